@@ -1,17 +1,34 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"shipping-api/internal/adapters/events"
 	"shipping-api/internal/adapters/providers/providerA"
 	"shipping-api/internal/adapters/providers/providerB"
 	"shipping-api/internal/adapters/repository"
+	"shipping-api/internal/adapters/webhooks"
+	"shipping-api/internal/core/domain"
 	"shipping-api/internal/core/service"
 	"shipping-api/internal/handlers"
+	"shipping-api/internal/middleware"
 	"shipping-api/pkg/config"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// drainGracePeriod bounds the wait for handlers cut off when requests did not
+// finish within SHUTDOWN_TIMEOUT.
+const drainGracePeriod = 10 * time.Second
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -22,29 +39,304 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
-	defer repo.Close()
 
 	shippingService := service.NewShippingService(repo)
 
 	providerAAdapter := providerA.NewAdapter(cfg.ProviderAURL)
-	shippingService.RegisterProvider(providerAAdapter)
-
 	providerBAdapter := providerB.NewAdapter(cfg.ProviderBURL)
+
+	if cfg.CodeTablesFile != "" {
+		tables, err := config.LoadCodeTables(cfg.CodeTablesFile)
+		if err != nil {
+			log.Fatalf("failed to load code tables: %v", err)
+		}
+		providerAAdapter.SetCodeTable(providerA.DefaultCodeTable().Merge(tables["A"]))
+		providerBAdapter.SetCodeTable(providerB.DefaultCodeTable().Merge(tables["B"]))
+	}
+
+	providerAAdapter.SetWebhookSecret(cfg.WebhookSecretA)
+	providerBAdapter.SetWebhookSecret(cfg.WebhookSecretB)
+
+	shippingService.RegisterProvider(providerAAdapter)
 	shippingService.RegisterProvider(providerBAdapter)
+	shippingService.SetBatchConcurrency(cfg.BatchConcurrency)
+
+	var csvProfiles map[string]*domain.CSVProfile
+	if cfg.CSVProfilesFile != "" {
+		csvProfiles, err = loadCSVProfiles(cfg.CSVProfilesFile)
+		if err != nil {
+			log.Fatalf("failed to load CSV profiles: %v", err)
+		}
+	}
+
+	trackingService := service.NewTrackingService(repo)
+	trackingService.RegisterWebhookParser(providerAAdapter)
+	trackingService.RegisterWebhookParser(providerBAdapter)
+
+	poller := service.NewTrackingPoller(repo, trackingService, cfg.PollerInterval)
+	if cfg.ProviderATrackingURL != "" {
+		providerAAdapter.SetTrackingEndpoint(cfg.ProviderATrackingURL)
+		poller.RegisterProvider(providerAAdapter)
+	}
+	if cfg.ProviderBTrackingURL != "" {
+		providerBAdapter.SetTrackingEndpoint(cfg.ProviderBTrackingURL)
+		poller.RegisterProvider(providerBAdapter)
+	}
+
+	dispatcher := service.NewWebhookDispatcher(repo, webhooks.NewHTTPSender(), cfg.WebhookDispatchInterval)
+
+	relay := service.NewOutboxRelay(repo, cfg.OutboxRelayInterval)
+	relay.AddSink(dispatcher)
+	if cfg.EventLog {
+		relay.AddSink(events.NewLogSink(nil))
+	}
+	if cfg.EventFile != "" {
+		fileSink, err := events.NewFileSink(cfg.EventFile)
+		if err != nil {
+			log.Fatalf("failed to open event file: %v", err)
+		}
+		defer fileSink.Close()
+		relay.AddSink(fileSink)
+	}
+
+	jobRunner := service.NewJobRunner(repo, shippingService, cfg.JobInterval)
+	jobRunner.SetConcurrency(cfg.JobConcurrency)
+	jobPolicy := domain.DefaultJobRetryPolicy
+	jobPolicy.MaxAttempts = cfg.JobMaxAttempts
+	jobRunner.SetRetryPolicy(jobPolicy)
 
 	handler := handlers.NewShippingHandler(shippingService)
+	handler.SetJobService(jobRunner)
+	handler.SetStrictDecoding(cfg.StrictDecoding)
+	shipmentHandler := handlers.NewShipmentHandler(repo, trackingService)
+	webhookHandler := handlers.NewWebhookHandler(trackingService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(dispatcher, repo)
+	jobHandler := handlers.NewJobHandler(repo)
+	batchHandler := handlers.NewBatchHandler(shippingService, csvProfiles)
+	batchHandler.SetTimeout(cfg.BatchTimeout)
+	v2Handler := handlers.NewV2Handler(shippingService, repo, trackingService, repo)
+	v2Handler.SetJobService(jobRunner)
+
+	var tenants map[string]*domain.Tenant
+	if cfg.TenantsFile != "" {
+		tenants, err = loadTenants(cfg.TenantsFile)
+		if err != nil {
+			log.Fatalf("failed to load tenants: %v", err)
+		}
+	}
+	tenantRegistry := service.NewTenantRegistry(tenants)
+	jobRunner.SetTenantDirectory(tenantRegistry)
+
+	apiKeyService := service.NewAPIKeyService(repo)
+	apiKeyService.SetTenantDirectory(tenantRegistry)
+	if cfg.BootstrapAPIKey != "" {
+		if err := apiKeyService.EnsureKey(context.Background(), "bootstrap", cfg.BootstrapAPIKey, []string{domain.ScopeAdmin}); err != nil {
+			log.Fatalf("failed to create bootstrap api key: %v", err)
+		}
+	}
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, repo)
+
+	usageService := service.NewUsageService(repo, tenantRegistry)
+	shippingService.SetUsageService(usageService)
+	usageHandler := handlers.NewUsageHandler(usageService)
+
+	auth := middleware.NewAuth(apiKeyService, tenantRegistry)
+	limiter := middleware.NewRateLimiter(usageService)
+	secure := func(scope string, handler http.HandlerFunc) http.Handler {
+		if !cfg.AuthEnabled {
+			return handler
+		}
+		return auth.Require(scope, limiter.Limit(handler))
+	}
+	if !cfg.AuthEnabled {
+		log.Printf("authentication is disabled")
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/createShipping", handler.CreateShipment)
+	mux.Handle("/api/v1/createShipping", secure(domain.ScopeShipmentsCreate, handler.CreateShipment))
+	mux.Handle("POST /api/v1/shipments/batch", secure(domain.ScopeShipmentsCreate, batchHandler.CreateBatch))
+	mux.Handle("GET /api/v1/shipments", secure(domain.ScopeShipmentsRead, shipmentHandler.ListShipments))
+	mux.Handle("GET /api/v1/shipments/{id}", secure(domain.ScopeShipmentsRead, shipmentHandler.GetShipment))
+	mux.Handle("POST /api/v1/shipments/{id}/status", secure(domain.ScopeAdmin, shipmentHandler.UpdateStatus))
+	mux.HandleFunc("POST /api/v1/webhooks/{provider}", webhookHandler.ReceiveWebhook)
+	mux.Handle("POST /api/v1/webhook-subscriptions", secure(domain.ScopeAdmin, subscriptionHandler.CreateSubscription))
+	mux.Handle("GET /api/v1/webhook-subscriptions", secure(domain.ScopeAdmin, subscriptionHandler.ListSubscriptions))
+	mux.Handle("DELETE /api/v1/webhook-subscriptions/{id}", secure(domain.ScopeAdmin, subscriptionHandler.DeleteSubscription))
+	mux.Handle("GET /api/v1/webhook-subscriptions/{id}/deliveries", secure(domain.ScopeAdmin, subscriptionHandler.ListDeliveries))
+	mux.Handle("GET /api/v1/webhook-deliveries", secure(domain.ScopeAdmin, subscriptionHandler.ListDeliveries))
+	mux.Handle("GET /api/v1/webhook-deliveries/{id}", secure(domain.ScopeAdmin, subscriptionHandler.GetDelivery))
+	mux.Handle("POST /api/v1/webhook-deliveries/{id}/replay", secure(domain.ScopeAdmin, subscriptionHandler.ReplayDelivery))
+	mux.Handle("GET /api/v1/jobs", secure(domain.ScopeAdmin, jobHandler.ListJobs))
+	mux.Handle("GET /api/v1/jobs/{id}", secure(domain.ScopeShipmentsRead, jobHandler.GetJob))
+	mux.Handle("POST /api/v2/shipments", secure(domain.ScopeShipmentsCreate, v2Handler.CreateShipment))
+	mux.Handle("GET /api/v2/shipments", secure(domain.ScopeShipmentsRead, v2Handler.ListShipments))
+	mux.Handle("GET /api/v2/shipments/{id}", secure(domain.ScopeShipmentsRead, v2Handler.GetShipment))
+	mux.Handle("POST /api/v2/shipments/{id}/status", secure(domain.ScopeAdmin, v2Handler.UpdateStatus))
+	mux.Handle("POST /api/v2/broadcasts", secure(domain.ScopeShipmentsCreate, v2Handler.CreateBroadcast))
+	mux.Handle("GET /api/v2/providers", secure(domain.ScopeShipmentsRead, v2Handler.ListProviders))
+	mux.Handle("GET /api/v2/providers/{name}", secure(domain.ScopeShipmentsRead, v2Handler.GetProvider))
+	mux.Handle("GET /api/v2/jobs/{id}", secure(domain.ScopeShipmentsRead, v2Handler.GetJob))
+	mux.Handle("POST /api/v1/admin/api-keys", secure(domain.ScopeAdmin, apiKeyHandler.IssueKey))
+	mux.Handle("GET /api/v1/admin/api-keys", secure(domain.ScopeAdmin, apiKeyHandler.ListKeys))
+	mux.Handle("POST /api/v1/admin/api-keys/{id}/rotate", secure(domain.ScopeAdmin, apiKeyHandler.RotateKey))
+	mux.Handle("DELETE /api/v1/admin/api-keys/{id}", secure(domain.ScopeAdmin, apiKeyHandler.RevokeKey))
+	mux.Handle("GET /api/v1/admin/usage", secure(domain.ScopeAdmin, usageHandler.GetUsage))
+	mux.HandleFunc("GET /openapi.json", handlers.OpenAPI)
+	mux.HandleFunc("GET /schemas/generic-shipping-request/{version}", handlers.RequestSchema)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Workers keep running until the server has drained, so they relay the
+	// events and run the jobs of the last requests.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	for _, run := range []func(context.Context){poller.Run, relay.Run, dispatcher.Run, jobRunner.Run, usageService.Run} {
+		workers.Add(1)
+		go func(run func(context.Context)) {
+			defer workers.Done()
+			run(workerCtx)
+		}(run)
+	}
+
+	drain := middleware.NewDrain(cfg.RequestTimeout)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	root := middleware.Chain(mux,
+		middleware.RequestID,
+		middleware.AccessLog(logger),
+		middleware.Recover(logger),
+		middleware.MaxBytes(cfg.MaxBodyBytes),
+		middleware.Timeout(cfg.RequestTimeout, cfg.WriteTimeout),
+		drain.Track,
+	)
+
 	addr := fmt.Sprintf(":%s", cfg.ServerPort)
-	log.Printf("server starting on %s", addr)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           root,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	server.RegisterOnShutdown(drain.Shutdown)
+
+	go func() {
+		log.Printf("server starting on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server failed to start: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// A second signal kills the process without waiting.
+	stop()
+	log.Printf("shutting down, draining for up to %s", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("requests did not finish in time, closing connections: %v", err)
+		server.Close()
+	}
+	// Handlers cut off by Close may still be saving shipments they booked.
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainGracePeriod)
+	defer cancelDrain()
+	requestsDone := drain.Wait(drainCtx)
+	if !requestsDone {
+		log.Printf("requests did not stop after closing connections")
+	}
+
+	if shutdownWorkers(stopWorkers, &workers, cfg.ShutdownTimeout, requestsDone, repo) {
+		log.Printf("shutdown complete")
+	}
+}
+
+// shutdownWorkers stops the background workers and gives them timeout from
+// now to finish, since draining requests may have used up SHUTDOWN_TIMEOUT.
+// The database is closed only once the workers and requests are done: a job
+// still running may be saving a shipment its carrier booked. It reports
+// whether the database was closed.
+func shutdownWorkers(stop context.CancelFunc, workers *sync.WaitGroup, timeout time.Duration, requestsDone bool, db io.Closer) bool {
+	stop()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !wait(ctx, workers) {
+		log.Printf("background workers did not finish in time, leaving the database open")
+		return false
+	}
+	if !requestsDone {
+		log.Printf("requests are still running, leaving the database open")
+		return false
+	}
+
+	if err := db.Close(); err != nil {
+		log.Printf("failed to close database: %v", err)
+	}
+	return true
+}
+
+// loadCSVProfiles decodes and validates the configured batch upload profiles.
+func loadCSVProfiles(path string) (map[string]*domain.CSVProfile, error) {
+	raw, err := config.LoadCSVProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*domain.CSVProfile, len(raw))
+	for name, data := range raw {
+		var profile domain.CSVProfile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return nil, fmt.Errorf("failed to parse CSV profile %q: %w", name, err)
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid CSV profile %q: %w", name, err)
+		}
+		profiles[name] = &profile
+	}
+	return profiles, nil
+}
+
+// loadTenants decodes and validates the configured tenants.
+func loadTenants(path string) (map[string]*domain.Tenant, error) {
+	raw, err := config.LoadTenants(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := make(map[string]*domain.Tenant, len(raw))
+	for id, data := range raw {
+		var tenant domain.Tenant
+		if err := json.Unmarshal(data, &tenant); err != nil {
+			return nil, fmt.Errorf("failed to parse tenant %q: %w", id, err)
+		}
+		tenant.ID = id
+		if err := tenant.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid tenant %q: %w", id, err)
+		}
+		tenants[id] = &tenant
+	}
+	return tenants, nil
+}
+
+// wait waits for wg until ctx is done and reports whether wg finished.
+func wait(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		log.Fatalf("server failed to start: %v", err)
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeDatabase struct {
+	closed     bool
+	workerDone *atomic.Bool
+	closedLate bool
+}
+
+func (d *fakeDatabase) Close() error {
+	d.closed = true
+	d.closedLate = d.workerDone != nil && !d.workerDone.Load()
+	return nil
+}
+
+// startWorker runs a worker that keeps working for runFor after its context
+// is cancelled, like a job runner finishing the job it holds.
+func startWorker(runFor time.Duration) (context.CancelFunc, *sync.WaitGroup, *atomic.Bool) {
+	ctx, stop := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	var done atomic.Bool
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		<-ctx.Done()
+		time.Sleep(runFor)
+		done.Store(true)
+	}()
+	return stop, &workers, &done
+}
+
+func TestShutdownWorkers(t *testing.T) {
+	stop, workers, done := startWorker(20 * time.Millisecond)
+	db := &fakeDatabase{workerDone: done}
+	if !shutdownWorkers(stop, workers, time.Second, true, db) {
+		t.Fatal("expected the database to be closed once the workers finished")
+	}
+	if !db.closed || db.closedLate {
+		t.Errorf("expected the database to be closed after the workers finished, closed %v late %v", db.closed, db.closedLate)
+	}
+}
+
+func TestShutdownWorkers_LeavesDatabaseOpen(t *testing.T) {
+	stop, workers, done := startWorker(time.Second)
+	db := &fakeDatabase{workerDone: done}
+	if shutdownWorkers(stop, workers, 20*time.Millisecond, true, db) || db.closed {
+		t.Error("expected the database to stay open while a worker is running")
+	}
+
+	stop, workers, _ = startWorker(0)
+	db = &fakeDatabase{}
+	if shutdownWorkers(stop, workers, time.Second, false, db) || db.closed {
+		t.Error("expected the database to stay open while requests are running")
+	}
+}
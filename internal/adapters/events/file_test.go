@@ -0,0 +1,54 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"shipping-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestFileSink_AppendsNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	for i, eventType := range []string{domain.EventShipmentCreated, domain.EventStatusChanged} {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("failed to open sink: %v", err)
+		}
+		event := &domain.ShipmentEvent{
+			ID:         string(rune('a' + i)),
+			Type:       eventType,
+			ShipmentID: "shipment-1",
+			OccurredAt: time.Now(),
+			Data:       json.RawMessage(`{"provider":"A"}`),
+		}
+		if err := sink.Publish(context.Background(), event); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+		sink.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event domain.ShipmentEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line is not JSON: %v", err)
+		}
+		types = append(types, event.Type)
+	}
+
+	if len(types) != 2 || types[0] != domain.EventShipmentCreated || types[1] != domain.EventStatusChanged {
+		t.Errorf("expected both events appended in order, got %v", types)
+	}
+}
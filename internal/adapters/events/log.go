@@ -0,0 +1,24 @@
+package events
+
+import (
+	"context"
+	"log"
+	"shipping-api/internal/core/domain"
+)
+
+// LogSink writes each event to the standard logger.
+type LogSink struct {
+	logger *log.Logger
+}
+
+func NewLogSink(logger *log.Logger) *LogSink {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Publish(ctx context.Context, event *domain.ShipmentEvent) error {
+	s.logger.Printf("event %s id=%s shipment=%s data=%s", event.Type, event.ID, event.ShipmentID, event.Data)
+	return nil
+}
@@ -0,0 +1,99 @@
+package constraints
+
+import (
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Field struct {
+	MaxLength int
+	ASCIIOnly bool
+}
+
+type Checker struct {
+	provider string
+	warnings []domain.MappingWarning
+}
+
+func NewChecker(provider string) *Checker {
+	return &Checker{provider: provider}
+}
+
+func (c *Checker) Warnings() []domain.MappingWarning {
+	return c.warnings
+}
+
+func (c *Checker) Apply(path string, value *string, field Field) {
+	c.transliterate(path, value, field)
+
+	kept, rest := Truncate(*value, field.MaxLength)
+	if rest != "" {
+		c.warn(path, domain.WarningTruncated, fmt.Sprintf("provider %s allows %d characters; dropped %q", c.provider, field.MaxLength, rest))
+	}
+	*value = kept
+}
+
+// Spill applies the field constraints to a primary line and moves any overflow
+// to the front of the secondary line before constraining that one too.
+func (c *Checker) Spill(path string, primary *string, secondaryPath string, secondary *string, field Field) {
+	c.transliterate(path, primary, field)
+	c.transliterate(secondaryPath, secondary, field)
+
+	kept, rest := Truncate(*primary, field.MaxLength)
+	if rest != "" {
+		c.warn(path, domain.WarningTruncated, fmt.Sprintf("provider %s allows %d characters; moved %q to %s", c.provider, field.MaxLength, rest, secondaryPath))
+		*secondary = strings.TrimSpace(rest + " " + *secondary)
+	}
+	*primary = kept
+
+	kept, rest = Truncate(*secondary, field.MaxLength)
+	if rest != "" {
+		c.warn(secondaryPath, domain.WarningTruncated, fmt.Sprintf("provider %s allows %d characters; dropped %q", c.provider, field.MaxLength, rest))
+	}
+	*secondary = kept
+}
+
+func (c *Checker) transliterate(path string, value *string, field Field) {
+	if !field.ASCIIOnly || isASCII(*value) {
+		return
+	}
+	original := *value
+	*value = Transliterate(original)
+	c.warn(path, domain.WarningTransliterated, fmt.Sprintf("provider %s accepts ASCII only; %q sent as %q", c.provider, original, *value))
+}
+
+func (c *Checker) warn(path, reason, detail string) {
+	c.warnings = append(c.warnings, domain.MappingWarning{Field: path, Reason: reason, Detail: detail})
+}
+
+// Truncate cuts s to at most max characters, preferring the last word
+// boundary. It returns the kept text and the overflow. A max of zero
+// disables the limit.
+func Truncate(s string, max int) (string, string) {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s, ""
+	}
+
+	runes := []rune(s)
+	cut := max
+	for i := max; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace), strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
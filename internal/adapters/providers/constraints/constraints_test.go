@@ -0,0 +1,88 @@
+package constraints
+
+import (
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input    string
+		max      int
+		kept     string
+		overflow string
+	}{
+		{"Short", 10, "Short", ""},
+		{"Tulip oasis 6, 6th floor", 15, "Tulip oasis 6,", "6th floor"},
+		{"Supercalifragilistic", 5, "Super", "califragilistic"},
+		{"No limit", 0, "No limit", ""},
+	}
+
+	for _, tt := range tests {
+		kept, overflow := Truncate(tt.input, tt.max)
+		if kept != tt.kept || overflow != tt.overflow {
+			t.Errorf("Truncate(%q, %d) = (%q, %q); expected (%q, %q)", tt.input, tt.max, kept, overflow, tt.kept, tt.overflow)
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"John Doe", "John Doe"},
+		{"José Müller", "Jose Muller"},
+		{"محمد", "mhmd"},
+		{"دبي ١٢", "dby 12"},
+		{"राम", "raam"},
+		{"अमित कुमार", "amit kumaar"},
+	}
+
+	for _, tt := range tests {
+		if result := Transliterate(tt.input); result != tt.expected {
+			t.Errorf("Transliterate(%q) = %q; expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestChecker_Spill(t *testing.T) {
+	checker := NewChecker("B")
+
+	line1 := "Building 12, Al Wasl Road, Jumeirah"
+	line2 := "Flat 4"
+	checker.Spill("consignee.address.line1", &line1, "consignee.address.line2", &line2, Field{MaxLength: 20})
+
+	if line1 != "Building 12, Al Wasl" {
+		t.Errorf("expected line1 to be truncated at a word boundary, got %q", line1)
+	}
+
+	if line2 != "Road, Jumeirah Flat" {
+		t.Errorf("expected overflow spilled into line2, got %q", line2)
+	}
+
+	warnings := checker.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+
+	if warnings[0].Field != "consignee.address.line1" || warnings[0].Reason != domain.WarningTruncated {
+		t.Errorf("unexpected first warning %+v", warnings[0])
+	}
+}
+
+func TestChecker_ApplyTransliterates(t *testing.T) {
+	checker := NewChecker("A")
+
+	name := "محمد"
+	checker.Apply("consignee.contact.name", &name, Field{MaxLength: 35, ASCIIOnly: true})
+
+	if name != "mhmd" {
+		t.Errorf("expected transliterated name, got %q", name)
+	}
+
+	warnings := checker.Warnings()
+	if len(warnings) != 1 || warnings[0].Reason != domain.WarningTransliterated {
+		t.Errorf("expected a single transliterated warning, got %v", warnings)
+	}
+}
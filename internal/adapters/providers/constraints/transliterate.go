@@ -0,0 +1,137 @@
+package constraints
+
+import (
+	"strings"
+	"unicode"
+)
+
+var latin = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ð': "D", 'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "TH", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ð': "d", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Ā': "A", 'ā': "a", 'Ă': "A", 'ă': "a", 'Ą': "A", 'ą': "a", 'Ć': "C", 'ć': "c",
+	'Č': "C", 'č': "c", 'Ď': "D", 'ď': "d", 'Đ': "D", 'đ': "d", 'Ē': "E", 'ē': "e",
+	'Ę': "E", 'ę': "e", 'Ě': "E", 'ě': "e", 'Ğ': "G", 'ğ': "g", 'Ī': "I", 'ī': "i",
+	'İ': "I", 'ı': "i", 'Ł': "L", 'ł': "l", 'Ń': "N", 'ń': "n", 'Ň': "N", 'ň': "n",
+	'Ō': "O", 'ō': "o", 'Ő': "O", 'ő': "o", 'Œ': "OE", 'œ': "oe", 'Ř': "R", 'ř': "r",
+	'Ś': "S", 'ś': "s", 'Ş': "S", 'ş': "s", 'Š': "S", 'š': "s", 'Ţ': "T", 'ţ': "t",
+	'Ť': "T", 'ť': "t", 'Ū': "U", 'ū': "u", 'Ů': "U", 'ů': "u", 'Ű': "U", 'ű': "u",
+	'Ź': "Z", 'ź': "z", 'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z",
+	'‘': "'", '’': "'", '“': "\"", '”': "\"", '–': "-", '—': "-", '…': "...",
+}
+
+var arabic = map[rune]string{
+	'ا': "a", 'أ': "a", 'إ': "i", 'آ': "aa", 'ٱ': "a", 'ب': "b", 'ت': "t", 'ث': "th",
+	'ج': "j", 'ح': "h", 'خ': "kh", 'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s",
+	'ش': "sh", 'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z", 'ع': "a", 'غ': "gh", 'ف': "f",
+	'ق': "q", 'ك': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'و': "w", 'ي': "y",
+	'ى': "a", 'ة': "a", 'ء': "", 'ؤ': "w", 'ئ': "y", 'پ': "p", 'چ': "ch", 'گ': "g",
+	'ک': "k", 'ی': "y", '،': ",", '؛': ";", '؟': "?", 'ـ': "",
+	'٠': "0", '١': "1", '٢': "2", '٣': "3", '٤': "4", '٥': "5", '٦': "6", '٧': "7", '٨': "8", '٩': "9",
+}
+
+var devanagariConsonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "ng", 'च': "ch", 'छ': "chh", 'ज': "j",
+	'झ': "jh", 'ञ': "ny", 'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n", 'त': "t",
+	'थ': "th", 'द': "d", 'ध': "dh", 'न': "n", 'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh",
+	'म': "m", 'य': "y", 'र': "r", 'ल': "l", 'व': "v", 'श': "sh", 'ष': "sh", 'स': "s",
+	'ह': "h", 'ळ': "l",
+}
+
+var devanagariVowels = map[rune]string{
+	'अ': "a", 'आ': "aa", 'इ': "i", 'ई': "ee", 'उ': "u", 'ऊ': "oo", 'ऋ': "ri", 'ए': "e",
+	'ऐ': "ai", 'ओ': "o", 'औ': "au",
+	'०': "0", '१': "1", '२': "2", '३': "3", '४': "4", '५': "5", '६': "6", '७': "7", '८': "8", '९': "9",
+	'।': ".", 'ं': "n", 'ँ': "n", 'ः': "h",
+}
+
+var devanagariSigns = map[rune]string{
+	'ा': "aa", 'ि': "i", 'ी': "ee", 'ु': "u", 'ू': "oo", 'ृ': "ri", 'े': "e", 'ै': "ai",
+	'ो': "o", 'ौ': "au",
+}
+
+const (
+	virama = '्'
+	nukta  = '़'
+)
+
+// Transliterate converts Latin diacritics, Arabic and Devanagari text to plain
+// ASCII. Characters with no known transliteration are dropped.
+func Transliterate(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r < unicode.MaxASCII {
+			b.WriteRune(r)
+			continue
+		}
+		if out, ok := latin[r]; ok {
+			b.WriteString(out)
+			continue
+		}
+		if out, ok := arabic[r]; ok {
+			b.WriteString(out)
+			continue
+		}
+		if out, ok := devanagariVowels[r]; ok {
+			b.WriteString(out)
+			continue
+		}
+		if out, ok := devanagariConsonants[r]; ok {
+			b.WriteString(out)
+			next := i + 1
+			for next < len(runes) && runes[next] == nukta {
+				next++
+			}
+			if next < len(runes) {
+				if sign, ok := devanagariSigns[runes[next]]; ok {
+					b.WriteString(sign)
+					i = next
+					continue
+				}
+				if runes[next] == virama {
+					i = next
+					continue
+				}
+			}
+			if endsWord(runes, next) && wordStart(runes, i) < i {
+				i = next - 1
+				continue
+			}
+			b.WriteString("a")
+			i = next - 1
+			continue
+		}
+		if unicode.IsSpace(r) {
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func endsWord(runes []rune, i int) bool {
+	if i >= len(runes) {
+		return true
+	}
+	r := runes[i]
+	_, consonant := devanagariConsonants[r]
+	_, vowel := devanagariVowels[r]
+	_, sign := devanagariSigns[r]
+	return !consonant && !vowel && !sign && r != virama && r != nukta
+}
+
+func wordStart(runes []rune, i int) int {
+	for i > 0 && !endsWord(runes, i-1) {
+		i--
+	}
+	return i
+}
@@ -7,13 +7,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"shipping-api/internal/adapters/providers/transport"
 	"shipping-api/internal/core/domain"
 	"time"
 )
 
 type Adapter struct {
-	endpoint string
-	client   *http.Client
+	endpoint         string
+	client           *http.Client
+	codes            domain.CodeTable
+	webhookSecret    string
+	trackingEndpoint string
 }
 
 func NewAdapter(endpoint string) *Adapter {
@@ -22,15 +26,22 @@ func NewAdapter(endpoint string) *Adapter {
 		client: &http.Client{
 			Timeout: 15 * time.Second,
 		},
+		codes: DefaultCodeTable(),
 	}
 }
 
-func (a *Adapter) CreateShipment(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
-	providerReq := MapToProviderA(request)
+func (a *Adapter) SetCodeTable(table domain.CodeTable) {
+	a.codes = table
+}
 
-	jsonData, err := json.Marshal(providerReq)
+func (a *Adapter) CreateShipment(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+	jsonData, warnings, err := a.buildPayload(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
+	}
+
+	if request.StrictMapping && len(warnings) > 0 {
+		return nil, &domain.MappingError{Provider: a.GetProviderName(), Warnings: warnings}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", a.endpoint, bytes.NewBuffer(jsonData))
@@ -39,16 +50,35 @@ func (a *Adapter) CreateShipment(ctx context.Context, request *domain.GenericShi
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	transport.SetRequestID(req)
+
+	exchange := &domain.ProviderExchange{RequestBody: jsonData}
+	start := time.Now()
 
 	resp, err := a.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		exchange.Duration = time.Since(start)
+		return nil, &domain.ProviderError{
+			Provider: a.GetProviderName(),
+			Category: transport.Categorize(err),
+			Exchange: exchange,
+			Err:      fmt.Errorf("failed to send request: %w", err),
+		}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
+	exchange.Duration = time.Since(start)
+	exchange.StatusCode = resp.StatusCode
+	exchange.Headers = resp.Header
+	exchange.ResponseBody = body
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, &domain.ProviderError{
+			Provider: a.GetProviderName(),
+			Category: transport.Categorize(err),
+			Exchange: exchange,
+			Err:      fmt.Errorf("failed to read response: %w", err),
+		}
 	}
 
 	var rawResponse map[string]interface{}
@@ -59,7 +89,9 @@ func (a *Adapter) CreateShipment(ctx context.Context, request *domain.GenericShi
 	response := &domain.ShipmentResponse{
 		Provider:    a.GetProviderName(),
 		Success:     resp.StatusCode >= 200 && resp.StatusCode < 300,
+		Warnings:    warnings,
 		RawResponse: rawResponse,
+		Exchange:    exchange,
 	}
 
 	if trackingID, ok := rawResponse["trackingId"].(string); ok {
@@ -75,6 +107,34 @@ func (a *Adapter) CreateShipment(ctx context.Context, request *domain.GenericShi
 	return response, nil
 }
 
+func (a *Adapter) TransformRequest(request *domain.GenericShippingRequest) (*domain.TransformResult, error) {
+	jsonData, warnings, err := a.buildPayload(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TransformResult{
+		Provider: a.GetProviderName(),
+		Payload:  jsonData,
+		Warnings: warnings,
+	}, nil
+}
+
+func (a *Adapter) buildPayload(request *domain.GenericShippingRequest) ([]byte, []domain.MappingWarning, error) {
+	providerReq, warnings := MapToProviderA(request)
+	if err := translateCodes(providerReq, a.codes); err != nil {
+		return nil, nil, err
+	}
+	warnings = append(warnings, applyConstraints(providerReq)...)
+
+	jsonData, err := json.Marshal(providerReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return jsonData, warnings, nil
+}
+
 func (a *Adapter) GetProviderName() string {
 	return "A"
 }
@@ -82,3 +142,13 @@ func (a *Adapter) GetProviderName() string {
 func (a *Adapter) GetEndpoint() string {
 	return a.endpoint
 }
+
+func (a *Adapter) GetCapabilities() domain.ProviderCapabilities {
+	return domain.ProviderCapabilities{
+		SupportsCOD:       true,
+		MaxWeightKg:       70,
+		MaxPieces:         99,
+		ProductCodes:      a.codes.Codes(domain.CodeFieldProduct),
+		SupportsInsurance: true,
+	}
+}
@@ -0,0 +1,31 @@
+package providerA
+
+import "shipping-api/internal/core/domain"
+
+func DefaultCodeTable() domain.CodeTable {
+	table := domain.CodeTable{}
+	for field, codes := range domain.CanonicalCodes {
+		table[field] = make(map[string]string, len(codes))
+		for _, code := range codes {
+			table[field][code] = code
+		}
+	}
+	return table
+}
+
+func translateCodes(req *Request, table domain.CodeTable) error {
+	var err error
+	if req.ProductCode, err = table.Translate("A", domain.CodeFieldProduct, req.ProductCode); err != nil {
+		return err
+	}
+	if req.ServiceType, err = table.Translate("A", domain.CodeFieldService, req.ServiceType); err != nil {
+		return err
+	}
+	if req.DeliveryType, err = table.Translate("A", domain.CodeFieldDelivery, req.DeliveryType); err != nil {
+		return err
+	}
+	if req.ContentType, err = table.Translate("A", domain.CodeFieldContent, req.ContentType); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package providerA
+
+import (
+	"fmt"
+	"shipping-api/internal/adapters/providers/constraints"
+	"shipping-api/internal/core/domain"
+)
+
+var (
+	nameField        = constraints.Field{MaxLength: 35, ASCIIOnly: true}
+	addressField     = constraints.Field{MaxLength: 45, ASCIIOnly: true}
+	cityField        = constraints.Field{MaxLength: 35, ASCIIOnly: true}
+	zipCodeField     = constraints.Field{MaxLength: 10, ASCIIOnly: true}
+	notesField       = constraints.Field{MaxLength: 200, ASCIIOnly: true}
+	descriptionField = constraints.Field{MaxLength: 100, ASCIIOnly: true}
+)
+
+func applyConstraints(req *Request) []domain.MappingWarning {
+	checker := constraints.NewChecker("A")
+
+	applyPartyConstraints(checker, "shipper", &req.Shipper)
+	applyPartyConstraints(checker, "consignee", &req.Consignee)
+
+	checker.Apply("specialNotes", &req.SpecialNotes, notesField)
+	checker.Apply("remarks", &req.Remarks, notesField)
+
+	for i := range req.CustomsDeclarations {
+		checker.Apply(fmt.Sprintf("customsDeclarations[%d].description", i), &req.CustomsDeclarations[i].Description, descriptionField)
+	}
+
+	return checker.Warnings()
+}
+
+func applyPartyConstraints(checker *constraints.Checker, prefix string, party *Party) {
+	checker.Apply(prefix+".contact.name", &party.Contact.Name, nameField)
+	checker.Apply(prefix+".contact.companyName", &party.Contact.CompanyName, nameField)
+	checker.Apply(prefix+".address.line1", &party.Address.Line1, addressField)
+	checker.Apply(prefix+".address.city", &party.Address.City, cityField)
+	checker.Apply(prefix+".address.zipCode", &party.Address.ZipCode, zipCodeField)
+}
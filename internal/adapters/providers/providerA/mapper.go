@@ -1,11 +1,14 @@
 package providerA
 
 import (
+	"fmt"
 	"shipping-api/internal/core/domain"
 	"strconv"
 )
 
-func MapToProviderA(req *domain.GenericShippingRequest) *Request {
+const maxReferenceNumbers = 4
+
+func MapToProviderA(req *domain.GenericShippingRequest) (*Request, []domain.MappingWarning) {
 	result := &Request{
 		Weight: Weight{
 			Value: req.Weight.Value,
@@ -51,10 +54,10 @@ func MapToProviderA(req *domain.GenericShippingRequest) *Request {
 			Width:  req.Dimensions.Width,
 			Unit:   req.Dimensions.Unit,
 		},
-		ProductCode:    req.ProductCode,
-		ServiceType:    req.ServiceType,
-		PrintType:      "AWBOnly",
-		IsInsured:      req.IsInsured,
+		ProductCode: req.ProductCode,
+		ServiceType: req.ServiceType,
+		PrintType:   "AWBOnly",
+		IsInsured:   req.IsInsured,
 		DeclaredValue: DeclaredValue{
 			Amount:   req.DeclaredValue.Amount,
 			Currency: req.DeclaredValue.Currency,
@@ -67,7 +70,16 @@ func MapToProviderA(req *domain.GenericShippingRequest) *Request {
 		IsCOD:          req.IsCOD,
 	}
 
-	accountNum, _ := strconv.Atoi(req.Account.Number)
+	var warnings []domain.MappingWarning
+
+	accountNum, err := strconv.Atoi(req.Account.Number)
+	if err != nil && req.Account.Number != "" {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  "account.number",
+			Reason: domain.WarningDefaulted,
+			Detail: fmt.Sprintf("%q is not numeric; sent as 0", req.Account.Number),
+		})
+	}
 	result.Account = Account{Number: accountNum}
 
 	if len(req.ReferenceNumbers) > 0 {
@@ -82,6 +94,13 @@ func MapToProviderA(req *domain.GenericShippingRequest) *Request {
 	if len(req.ReferenceNumbers) > 3 {
 		result.ReferenceNumber4 = req.ReferenceNumbers[3]
 	}
+	for i := maxReferenceNumbers; i < len(req.ReferenceNumbers); i++ {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  fmt.Sprintf("referenceNumbers[%d]", i),
+			Reason: domain.WarningDropped,
+			Detail: fmt.Sprintf("provider A accepts at most %d reference numbers", maxReferenceNumbers),
+		})
+	}
 
 	result.CustomsDeclarations = make([]CustomsDeclaration, len(req.CustomsDeclarations))
 	for i, cd := range req.CustomsDeclarations {
@@ -101,5 +120,43 @@ func MapToProviderA(req *domain.GenericShippingRequest) *Request {
 		}
 	}
 
-	return result
+	warnings = append(warnings, addressWarnings("shipper", req.Shipper.Address)...)
+	warnings = append(warnings, addressWarnings("consignee", req.Consignee.Address)...)
+
+	if req.IsCOD && req.CODAmount > 0 {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  "codAmount",
+			Reason: domain.WarningDropped,
+			Detail: "provider A has no COD amount field",
+		})
+	}
+
+	if len(req.Packages) > 0 {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  "packages",
+			Reason: domain.WarningDropped,
+			Detail: "provider A has no per-package breakdown",
+		})
+	}
+
+	return result, warnings
+}
+
+func addressWarnings(party string, address domain.Address) []domain.MappingWarning {
+	var warnings []domain.MappingWarning
+	if address.Line2 != "" {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  party + ".address.line2",
+			Reason: domain.WarningDropped,
+			Detail: "provider A has no second address line",
+		})
+	}
+	if address.State != "" {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  party + ".address.state",
+			Reason: domain.WarningDropped,
+			Detail: "provider A has no state field",
+		})
+	}
+	return warnings
 }
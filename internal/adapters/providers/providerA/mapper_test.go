@@ -86,7 +86,7 @@ func TestMapToProviderA(t *testing.T) {
 		},
 	}
 
-	result := MapToProviderA(genericReq)
+	result, _ := MapToProviderA(genericReq)
 
 	if result.Weight.Value != 1000 {
 		t.Errorf("expected weight value 1000, got %f", result.Weight.Value)
@@ -194,7 +194,7 @@ func TestMapToProviderA_EmptyReferenceNumbers(t *testing.T) {
 		ReferenceNumbers: []string{},
 	}
 
-	result := MapToProviderA(genericReq)
+	result, _ := MapToProviderA(genericReq)
 
 	if result.ReferenceNumber1 != "" {
 		t.Errorf("expected empty reference number 1, got %s", result.ReferenceNumber1)
@@ -231,7 +231,7 @@ func TestMapToProviderA_MultipleCustomsDeclarations(t *testing.T) {
 		},
 	}
 
-	result := MapToProviderA(genericReq)
+	result, _ := MapToProviderA(genericReq)
 
 	if len(result.CustomsDeclarations) != 2 {
 		t.Errorf("expected 2 customs declarations, got %d", len(result.CustomsDeclarations))
@@ -245,3 +245,56 @@ func TestMapToProviderA_MultipleCustomsDeclarations(t *testing.T) {
 		t.Errorf("expected second item description Item 2, got %s", result.CustomsDeclarations[1].Description)
 	}
 }
+
+func TestMapToProviderA_Warnings(t *testing.T) {
+	genericReq := &domain.GenericShippingRequest{
+		Shipper: domain.Party{
+			Address: domain.Address{Line1: "Street 1", Line2: "Floor 6", State: "Dubai"},
+		},
+		Consignee: domain.Party{
+			Address: domain.Address{Line1: "Road 2"},
+		},
+		Account:          domain.AccountInfo{Number: "ACC-1"},
+		ReferenceNumbers: []string{"R1", "R2", "R3", "R4", "R5", "R6"},
+	}
+
+	_, warnings := MapToProviderA(genericReq)
+
+	expected := map[string]string{
+		"shipper.address.line2": domain.WarningDropped,
+		"shipper.address.state": domain.WarningDropped,
+		"referenceNumbers[4]":   domain.WarningDropped,
+		"referenceNumbers[5]":   domain.WarningDropped,
+		"account.number":        domain.WarningDefaulted,
+	}
+
+	if len(warnings) != len(expected) {
+		t.Errorf("expected %d warnings, got %d: %v", len(expected), len(warnings), warnings)
+	}
+
+	for _, w := range warnings {
+		reason, ok := expected[w.Field]
+		if !ok {
+			t.Errorf("unexpected warning for field %s", w.Field)
+			continue
+		}
+		if w.Reason != reason {
+			t.Errorf("expected reason %s for %s, got %s", reason, w.Field, w.Reason)
+		}
+	}
+}
+
+func TestMapToProviderA_NoWarnings(t *testing.T) {
+	genericReq := &domain.GenericShippingRequest{
+		Shipper:          domain.Party{Address: domain.Address{Line1: "Street 1", City: "Dubai"}},
+		Consignee:        domain.Party{Address: domain.Address{Line1: "Road 2", City: "Bangalore"}},
+		Account:          domain.AccountInfo{Number: "123"},
+		ReferenceNumbers: []string{"R1", "R2"},
+	}
+
+	_, warnings := MapToProviderA(genericReq)
+
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
@@ -0,0 +1,60 @@
+package providerA
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"shipping-api/internal/core/domain"
+	"time"
+)
+
+func (a *Adapter) SetTrackingEndpoint(endpoint string) {
+	a.trackingEndpoint = endpoint
+}
+
+func (a *Adapter) GetTrackingPolicy() domain.TrackingPolicy {
+	return domain.TrackingPolicy{
+		BatchSize:         50,
+		RequestsPerMinute: 60,
+		PollInterval:      30 * time.Minute,
+		MaxPollInterval:   6 * time.Hour,
+		TrackBy:           domain.TrackByTrackingID,
+	}
+}
+
+// TrackShipments fetches events for a batch of tracking IDs in one call. The
+// tracking endpoint answers in the same format as the webhook push.
+func (a *Adapter) TrackShipments(ctx context.Context, shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+	query := url.Values{}
+	for _, shipment := range shipments {
+		if shipment.TrackingID != "" {
+			query.Add("trackingId", shipment.TrackingID)
+		}
+	}
+	if len(query) == 0 {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.trackingEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracking request: %w", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tracking request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tracking request failed with status %d", resp.StatusCode)
+	}
+
+	return a.ParseWebhook(body)
+}
@@ -0,0 +1,81 @@
+package providerA
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/adapters/providers/webhook"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"time"
+)
+
+const SignatureHeader = "X-Signature"
+
+type WebhookPayload struct {
+	Events []WebhookEvent `json:"events"`
+}
+
+type WebhookEvent struct {
+	EventID     string `json:"eventId"`
+	TrackingID  string `json:"trackingId"`
+	AWB         string `json:"awb"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	Timestamp   string `json:"timestamp"`
+}
+
+var statusCodes = map[string]domain.ShipmentStatus{
+	"CREATED":            domain.StatusCreated,
+	"LABEL_CREATED":      domain.StatusLabelGenerated,
+	"PICKED_UP":          domain.StatusPickedUp,
+	"IN_TRANSIT":         domain.StatusInTransit,
+	"ARRIVED_AT_HUB":     domain.StatusInTransit,
+	"DEPARTED_HUB":       domain.StatusInTransit,
+	"OUT_FOR_DELIVERY":   domain.StatusOutForDelivery,
+	"DELIVERED":          domain.StatusDelivered,
+	"DELIVERY_FAILED":    domain.StatusException,
+	"EXCEPTION":          domain.StatusException,
+	"RETURNED_TO_SENDER": domain.StatusReturned,
+	"CANCELLED":          domain.StatusCancelled,
+}
+
+func (a *Adapter) SetWebhookSecret(secret string) {
+	a.webhookSecret = secret
+}
+
+// VerifyWebhook checks the HMAC-SHA256 signature provider A sends in the
+// X-Signature header.
+func (a *Adapter) VerifyWebhook(header http.Header, body []byte) error {
+	return webhook.VerifyHMAC(a.webhookSecret, header.Get(SignatureHeader), body)
+}
+
+func (a *Adapter) ParseWebhook(body []byte) ([]*domain.TrackingEvent, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse provider A webhook: %w", err)
+	}
+
+	events := make([]*domain.TrackingEvent, 0, len(payload.Events))
+	for _, event := range payload.Events {
+		occurredAt, err := time.Parse(time.RFC3339, event.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q in provider A webhook event %s", event.Timestamp, event.EventID)
+		}
+		carrierStatus := strings.ToUpper(event.Status)
+		events = append(events, &domain.TrackingEvent{
+			Provider:      a.GetProviderName(),
+			EventID:       event.EventID,
+			TrackingID:    event.TrackingID,
+			AWB:           event.AWB,
+			Status:        statusCodes[carrierStatus],
+			CarrierStatus: carrierStatus,
+			Description:   event.Description,
+			Location:      event.Location,
+			OccurredAt:    occurredAt,
+		})
+	}
+
+	return events, nil
+}
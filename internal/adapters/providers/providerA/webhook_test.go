@@ -0,0 +1,56 @@
+package providerA
+
+import (
+	"errors"
+	"net/http"
+	"shipping-api/internal/adapters/providers/webhook"
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestAdapter_ParseWebhook(t *testing.T) {
+	adapter := NewAdapter("http://test")
+	body := []byte(`{"events": [
+		{"eventId": "E1", "trackingId": "A-TRACK-1", "status": "out_for_delivery", "location": "Dubai", "timestamp": "2026-01-02T08:00:00Z"},
+		{"eventId": "E2", "trackingId": "A-TRACK-1", "status": "WEIGHED", "timestamp": "2026-01-02T09:00:00Z"}
+	]}`)
+
+	events, err := adapter.ParseWebhook(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Status != domain.StatusOutForDelivery || events[0].Location != "Dubai" || events[0].EventID != "E1" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Status != "" || events[1].CarrierStatus != "WEIGHED" {
+		t.Errorf("expected unmapped status to be kept as carrier status, got %+v", events[1])
+	}
+}
+
+func TestAdapter_ParseWebhook_InvalidTimestamp(t *testing.T) {
+	adapter := NewAdapter("http://test")
+
+	if _, err := adapter.ParseWebhook([]byte(`{"events": [{"status": "DELIVERED", "timestamp": "yesterday"}]}`)); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestAdapter_VerifyWebhook(t *testing.T) {
+	adapter := NewAdapter("http://test")
+	adapter.SetWebhookSecret("secret")
+	body := []byte(`{"events": []}`)
+
+	header := http.Header{}
+	header.Set(SignatureHeader, webhook.Sign("secret", body))
+	if err := adapter.VerifyWebhook(header, body); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+
+	header.Set(SignatureHeader, webhook.Sign("other", body))
+	if err := adapter.VerifyWebhook(header, body); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error, got %v", err)
+	}
+}
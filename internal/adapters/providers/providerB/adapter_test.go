@@ -0,0 +1,38 @@
+package providerB
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/testutil"
+	"testing"
+)
+
+func TestAdapter_CreateShipment_StrictMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"trackingId": "B-1", "awb": "B-AWB-1"}`))
+	}))
+	defer server.Close()
+
+	// The sample payload, without the fields provider B has no place for.
+	request := testutil.CreateSampleShippingRequest()
+	request.Shipper.Address.ZipCode = ""
+	request.Shipper.ReferenceNo2 = ""
+	request.Consignee.ReferenceNo1 = ""
+	request.Consignee.ReferenceNo2 = ""
+	request.Remarks = ""
+	request.ServiceType = ""
+	request.DeliveryType = ""
+	request.ContentType = ""
+	request.ReferenceNumbers = nil
+	request.CustomsDeclarations[0].Reference = ""
+	request.StrictMapping = true
+
+	response, err := NewAdapter(server.URL).CreateShipment(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected a lossless request to pass strict mapping, got %v", err)
+	}
+	if !response.Success || response.TrackingID != "B-1" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
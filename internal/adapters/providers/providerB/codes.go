@@ -0,0 +1,17 @@
+package providerB
+
+import "shipping-api/internal/core/domain"
+
+func DefaultCodeTable() domain.CodeTable {
+	return domain.CodeTable{
+		domain.CodeFieldProduct: {
+			domain.ProductInternational: "XPS",
+		},
+	}
+}
+
+func translateCodes(req *Request, table domain.CodeTable) error {
+	var err error
+	req.ProductType, err = table.Translate("B", domain.CodeFieldProduct, req.ProductType)
+	return err
+}
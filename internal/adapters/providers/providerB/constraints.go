@@ -0,0 +1,48 @@
+package providerB
+
+import (
+	"fmt"
+	"shipping-api/internal/adapters/providers/constraints"
+	"shipping-api/internal/core/domain"
+)
+
+var (
+	nameField        = constraints.Field{MaxLength: 35, ASCIIOnly: true}
+	addressField     = constraints.Field{MaxLength: 45, ASCIIOnly: true}
+	cityField        = constraints.Field{MaxLength: 35, ASCIIOnly: true}
+	instructionField = constraints.Field{MaxLength: 200, ASCIIOnly: true}
+	goodsField       = constraints.Field{MaxLength: 90, ASCIIOnly: true}
+	itemField        = constraints.Field{MaxLength: 50, ASCIIOnly: true}
+)
+
+func applyConstraints(req *Request) []domain.MappingWarning {
+	checker := constraints.NewChecker("B")
+
+	checker.Apply("shipper.contact.companyName", &req.Shipper, nameField)
+	checker.Apply("shipper.contact.name", &req.ShipperCPerson, nameField)
+	checker.Spill("shipper.address.line1", &req.ShipperAddress1, "shipper.address.line2", &req.ShipperAddress2, addressField)
+	checker.Apply("shipper.address.city", &req.ShipperCity, cityField)
+
+	checker.Apply("consignee.contact.companyName", &req.Consignee, nameField)
+	checker.Apply("consignee.contact.name", &req.ConsigneeCPerson, nameField)
+	checker.Spill("consignee.address.line1", &req.ConsigneeAddress1, "consignee.address.line2", &req.ConsigneeAddress2, addressField)
+	checker.Apply("consignee.address.city", &req.ConsigneeCity, cityField)
+	checker.Apply("consignee.address.state", &req.ConsigneeState, cityField)
+
+	// Origin and destination codes come from the cities as they are sent.
+	if req.Origin != "" {
+		req.Origin = extractCityCode(req.ShipperCity)
+	}
+	if req.Destination != "" {
+		req.Destination = extractCityCode(req.ConsigneeCity)
+	}
+
+	checker.Apply("specialNotes", &req.SpecialInstruction, instructionField)
+	checker.Apply("customsDeclarations[*].description", &req.GoodsDescription, goodsField)
+
+	for i := range req.ExportItemDeclarationRequest {
+		checker.Apply(fmt.Sprintf("customsDeclarations[%d].description", i), &req.ExportItemDeclarationRequest[i].ItemDesc, itemField)
+	}
+
+	return checker.Warnings()
+}
@@ -0,0 +1,57 @@
+package providerB
+
+import (
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestApplyConstraints(t *testing.T) {
+	req := &Request{
+		ConsigneeCPerson:  "محمد عبدالله",
+		ConsigneeAddress1: "Villa 17, Street 32B, Al Barsha South Third, Near Park",
+		ConsigneeAddress2: "Dubai Marina",
+	}
+
+	warnings := applyConstraints(req)
+
+	if req.ConsigneeCPerson != "mhmd abdallh" {
+		t.Errorf("expected transliterated consignee name, got %q", req.ConsigneeCPerson)
+	}
+
+	if len(req.ConsigneeAddress1) > 45 {
+		t.Errorf("expected address line 1 within 45 characters, got %d", len(req.ConsigneeAddress1))
+	}
+
+	if req.ConsigneeAddress2 != "Near Park Dubai Marina" {
+		t.Errorf("expected overflow spilled into address line 2, got %q", req.ConsigneeAddress2)
+	}
+
+	reasons := make(map[string]string)
+	for _, w := range warnings {
+		reasons[w.Field] = w.Reason
+	}
+
+	if reasons["consignee.contact.name"] != domain.WarningTransliterated {
+		t.Errorf("expected transliterated warning for consignee name, got %v", warnings)
+	}
+
+	if reasons["consignee.address.line1"] != domain.WarningTruncated {
+		t.Errorf("expected truncated warning for address line 1, got %v", warnings)
+	}
+}
+
+func TestApplyConstraints_CityCodes(t *testing.T) {
+	req, _ := MapToProviderB(&domain.GenericShippingRequest{
+		Shipper:   domain.Party{Address: domain.Address{Line1: "Street 1", City: "दिल्ली"}},
+		Consignee: domain.Party{Address: domain.Address{Line1: "Street 2", City: "دبي"}},
+	})
+
+	applyConstraints(req)
+
+	if req.Origin != "DIL" {
+		t.Errorf("expected origin from the transliterated shipper city %q, got %q", req.ShipperCity, req.Origin)
+	}
+	if req.Destination != "DBY" {
+		t.Errorf("expected destination from the transliterated consignee city %q, got %q", req.ConsigneeCity, req.Destination)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 )
 
-func MapToProviderB(req *domain.GenericShippingRequest) *Request {
+func MapToProviderB(req *domain.GenericShippingRequest) (*Request, []domain.MappingWarning) {
 	result := &Request{
 		ProductType:        req.ProductCode,
 		ServiceType:        mapServiceType(req.IsCOD),
@@ -79,7 +79,43 @@ func MapToProviderB(req *domain.GenericShippingRequest) *Request {
 		}
 	}
 
-	return result
+	return result, collectWarnings(req)
+}
+
+func collectWarnings(req *domain.GenericShippingRequest) []domain.MappingWarning {
+	var warnings []domain.MappingWarning
+	drop := func(field, value, detail string) {
+		if value != "" {
+			warnings = append(warnings, domain.MappingWarning{Field: field, Reason: domain.WarningDropped, Detail: detail})
+		}
+	}
+
+	if len(req.Packages) == 0 && (req.Dimensions.Length > 0 || req.Dimensions.Width > 0 || req.Dimensions.Height > 0) {
+		warnings = append(warnings, domain.MappingWarning{
+			Field:  "dimensions",
+			Reason: domain.WarningDropped,
+			Detail: "provider B only accepts dimensions per package; set packages to send them",
+		})
+	}
+
+	drop("shipper.address.state", req.Shipper.Address.State, "provider B has no shipper state field")
+	drop("shipper.address.zipCode", req.Shipper.Address.ZipCode, "provider B has no shipper zip code field")
+	drop("shipper.referenceNo2", req.Shipper.ReferenceNo2, "provider B accepts a single shipper reference")
+	drop("consignee.referenceNo1", req.Consignee.ReferenceNo1, "provider B has no consignee reference field")
+	drop("consignee.referenceNo2", req.Consignee.ReferenceNo2, "provider B has no consignee reference field")
+	drop("remarks", req.Remarks, "provider B has no remarks field")
+	drop("serviceType", req.ServiceType, "provider B service type is derived from isCod")
+	drop("deliveryType", req.DeliveryType, "provider B has no delivery type field")
+	drop("contentType", req.ContentType, "provider B has no content type field")
+
+	for i, ref := range req.ReferenceNumbers {
+		drop(fmt.Sprintf("referenceNumbers[%d]", i), ref, "provider B has no shipment reference fields")
+	}
+	for i, cd := range req.CustomsDeclarations {
+		drop(fmt.Sprintf("customsDeclarations[%d].reference", i), cd.Reference, "provider B has no item reference field")
+	}
+
+	return warnings
 }
 
 func mapServiceType(isCOD bool) string {
@@ -102,8 +138,9 @@ func buildGoodsDescription(declarations []domain.CustomsDeclaration) string {
 }
 
 func extractCityCode(city string) string {
-	if len(city) >= 3 {
-		return strings.ToUpper(city[:3])
+	runes := []rune(city)
+	if len(runes) > 3 {
+		runes = runes[:3]
 	}
-	return strings.ToUpper(city)
+	return strings.ToUpper(string(runes))
 }
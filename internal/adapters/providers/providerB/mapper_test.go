@@ -2,6 +2,7 @@ package providerB
 
 import (
 	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
 	"testing"
 )
 
@@ -101,7 +102,7 @@ func TestMapToProviderB(t *testing.T) {
 		},
 	}
 
-	result := MapToProviderB(genericReq)
+	result, _ := MapToProviderB(genericReq)
 
 	if result.ProductType != "XPS" {
 		t.Errorf("expected product type XPS, got %s", result.ProductType)
@@ -220,7 +221,7 @@ func TestMapToProviderB_CODService(t *testing.T) {
 		},
 	}
 
-	result := MapToProviderB(genericReq)
+	result, _ := MapToProviderB(genericReq)
 
 	if result.ServiceType != "COD" {
 		t.Errorf("expected service type COD, got %s", result.ServiceType)
@@ -250,7 +251,7 @@ func TestMapToProviderB_GoodsDescription(t *testing.T) {
 		},
 	}
 
-	result := MapToProviderB(genericReq)
+	result, _ := MapToProviderB(genericReq)
 
 	expected := "Item 1, Item 2, Item 3"
 	if result.GoodsDescription != expected {
@@ -266,6 +267,7 @@ func TestExtractCityCode(t *testing.T) {
 		{"Dubai", "DUB"},
 		{"London", "LON"},
 		{"NY", "NY"},
+		{"Zürich", "ZÜR"},
 		{"A", "A"},
 		{"", ""},
 	}
@@ -277,3 +279,62 @@ func TestExtractCityCode(t *testing.T) {
 		}
 	}
 }
+
+func TestMapToProviderB_Warnings(t *testing.T) {
+	genericReq := &domain.GenericShippingRequest{
+		Shipper: domain.Party{
+			Address:      domain.Address{City: "Dubai", State: "Dubai"},
+			ReferenceNo2: "ShipperRef2",
+		},
+		Consignee: domain.Party{
+			Address: domain.Address{City: "City"},
+		},
+		Dimensions:       domain.Dimensions{Length: 10, Width: 10, Height: 10},
+		ReferenceNumbers: []string{"Ref1"},
+	}
+
+	_, warnings := MapToProviderB(genericReq)
+
+	fields := make(map[string]string)
+	for _, w := range warnings {
+		fields[w.Field] = w.Reason
+	}
+
+	expected := map[string]string{
+		"dimensions":            domain.WarningDropped,
+		"shipper.address.state": domain.WarningDropped,
+		"shipper.referenceNo2":  domain.WarningDropped,
+		"referenceNumbers[0]":   domain.WarningDropped,
+	}
+
+	for field, reason := range expected {
+		if fields[field] != reason {
+			t.Errorf("expected %s warning for %s, got %q", reason, field, fields[field])
+		}
+	}
+}
+
+func TestMapToProviderB_SamplePayloadCountryCodes(t *testing.T) {
+	_, warnings := MapToProviderB(testutil.CreateSampleShippingRequest())
+
+	for _, w := range warnings {
+		if w.Field == "shipper.address.countryCode" || w.Field == "consignee.address.countryCode" {
+			t.Errorf("expected no warning for %s, which eligibility needs", w.Field)
+		}
+	}
+}
+
+func TestMapToProviderB_DimensionsKeptWithPackages(t *testing.T) {
+	genericReq := &domain.GenericShippingRequest{
+		Dimensions: domain.Dimensions{Length: 10, Width: 10, Height: 10},
+		Packages:   []domain.Package{{Length: 10, Width: 10, Height: 10, Pieces: 1}},
+	}
+
+	_, warnings := MapToProviderB(genericReq)
+
+	for _, w := range warnings {
+		if w.Field == "dimensions" {
+			t.Error("expected no dimensions warning when packages are set")
+		}
+	}
+}
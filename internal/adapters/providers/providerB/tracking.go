@@ -0,0 +1,66 @@
+package providerB
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"shipping-api/internal/core/domain"
+	"time"
+)
+
+func (a *Adapter) SetTrackingEndpoint(endpoint string) {
+	a.trackingEndpoint = endpoint
+}
+
+// GetTrackingPolicy reflects that provider B's tracking endpoint takes a
+// single AWB per request.
+func (a *Adapter) GetTrackingPolicy() domain.TrackingPolicy {
+	return domain.TrackingPolicy{
+		BatchSize:         1,
+		RequestsPerMinute: 30,
+		PollInterval:      time.Hour,
+		MaxPollInterval:   12 * time.Hour,
+		TrackBy:           domain.TrackByAWB,
+	}
+}
+
+func (a *Adapter) TrackShipments(ctx context.Context, shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+	var events []*domain.TrackingEvent
+	for _, shipment := range shipments {
+		if shipment.AWB == "" {
+			continue
+		}
+		tracked, err := a.trackAWB(ctx, shipment.AWB)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, tracked...)
+	}
+	return events, nil
+}
+
+func (a *Adapter) trackAWB(ctx context.Context, awb string) ([]*domain.TrackingEvent, error) {
+	query := url.Values{"AWBNo": {awb}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.trackingEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracking request: %w", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tracking request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tracking request for AWB %s failed with status %d", awb, resp.StatusCode)
+	}
+
+	return a.ParseWebhook(body)
+}
@@ -0,0 +1,47 @@
+package providerB
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestAdapter_TrackShipments(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		awb := r.URL.Query().Get("AWBNo")
+		requested = append(requested, awb)
+		w.Write([]byte(`{"AWBNo": "` + awb + `", "Activities": [{"Code": "IT", "ActivityDate": "2026-01-02T08:00:00"}]}`))
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter("http://test")
+	adapter.SetTrackingEndpoint(server.URL)
+
+	events, err := adapter.TrackShipments(context.Background(), []*domain.ShipmentRecord{{AWB: "B-AWB-1"}, {AWB: "B-AWB-2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requested) != 2 || len(events) != 2 {
+		t.Fatalf("expected one request and event per AWB, got %d requests and %d events", len(requested), len(events))
+	}
+	if events[1].AWB != "B-AWB-2" || events[1].Status != domain.StatusInTransit {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+}
+
+func TestAdapter_TrackShipments_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter("http://test")
+	adapter.SetTrackingEndpoint(server.URL)
+
+	if _, err := adapter.TrackShipments(context.Background(), []*domain.ShipmentRecord{{AWB: "B-AWB-1"}}); err == nil {
+		t.Error("expected error for non-200 tracking response")
+	}
+}
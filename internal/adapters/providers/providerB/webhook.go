@@ -0,0 +1,83 @@
+package providerB
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/adapters/providers/webhook"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"time"
+)
+
+const TokenHeader = "X-Webhook-Token"
+
+const activityDateLayout = "2006-01-02T15:04:05"
+
+type WebhookPayload struct {
+	AWBNo      string            `json:"AWBNo"`
+	Activities []WebhookActivity `json:"Activities"`
+}
+
+type WebhookActivity struct {
+	Code         string `json:"Code"`
+	Description  string `json:"Description"`
+	Location     string `json:"Location"`
+	ActivityDate string `json:"ActivityDate"`
+}
+
+var activityCodes = map[string]domain.ShipmentStatus{
+	"SC":  domain.StatusCreated,
+	"LG":  domain.StatusLabelGenerated,
+	"PU":  domain.StatusPickedUp,
+	"IT":  domain.StatusInTransit,
+	"AH":  domain.StatusInTransit,
+	"OFD": domain.StatusOutForDelivery,
+	"DL":  domain.StatusDelivered,
+	"UD":  domain.StatusException,
+	"EX":  domain.StatusException,
+	"RT":  domain.StatusReturned,
+	"CN":  domain.StatusCancelled,
+}
+
+func (a *Adapter) SetWebhookSecret(secret string) {
+	a.webhookSecret = secret
+}
+
+// VerifyWebhook checks the shared secret provider B sends in the
+// X-Webhook-Token header.
+func (a *Adapter) VerifyWebhook(header http.Header, body []byte) error {
+	return webhook.VerifyToken(a.webhookSecret, header.Get(TokenHeader))
+}
+
+// ParseWebhook converts a provider B activity push. Activities carry no ID,
+// and ActivityDate has no zone and is read as UTC.
+func (a *Adapter) ParseWebhook(body []byte) ([]*domain.TrackingEvent, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse provider B webhook: %w", err)
+	}
+	if payload.AWBNo == "" {
+		return nil, fmt.Errorf("provider B webhook is missing AWBNo")
+	}
+
+	events := make([]*domain.TrackingEvent, 0, len(payload.Activities))
+	for _, activity := range payload.Activities {
+		occurredAt, err := time.Parse(activityDateLayout, activity.ActivityDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ActivityDate %q in provider B webhook", activity.ActivityDate)
+		}
+		code := strings.ToUpper(activity.Code)
+		events = append(events, &domain.TrackingEvent{
+			Provider:      a.GetProviderName(),
+			AWB:           payload.AWBNo,
+			Status:        activityCodes[code],
+			CarrierStatus: code,
+			Description:   activity.Description,
+			Location:      activity.Location,
+			OccurredAt:    occurredAt,
+		})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,58 @@
+package providerB
+
+import (
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestAdapter_ParseWebhook(t *testing.T) {
+	adapter := NewAdapter("http://test")
+	body := []byte(`{"AWBNo": "B-AWB-7", "Activities": [
+		{"Code": "PU", "Description": "Picked up", "Location": "Riyadh", "ActivityDate": "2026-01-02T08:00:00"},
+		{"Code": "DL", "Description": "Delivered", "ActivityDate": "2026-01-03T10:30:00"}
+	]}`)
+
+	events, err := adapter.ParseWebhook(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].AWB != "B-AWB-7" || events[0].Status != domain.StatusPickedUp {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if !events[1].OccurredAt.Equal(time.Date(2026, 1, 3, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected activity date in UTC, got %v", events[1].OccurredAt)
+	}
+	if events[0].Key() == events[1].Key() {
+		t.Error("expected distinct event keys")
+	}
+}
+
+func TestAdapter_ParseWebhook_MissingAWB(t *testing.T) {
+	adapter := NewAdapter("http://test")
+
+	if _, err := adapter.ParseWebhook([]byte(`{"Activities": []}`)); err == nil {
+		t.Error("expected error for missing AWBNo")
+	}
+}
+
+func TestAdapter_VerifyWebhook(t *testing.T) {
+	adapter := NewAdapter("http://test")
+	adapter.SetWebhookSecret("shared")
+
+	header := http.Header{}
+	header.Set(TokenHeader, "shared")
+	if err := adapter.VerifyWebhook(header, nil); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+
+	header.Set(TokenHeader, "wrong")
+	if err := adapter.VerifyWebhook(header, nil); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"shipping-api/internal/core/domain"
+)
+
+// SetRequestID forwards the request ID in req's context, so carrier logs can
+// be correlated with ours.
+func SetRequestID(req *http.Request) {
+	if requestID := domain.RequestIDFromContext(req.Context()); requestID != "" {
+		req.Header.Set(domain.RequestIDHeader, requestID)
+	}
+}
+
+func Categorize(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.ErrorCategoryTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return domain.ErrorCategoryTimeout
+	}
+	return domain.ErrorCategoryNetwork
+}
@@ -0,0 +1,22 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestSetRequestID(t *testing.T) {
+	req, _ := http.NewRequestWithContext(domain.WithRequestID(context.Background(), "req-123"), http.MethodPost, "http://carrier.test", nil)
+	SetRequestID(req)
+	if got := req.Header.Get(domain.RequestIDHeader); got != "req-123" {
+		t.Errorf("expected X-Request-ID req-123, got %q", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, "http://carrier.test", nil)
+	SetRequestID(req)
+	if _, exists := req.Header[domain.RequestIDHeader]; exists {
+		t.Error("expected no header without a request ID")
+	}
+}
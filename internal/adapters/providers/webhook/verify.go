@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"strings"
+)
+
+// VerifyHMAC checks a hex HMAC-SHA256 of the body. The signature may carry a
+// "sha256=" prefix.
+func VerifyHMAC(secret, signature string, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no secret configured", domain.ErrWebhookSignature)
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(expected) == 0 {
+		return fmt.Errorf("%w: malformed signature", domain.ErrWebhookSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return domain.ErrWebhookSignature
+	}
+
+	return nil
+}
+
+func VerifyToken(secret, token string) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no secret configured", domain.ErrWebhookSignature)
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		return domain.ErrWebhookSignature
+	}
+	return nil
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
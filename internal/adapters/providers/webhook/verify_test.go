@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"errors"
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestVerifyHMAC(t *testing.T) {
+	body := []byte(`{"events":[]}`)
+	signature := Sign("secret", body)
+
+	if err := VerifyHMAC("secret", signature, body); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+	if err := VerifyHMAC("other", signature, body); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error for wrong secret, got %v", err)
+	}
+	if err := VerifyHMAC("secret", "not-hex", body); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error for malformed signature, got %v", err)
+	}
+	if err := VerifyHMAC("", signature, body); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error without secret, got %v", err)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	if err := VerifyToken("secret", "secret"); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+	if err := VerifyToken("secret", "guess"); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected signature error, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `
+	id, tenant_id, name, prefix, key_hash, scopes, created_at, expires_at, revoked_at, last_used_at, replaced_by
+`
+
+func (r *PostgresRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	return insertAPIKey(ctx, r.db, key)
+}
+
+func insertAPIKey(ctx context.Context, db execer, key *domain.APIKey) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, scopes, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		key.ID,
+		key.TenantID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		pq.Array(key.Scopes),
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save api key: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.findAPIKey(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+}
+
+func (r *PostgresRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return r.findAPIKey(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+}
+
+func (r *PostgresRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	keys, err := r.queryAPIKeys(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RotateAPIKey stores the replacement and limits the old key to the overlap
+// window, so clients can switch over without downtime.
+func (r *PostgresRepository) RotateAPIKey(ctx context.Context, oldID string, replacement *domain.APIKey, oldExpiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin api key rotation: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE api_keys
+		SET expires_at = LEAST(COALESCE(expires_at, $2), $2), replaced_by = $3
+		WHERE id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, oldID, oldExpiresAt, replacement.ID)
+	if err != nil {
+		return fmt.Errorf("failed to expire rotated api key: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		if _, err := r.FindAPIKey(ctx, oldID); err != nil {
+			return err
+		}
+		return domain.ErrAPIKeyInactive
+	}
+
+	if err := insertAPIKey(ctx, tx, replacement); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit api key rotation: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1
+	`, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record api key use: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) findAPIKey(ctx context.Context, query string, arg interface{}) (*domain.APIKey, error) {
+	keys, err := r.queryAPIKeys(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return keys[0], nil
+}
+
+func (r *PostgresRepository) queryAPIKeys(ctx context.Context, query string, args ...interface{}) ([]*domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key := &domain.APIKey{}
+		err := rows.Scan(
+			&key.ID,
+			&key.TenantID,
+			&key.Name,
+			&key.Prefix,
+			&key.Hash,
+			pq.Array(&key.Scopes),
+			&key.CreatedAt,
+			&key.ExpiresAt,
+			&key.RevokedAt,
+			&key.LastUsedAt,
+			&key.ReplacedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+)
+
+const attemptColumns = `
+	id, tenant_id, request_id, COALESCE(shipment_id, ''), provider, attempt_number,
+	request_body, response_status, response_headers, response_body,
+	duration_ms, error_category, error_message, success, created_at
+`
+
+// attemptNumberRetries bounds how often SaveAttempt renumbers an attempt
+// that lost a race for its number against a concurrent attempt.
+const attemptNumberRetries = 5
+
+// SaveAttempt stores the attempt and, for an attempt that produced no
+// shipment, a shipment.failed outbox event in the same transaction.
+func (r *PostgresRepository) SaveAttempt(ctx context.Context, attempt *domain.ShipmentAttempt) error {
+	if attempt.TenantID == "" {
+		attempt.TenantID = domain.TenantIDFromContext(ctx)
+	}
+
+	failed, err := attempt.FailedEvent()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		err := r.insertAttempt(ctx, attempt, failed)
+		if err == nil || i == attemptNumberRetries-1 || !isUniqueViolation(err) {
+			return err
+		}
+	}
+}
+
+func (r *PostgresRepository) insertAttempt(ctx context.Context, attempt *domain.ShipmentAttempt, failed *domain.ShipmentEvent) error {
+	query := `
+		INSERT INTO shipment_attempts (
+			id, request_id, shipment_id, provider, attempt_number,
+			request_body, response_status, response_headers, response_body,
+			duration_ms, error_category, error_message, success, created_at, tenant_id
+		) VALUES (
+			$1, $2, NULLIF($3, ''), $4,
+			(SELECT COALESCE(MAX(attempt_number), 0) + 1 FROM shipment_attempts WHERE request_id = $2 AND provider = $4),
+			$5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		)
+		RETURNING attempt_number
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin shipment attempt save: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		attempt.ID,
+		attempt.RequestID,
+		attempt.ShipmentID,
+		attempt.Provider,
+		attempt.RequestBody,
+		attempt.ResponseStatus,
+		attempt.ResponseHeaders,
+		attempt.ResponseBody,
+		attempt.DurationMs,
+		attempt.ErrorCategory,
+		attempt.ErrorMessage,
+		attempt.Success,
+		attempt.CreatedAt,
+		attempt.TenantID,
+	).Scan(&attempt.AttemptNumber)
+
+	if err != nil {
+		return fmt.Errorf("failed to save shipment attempt: %w", err)
+	}
+
+	if failed != nil {
+		if err := insertOutboxEvents(ctx, tx, failed); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit shipment attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) FindAttemptsByRequestID(ctx context.Context, requestID string) ([]*domain.ShipmentAttempt, error) {
+	query := `SELECT` + attemptColumns + `
+		FROM shipment_attempts
+		WHERE request_id = $1 AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at, attempt_number
+	`
+	return r.queryAttempts(ctx, query, requestID, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) FindAttemptsByShipmentID(ctx context.Context, shipmentID string) ([]*domain.ShipmentAttempt, error) {
+	query := `SELECT` + attemptColumns + `
+		FROM shipment_attempts
+		WHERE request_id IN (SELECT request_id FROM shipment_attempts WHERE shipment_id = $1)
+			AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at, attempt_number
+	`
+	return r.queryAttempts(ctx, query, shipmentID, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) queryAttempts(ctx context.Context, query string, args ...interface{}) ([]*domain.ShipmentAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shipment attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*domain.ShipmentAttempt
+	for rows.Next() {
+		attempt := &domain.ShipmentAttempt{}
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.TenantID,
+			&attempt.RequestID,
+			&attempt.ShipmentID,
+			&attempt.Provider,
+			&attempt.AttemptNumber,
+			&attempt.RequestBody,
+			&attempt.ResponseStatus,
+			&attempt.ResponseHeaders,
+			&attempt.ResponseBody,
+			&attempt.DurationMs,
+			&attempt.ErrorCategory,
+			&attempt.ErrorMessage,
+			&attempt.Success,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shipment attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
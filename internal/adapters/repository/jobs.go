@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"time"
+)
+
+const jobColumns = `
+	id, tenant_id, status, provider, request_id, request, results, error,
+	attempts, max_attempts, run_at, created_at, updated_at, completed_at
+`
+
+func (r *PostgresRepository) CreateJob(ctx context.Context, job *domain.Job) error {
+	if job.TenantID == "" {
+		job.TenantID = domain.TenantIDFromContext(ctx)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shipment_jobs (
+			id, tenant_id, status, provider, request_id, request, max_attempts, run_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		job.ID,
+		job.TenantID,
+		job.Status,
+		job.Provider,
+		job.RequestID,
+		[]byte(job.Request),
+		job.MaxAttempts,
+		job.RunAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// ClaimJobs marks due queued jobs running and counts the attempt. The lease
+// moves run_at forward; a job still running when it expires is failed by
+// FailExpiredJobs rather than claimed again.
+func (r *PostgresRepository) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]*domain.Job, error) {
+	now := time.Now()
+	query := `
+		UPDATE shipment_jobs
+		SET status = 'running', attempts = attempts + 1, run_at = $3, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM shipment_jobs
+			WHERE status = 'queued' AND run_at <= $1
+			ORDER BY run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	jobs, err := r.queryJobs(ctx, query, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// FailExpiredJobs fails running jobs whose lease expired, because their worker
+// died after the job may have reached the carriers.
+func (r *PostgresRepository) FailExpiredJobs(ctx context.Context, reason string) (int, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE shipment_jobs
+		SET status = 'failed', error = $2, updated_at = $1, completed_at = $1
+		WHERE status = 'running' AND run_at <= $1
+	`, now, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail expired jobs: %w", err)
+	}
+	failed, _ := result.RowsAffected()
+	return int(failed), nil
+}
+
+// UpdateJob stores the outcome of an attempt: its status, results, error
+// and, for a retry, the next run time.
+func (r *PostgresRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	var results []byte
+	if len(job.Results) > 0 {
+		results = job.Results
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE shipment_jobs
+		SET status = $2, results = $3, error = $4, run_at = $5, updated_at = $6, completed_at = $7
+		WHERE id = $1
+	`,
+		job.ID,
+		job.Status,
+		results,
+		job.Error,
+		job.RunAt,
+		job.UpdatedAt,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindJob(ctx context.Context, id string) (*domain.Job, error) {
+	jobs, err := r.queryJobs(ctx, `SELECT `+jobColumns+` FROM shipment_jobs WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`, id, tenantScope(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, domain.ErrJobNotFound
+	}
+	return jobs[0], nil
+}
+
+func (r *PostgresRepository) ListJobs(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM shipment_jobs WHERE ($2 = '' OR tenant_id = $2)`
+	args := []interface{}{limit, tenantScope(ctx)}
+	if filter.Status != "" {
+		query += ` AND status = $3`
+		args = append(args, filter.Status)
+	}
+	query += ` ORDER BY created_at DESC LIMIT $1`
+
+	jobs, err := r.queryJobs(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *PostgresRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*domain.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job := &domain.Job{}
+		var request, results []byte
+		err := rows.Scan(
+			&job.ID,
+			&job.TenantID,
+			&job.Status,
+			&job.Provider,
+			&job.RequestID,
+			&request,
+			&results,
+			&job.Error,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.RunAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+			&job.CompletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		job.Request = request
+		job.Results = results
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
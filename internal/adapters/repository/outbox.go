@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const outboxColumns = `
+	sequence, event_id, tenant_id, event_type, shipment_id, data, occurred_at,
+	attempts, next_attempt_at, last_error, published_at, dead_at, created_at
+`
+
+// ClaimOutboxEvents leases the oldest unpublished event of each shipment
+// that is due, in sequence order. An event is only claimable once every
+// earlier event for its shipment has been published or dead-lettered, which
+// keeps relays on several replicas from reordering a shipment's events.
+// Events without a shipment are not ordered.
+func (r *PostgresRepository) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	now := time.Now()
+	query := `
+		UPDATE event_outbox
+		SET next_attempt_at = $3
+		WHERE sequence IN (
+			SELECT o.sequence FROM event_outbox o
+			WHERE o.published_at IS NULL AND o.dead_at IS NULL AND o.next_attempt_at <= $1
+				AND (o.shipment_id = '' OR NOT EXISTS (
+					SELECT 1 FROM event_outbox earlier
+					WHERE earlier.shipment_id = o.shipment_id
+						AND earlier.published_at IS NULL
+						AND earlier.dead_at IS NULL
+						AND earlier.sequence < o.sequence
+				))
+			ORDER BY o.sequence
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.OutboxEvent
+	for rows.Next() {
+		event := &domain.OutboxEvent{Event: &domain.ShipmentEvent{}}
+		err := rows.Scan(
+			&event.Sequence,
+			&event.Event.ID,
+			&event.Event.TenantID,
+			&event.Event.Type,
+			&event.Event.ShipmentID,
+			&event.Event.Data,
+			&event.Event.OccurredAt,
+			&event.Attempts,
+			&event.NextAttemptAt,
+			&event.LastError,
+			&event.PublishedAt,
+			&event.DeadAt,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Sequence < events[j].Sequence })
+	return events, nil
+}
+
+func (r *PostgresRepository) MarkOutboxPublished(ctx context.Context, sequence int64, publishedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE event_outbox SET published_at = $2, attempts = attempts + 1, last_error = '' WHERE sequence = $1`,
+		sequence, publishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// RescheduleOutboxEvent stores a failed relay attempt and when to retry.
+func (r *PostgresRepository) RescheduleOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE event_outbox SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE sequence = $1`,
+		event.Sequence, event.Attempts, event.NextAttemptAt, event.LastError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %w", err)
+	}
+	return nil
+}
+
+// DeadLetterOutboxEvent stores the last failed attempt of an event the relay
+// gave up on.
+func (r *PostgresRepository) DeadLetterOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE event_outbox SET attempts = $2, last_error = $3, dead_at = $4 WHERE sequence = $1`,
+		event.Sequence, event.Attempts, event.LastError, event.DeadAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter outbox event: %w", err)
+	}
+	return nil
+}
+
+func insertOutboxEvents(ctx context.Context, db execer, events ...*domain.ShipmentEvent) error {
+	for _, event := range events {
+		if event.ID == "" {
+			event.ID = uuid.New().String()
+		}
+		if event.TenantID == "" {
+			event.TenantID = domain.DefaultTenantID
+		}
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO event_outbox (event_id, tenant_id, event_type, shipment_id, data, occurred_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+			event.ID,
+			event.TenantID,
+			event.Type,
+			event.ShipmentID,
+			[]byte(event.Data),
+			event.OccurredAt,
+			time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+	}
+	return nil
+}
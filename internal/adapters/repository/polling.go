@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var terminalStatuses = []string{
+	string(domain.StatusDelivered),
+	string(domain.StatusReturned),
+	string(domain.StatusCancelled),
+}
+
+// ClaimShipmentsForPolling leases up to policy.BatchSize due shipments of a
+// provider that carry the identifier it tracks by. The lease pushes
+// next_poll_at forward, and rows another replica is claiming are skipped.
+// Each claimed shipment also holds a session advisory lock until release is
+// called, and shipments locked elsewhere are skipped, so a replica whose
+// lease ran out mid-poll still keeps the others from polling its shipments.
+func (r *PostgresRepository) ClaimShipmentsForPolling(ctx context.Context, provider string, policy domain.TrackingPolicy, lease time.Duration) ([]*domain.ShipmentRecord, func() error, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim shipments for polling: %w", err)
+	}
+	release := func() error { return releaseAdvisoryLocks(conn) }
+
+	now := time.Now()
+	query := `
+		WITH due AS (
+			SELECT id FROM shipment_records
+			WHERE provider = $1
+				AND success
+				AND status <> ALL($2)
+				AND next_poll_at <= $3
+				AND CASE $6
+					WHEN 'trackingId' THEN tracking_id <> ''
+					WHEN 'awb' THEN awb <> ''
+					ELSE tracking_id <> '' OR awb <> ''
+				END
+			ORDER BY next_poll_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		), locked AS (
+			SELECT id FROM due
+			WHERE pg_try_advisory_lock(hashtext('shipment_poll'), hashtext(id))
+		)
+		UPDATE shipment_records
+		SET next_poll_at = $5
+		WHERE id IN (SELECT id FROM locked)
+		RETURNING ` + recordColumns
+
+	rows, err := conn.QueryContext(ctx, query, provider, pq.Array(terminalStatuses), now, policy.BatchSize, now.Add(lease), policy.TrackBy)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("failed to claim shipments for polling: %w", err)
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("failed to claim shipments for polling: %w", err)
+	}
+
+	return records, release, nil
+}
+
+// releaseAdvisoryLocks drops every advisory lock held by conn and returns it
+// to the pool. A connection whose locks could not be dropped is discarded,
+// which ends its session and the locks with it.
+func releaseAdvisoryLocks(conn *sql.Conn) error {
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock_all()`); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		return fmt.Errorf("failed to release poll locks: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) SchedulePoll(ctx context.Context, shipmentID string, nextPollAt time.Time, unchangedPolls int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE shipment_records SET next_poll_at = $2, unchanged_polls = $3 WHERE id = $1`,
+		shipmentID, nextPollAt, unchangedPolls,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule poll: %w", err)
+	}
+	return nil
+}
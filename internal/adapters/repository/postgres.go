@@ -3,12 +3,37 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"shipping-api/internal/core/domain"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+const recordColumns = `id, tenant_id, provider, generic_payload, transformed_payload,
+			provider_response, raw_response, success, created_at,
+			tracking_id, awb, reference_numbers, destination_country, consignee_email_hash,
+			status, status_updated_at, next_poll_at, unchanged_polls`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// tenantScope returns the tenant a query is limited to. Work done outside a
+// tenant, such as polling and carrier webhooks, gets "" and sees every
+// tenant.
+func tenantScope(ctx context.Context) string {
+	if tenant := domain.TenantFromContext(ctx); tenant != nil {
+		return tenant.ID
+	}
+	return ""
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 type PostgresRepository struct {
 	db *sql.DB
 }
@@ -26,54 +51,86 @@ func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 	return &PostgresRepository{db: db}, nil
 }
 
+// Save stores the record with its initial status history entry and its
+// shipment.created outbox event in one transaction.
 func (r *PostgresRepository) Save(ctx context.Context, record *domain.ShipmentRecord) error {
+	record.PopulateIndexFields()
+	if record.TenantID == "" {
+		record.TenantID = domain.TenantIDFromContext(ctx)
+	}
+	initial := record.InitialStatusChange()
+	if record.NextPollAt.IsZero() {
+		record.NextPollAt = record.CreatedAt
+	}
+
 	query := `
-		INSERT INTO shipment_records (
-			id, provider, generic_payload, transformed_payload,
-			provider_response, success, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO shipment_records (` + recordColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 
-	_, err := r.db.ExecContext(
+	references := record.ReferenceNumbers
+	if references == nil {
+		references = []string{}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin shipment save: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		record.ID,
+		record.TenantID,
 		record.Provider,
 		record.GenericPayload,
 		record.TransformedPayload,
 		record.ProviderResponse,
+		record.RawResponse,
 		record.Success,
 		record.CreatedAt,
+		record.TrackingID,
+		record.AWB,
+		pq.Array(references),
+		record.DestinationCountry,
+		record.ConsigneeEmailHash,
+		record.Status,
+		record.StatusUpdatedAt,
+		record.NextPollAt,
+		record.UnchangedPolls,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to save shipment record: %w", err)
 	}
 
+	if err := insertStatusChange(ctx, tx, initial); err != nil {
+		return err
+	}
+
+	created, err := record.CreatedEvent(domain.RequestIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err := insertOutboxEvents(ctx, tx, created); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit shipment record: %w", err)
+	}
+
 	return nil
 }
 
 func (r *PostgresRepository) FindByID(ctx context.Context, id string) (*domain.ShipmentRecord, error) {
-	query := `
-		SELECT id, provider, generic_payload, transformed_payload,
-			provider_response, success, created_at
-		FROM shipment_records
-		WHERE id = $1
-	`
-
-	record := &domain.ShipmentRecord{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&record.ID,
-		&record.Provider,
-		&record.GenericPayload,
-		&record.TransformedPayload,
-		&record.ProviderResponse,
-		&record.Success,
-		&record.CreatedAt,
-	)
+	query := `SELECT ` + recordColumns + ` FROM shipment_records WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`
 
+	record, err := scanRecord(r.db.QueryRowContext(ctx, query, id, tenantScope(ctx)))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("shipment record not found")
+		return nil, domain.ErrShipmentNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find shipment record: %w", err)
@@ -84,41 +141,103 @@ func (r *PostgresRepository) FindByID(ctx context.Context, id string) (*domain.S
 
 func (r *PostgresRepository) FindByProvider(ctx context.Context, provider string, limit int) ([]*domain.ShipmentRecord, error) {
 	query := `
-		SELECT id, provider, generic_payload, transformed_payload,
-			provider_response, success, created_at
+		SELECT ` + recordColumns + `
 		FROM shipment_records
-		WHERE provider = $1
+		WHERE provider = $1 AND ($3 = '' OR tenant_id = $3)
 		ORDER BY created_at DESC
 		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, provider, limit)
+	return r.queryRecords(ctx, query, provider, limit, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) FindByTrackingID(ctx context.Context, trackingID string) ([]*domain.ShipmentRecord, error) {
+	query := `
+		SELECT ` + recordColumns + `
+		FROM shipment_records
+		WHERE tracking_id = $1 AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at DESC
+	`
+
+	return r.queryRecords(ctx, query, trackingID, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) FindByAWB(ctx context.Context, awb string) ([]*domain.ShipmentRecord, error) {
+	query := `
+		SELECT ` + recordColumns + `
+		FROM shipment_records
+		WHERE awb = $1 AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at DESC
+	`
+
+	return r.queryRecords(ctx, query, awb, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) FindByReference(ctx context.Context, reference string) ([]*domain.ShipmentRecord, error) {
+	query := `
+		SELECT ` + recordColumns + `
+		FROM shipment_records
+		WHERE reference_numbers @> ARRAY[$1]::text[] AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at DESC
+	`
+
+	return r.queryRecords(ctx, query, reference, tenantScope(ctx))
+}
+
+func (r *PostgresRepository) queryRecords(ctx context.Context, query string, args ...interface{}) ([]*domain.ShipmentRecord, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query shipment records: %w", err)
 	}
+	return scanRecords(rows)
+}
+
+func scanRecords(rows *sql.Rows) ([]*domain.ShipmentRecord, error) {
 	defer rows.Close()
 
 	var records []*domain.ShipmentRecord
 	for rows.Next() {
-		record := &domain.ShipmentRecord{}
-		err := rows.Scan(
-			&record.ID,
-			&record.Provider,
-			&record.GenericPayload,
-			&record.TransformedPayload,
-			&record.ProviderResponse,
-			&record.Success,
-			&record.CreatedAt,
-		)
+		record, err := scanRecord(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan shipment record: %w", err)
 		}
 		records = append(records, record)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query shipment records: %w", err)
+	}
 
 	return records, nil
 }
 
+func scanRecord(row rowScanner) (*domain.ShipmentRecord, error) {
+	record := &domain.ShipmentRecord{}
+	err := row.Scan(
+		&record.ID,
+		&record.TenantID,
+		&record.Provider,
+		&record.GenericPayload,
+		&record.TransformedPayload,
+		&record.ProviderResponse,
+		&record.RawResponse,
+		&record.Success,
+		&record.CreatedAt,
+		&record.TrackingID,
+		&record.AWB,
+		pq.Array(&record.ReferenceNumbers),
+		&record.DestinationCountry,
+		&record.ConsigneeEmailHash,
+		&record.Status,
+		&record.StatusUpdatedAt,
+		&record.NextPollAt,
+		&record.UnchangedPolls,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
 func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"shipping-api/internal/core/domain"
@@ -31,12 +32,44 @@ func setupTestDB(t *testing.T) (*PostgresRepository, func()) {
 	createTableSQL := `
 		CREATE TABLE IF NOT EXISTS shipment_records (
 			id VARCHAR(36) PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
 			provider VARCHAR(50) NOT NULL,
 			generic_payload JSONB NOT NULL,
 			transformed_payload JSONB NOT NULL,
 			provider_response JSONB NOT NULL,
+			raw_response BYTEA,
 			success BOOLEAN NOT NULL DEFAULT false,
-			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			tracking_id VARCHAR(100) NOT NULL DEFAULT '',
+			awb VARCHAR(100) NOT NULL DEFAULT '',
+			reference_numbers TEXT[] NOT NULL DEFAULT '{}',
+			destination_country TEXT NOT NULL DEFAULT '',
+			consignee_email_hash VARCHAR(64) NOT NULL DEFAULT '',
+			status VARCHAR(32) NOT NULL DEFAULT 'created',
+			status_updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			next_poll_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			unchanged_polls INT NOT NULL DEFAULT 0
+		);
+	`
+
+	createAttemptsTableSQL := `
+		CREATE TABLE IF NOT EXISTS shipment_attempts (
+			id VARCHAR(36) PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+			request_id VARCHAR(64) NOT NULL,
+			shipment_id VARCHAR(36) REFERENCES shipment_records(id),
+			provider VARCHAR(50) NOT NULL,
+			attempt_number INT NOT NULL DEFAULT 1,
+			request_body BYTEA,
+			response_status INT NOT NULL DEFAULT 0,
+			response_headers JSONB,
+			response_body BYTEA,
+			duration_ms BIGINT NOT NULL DEFAULT 0,
+			error_category VARCHAR(32) NOT NULL DEFAULT '',
+			error_message TEXT NOT NULL DEFAULT '',
+			success BOOLEAN NOT NULL DEFAULT false,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			UNIQUE (request_id, provider, attempt_number)
 		);
 	`
 
@@ -44,9 +77,162 @@ func setupTestDB(t *testing.T) (*PostgresRepository, func()) {
 		t.Fatalf("failed to create test table: %v", err)
 	}
 
+	if _, err := db.Exec(createAttemptsTableSQL); err != nil {
+		t.Fatalf("failed to create attempts table: %v", err)
+	}
+
+	createStatusHistoryTableSQL := `
+		CREATE TABLE IF NOT EXISTS shipment_status_history (
+			id VARCHAR(36) PRIMARY KEY,
+			shipment_id VARCHAR(36) NOT NULL REFERENCES shipment_records(id),
+			from_status VARCHAR(32) NOT NULL DEFAULT '',
+			to_status VARCHAR(32) NOT NULL,
+			source VARCHAR(16) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			location TEXT NOT NULL DEFAULT '',
+			event_id VARCHAR(128) NOT NULL DEFAULT '',
+			carrier_status VARCHAR(64) NOT NULL DEFAULT '',
+			occurred_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+	`
+
+	if _, err := db.Exec(createStatusHistoryTableSQL); err != nil {
+		t.Fatalf("failed to create status history table: %v", err)
+	}
+
+	createWebhookTablesSQL := `
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id VARCHAR(36) PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+			url TEXT NOT NULL,
+			event_types TEXT[] NOT NULL,
+			secret VARCHAR(128) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id VARCHAR(36) PRIMARY KEY,
+			subscription_id VARCHAR(36) NOT NULL REFERENCES webhook_subscriptions(id),
+			event_id VARCHAR(36) NOT NULL,
+			event_type VARCHAR(64) NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'pending',
+			attempts INT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			last_status_code INT NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			UNIQUE (subscription_id, event_id)
+		);
+		CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+			id VARCHAR(36) PRIMARY KEY,
+			delivery_id VARCHAR(36) NOT NULL REFERENCES webhook_deliveries(id),
+			attempt_number INT NOT NULL,
+			status_code INT NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			duration_ms BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+	`
+
+	if _, err := db.Exec(createWebhookTablesSQL); err != nil {
+		t.Fatalf("failed to create webhook tables: %v", err)
+	}
+
+	createOutboxTableSQL := `
+		CREATE TABLE IF NOT EXISTS event_outbox (
+			sequence BIGSERIAL PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+			event_id VARCHAR(36) NOT NULL UNIQUE,
+			event_type VARCHAR(64) NOT NULL,
+			shipment_id VARCHAR(36) NOT NULL DEFAULT '',
+			data JSONB NOT NULL,
+			occurred_at TIMESTAMP NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			last_error TEXT NOT NULL DEFAULT '',
+			published_at TIMESTAMP,
+			dead_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		);
+	`
+
+	if _, err := db.Exec(createOutboxTableSQL); err != nil {
+		t.Fatalf("failed to create outbox table: %v", err)
+	}
+
+	createJobsTableSQL := `
+		CREATE TABLE IF NOT EXISTS shipment_jobs (
+			id VARCHAR(36) PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+			status VARCHAR(16) NOT NULL DEFAULT 'queued',
+			provider VARCHAR(50) NOT NULL DEFAULT '',
+			request_id VARCHAR(36) NOT NULL,
+			request JSONB NOT NULL,
+			results JSONB,
+			error TEXT NOT NULL DEFAULT '',
+			attempts INT NOT NULL DEFAULT 0,
+			max_attempts INT NOT NULL,
+			run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			completed_at TIMESTAMP
+		);
+	`
+
+	if _, err := db.Exec(createJobsTableSQL); err != nil {
+		t.Fatalf("failed to create jobs table: %v", err)
+	}
+
+	createAPIKeysTableSQL := `
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR(36) PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+			name VARCHAR(255) NOT NULL,
+			prefix VARCHAR(16) NOT NULL,
+			key_hash CHAR(64) NOT NULL UNIQUE,
+			scopes TEXT[] NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			last_used_at TIMESTAMP,
+			replaced_by VARCHAR(36) NOT NULL DEFAULT ''
+		);
+	`
+
+	if _, err := db.Exec(createAPIKeysTableSQL); err != nil {
+		t.Fatalf("failed to create api keys table: %v", err)
+	}
+
+	createUsageTableSQL := `
+		CREATE TABLE IF NOT EXISTS usage_counters (
+			tenant_id VARCHAR(64) NOT NULL,
+			metric VARCHAR(16) NOT NULL,
+			window_start TIMESTAMP NOT NULL,
+			count INT NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (tenant_id, metric, window_start)
+		);
+	`
+
+	if _, err := db.Exec(createUsageTableSQL); err != nil {
+		t.Fatalf("failed to create usage table: %v", err)
+	}
+
 	repo := &PostgresRepository{db: db}
 
 	cleanup := func() {
+		db.Exec("DROP TABLE IF EXISTS usage_counters")
+		db.Exec("DROP TABLE IF EXISTS api_keys")
+		db.Exec("DROP TABLE IF EXISTS shipment_jobs")
+		db.Exec("DROP TABLE IF EXISTS event_outbox")
+		db.Exec("DROP TABLE IF EXISTS webhook_delivery_attempts")
+		db.Exec("DROP TABLE IF EXISTS webhook_deliveries")
+		db.Exec("DROP TABLE IF EXISTS webhook_subscriptions")
+		db.Exec("DROP TABLE IF EXISTS shipment_status_history")
+		db.Exec("DROP TABLE IF EXISTS shipment_attempts")
 		db.Exec("DROP TABLE IF EXISTS shipment_records")
 		db.Close()
 	}
@@ -263,3 +449,738 @@ func TestPostgresRepository_SaveAndRetrieve_JSONData(t *testing.T) {
 		t.Errorf("expected 2 items, got %d", len(items))
 	}
 }
+
+func TestPostgresRepository_SaveAttempt(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	requestID := uuid.New().String()
+
+	for i := 0; i < 2; i++ {
+		attempt := &domain.ShipmentAttempt{
+			ID:              uuid.New().String(),
+			RequestID:       requestID,
+			Provider:        "A",
+			RequestBody:     []byte(`{"weight": 1}`),
+			ResponseStatus:  502,
+			ResponseHeaders: []byte(`{"Content-Type": ["text/html"]}`),
+			ResponseBody:    []byte("<html>Bad Gateway</html>"),
+			DurationMs:      120,
+			ErrorCategory:   domain.ErrorCategoryProvider,
+			CreatedAt:       time.Now(),
+		}
+		if err := repo.SaveAttempt(context.Background(), attempt); err != nil {
+			t.Fatalf("failed to save attempt: %v", err)
+		}
+		if attempt.AttemptNumber != i+1 {
+			t.Errorf("expected attempt number %d, got %d", i+1, attempt.AttemptNumber)
+		}
+	}
+
+	attempts, err := repo.FindAttemptsByRequestID(context.Background(), requestID)
+	if err != nil {
+		t.Fatalf("failed to find attempts: %v", err)
+	}
+
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+
+	if string(attempts[0].ResponseBody) != "<html>Bad Gateway</html>" {
+		t.Errorf("expected raw response body to be preserved, got %s", attempts[0].ResponseBody)
+	}
+
+	if attempts[0].ShipmentID != "" {
+		t.Errorf("expected no shipment ID for failed attempt, got %s", attempts[0].ShipmentID)
+	}
+}
+
+func TestPostgresRepository_SaveAttempt_Concurrent(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	requestID := uuid.New().String()
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			errs <- repo.SaveAttempt(context.Background(), &domain.ShipmentAttempt{
+				ID:        uuid.New().String(),
+				RequestID: requestID,
+				Provider:  "A",
+				Success:   true,
+				CreatedAt: time.Now(),
+			})
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("failed to save attempt: %v", err)
+		}
+	}
+
+	attempts, err := repo.FindAttemptsByRequestID(context.Background(), requestID)
+	if err != nil {
+		t.Fatalf("failed to find attempts: %v", err)
+	}
+	seen := make(map[int]bool)
+	for _, attempt := range attempts {
+		if seen[attempt.AttemptNumber] {
+			t.Errorf("attempt number %d assigned twice", attempt.AttemptNumber)
+		}
+		seen[attempt.AttemptNumber] = true
+	}
+}
+
+func TestPostgresRepository_Save_LongCountryCode(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	record := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "A",
+		GenericPayload:     []byte(`{"consignee": {"address": {"countryCode": "gbr"}}}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{}`),
+		Success:            true,
+		CreatedAt:          time.Now(),
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("expected a booked shipment with a 3-letter country code to be stored, got %v", err)
+	}
+
+	page, err := repo.Search(context.Background(), domain.ShipmentFilter{Destination: "GBR"})
+	if err != nil {
+		t.Fatalf("failed to search by destination: %v", err)
+	}
+	if len(page.Records) != 1 {
+		t.Errorf("expected 1 record for GBR, got %d", len(page.Records))
+	}
+}
+
+func TestPostgresRepository_Search(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		record := &domain.ShipmentRecord{
+			ID:                 uuid.New().String(),
+			Provider:           "A",
+			GenericPayload:     []byte(fmt.Sprintf(`{"referenceNumbers": ["REF-%d"], "consignee": {"contact": {"emailAddress": "user%d@test.com"}}}`, i, i)),
+			TransformedPayload: []byte(`{}`),
+			ProviderResponse:   []byte(fmt.Sprintf(`{"trackingId": "TRACK-%d", "awb": "AWB-%d"}`, i, i)),
+			Success:            i%2 == 0,
+			CreatedAt:          base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := repo.Save(context.Background(), record); err != nil {
+			t.Fatalf("failed to save record: %v", err)
+		}
+	}
+
+	page, err := repo.Search(context.Background(), domain.ShipmentFilter{AWB: "AWB-3"})
+	if err != nil {
+		t.Fatalf("failed to search by AWB: %v", err)
+	}
+	if len(page.Records) != 1 {
+		t.Errorf("expected 1 record for AWB-3, got %d", len(page.Records))
+	}
+
+	page, err = repo.Search(context.Background(), domain.ShipmentFilter{ReferenceNumber: "REF-2", ConsigneeEmail: "USER2@test.com"})
+	if err != nil {
+		t.Fatalf("failed to search by reference and email: %v", err)
+	}
+	if len(page.Records) != 1 {
+		t.Errorf("expected 1 record for REF-2, got %d", len(page.Records))
+	}
+
+	var seen []string
+	filter := domain.ShipmentFilter{Limit: 2, SortOrder: domain.SortAscending}
+	for {
+		page, err := repo.Search(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("failed to page records: %v", err)
+		}
+		for _, record := range page.Records {
+			seen = append(seen, record.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("expected 5 records across pages, got %d", len(seen))
+	}
+}
+
+func TestPostgresRepository_FindByIndexedColumns(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	record := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "B",
+		GenericPayload:     []byte(`{"referenceNumbers": ["ORDER-1", "ORDER-2"], "consignee": {"address": {"countryCode": "in"}, "contact": {"emailAddress": "Receiver@Test.com"}}}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{"trackingId": "TRACK-9", "awb": "AWB-9"}`),
+		Success:            true,
+		CreatedAt:          time.Now(),
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+
+	found, err := repo.FindByID(context.Background(), record.ID)
+	if err != nil {
+		t.Fatalf("failed to find record: %v", err)
+	}
+	if found.TrackingID != "TRACK-9" || found.AWB != "AWB-9" {
+		t.Errorf("expected tracking TRACK-9 and AWB AWB-9, got %q and %q", found.TrackingID, found.AWB)
+	}
+	if found.DestinationCountry != "IN" {
+		t.Errorf("expected destination IN, got %q", found.DestinationCountry)
+	}
+	if found.ConsigneeEmailHash != domain.HashEmail("receiver@test.com") {
+		t.Errorf("expected consignee email hash to be stored")
+	}
+	if len(found.ReferenceNumbers) != 2 {
+		t.Errorf("expected 2 reference numbers, got %v", found.ReferenceNumbers)
+	}
+
+	for name, find := range map[string]func() ([]*domain.ShipmentRecord, error){
+		"tracking ID": func() ([]*domain.ShipmentRecord, error) {
+			return repo.FindByTrackingID(context.Background(), "TRACK-9")
+		},
+		"AWB":       func() ([]*domain.ShipmentRecord, error) { return repo.FindByAWB(context.Background(), "AWB-9") },
+		"reference": func() ([]*domain.ShipmentRecord, error) { return repo.FindByReference(context.Background(), "ORDER-2") },
+	} {
+		records, err := find()
+		if err != nil {
+			t.Fatalf("failed to find by %s: %v", name, err)
+		}
+		if len(records) != 1 || records[0].ID != record.ID {
+			t.Errorf("expected record by %s, got %d records", name, len(records))
+		}
+	}
+
+	page, err := repo.Search(context.Background(), domain.ShipmentFilter{Destination: "IN"})
+	if err != nil {
+		t.Fatalf("failed to search by destination: %v", err)
+	}
+	if len(page.Records) != 1 {
+		t.Errorf("expected 1 record for destination IN, got %d", len(page.Records))
+	}
+}
+
+func TestPostgresRepository_UpdateStatus(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	record := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "A",
+		GenericPayload:     []byte(`{}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{}`),
+		Success:            true,
+		CreatedAt:          time.Now(),
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+
+	change := &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusInTransit, Source: domain.StatusSourcePoller}
+	if err := repo.UpdateStatus(context.Background(), change); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	if change.FromStatus != domain.StatusCreated {
+		t.Errorf("expected from status created, got %s", change.FromStatus)
+	}
+
+	invalid := &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusCancelled, Source: domain.StatusSourceAPI}
+	var transitionErr *domain.TransitionError
+	if err := repo.UpdateStatus(context.Background(), invalid); !errors.As(err, &transitionErr) {
+		t.Errorf("expected TransitionError, got %v", err)
+	}
+
+	scan := &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusInTransit, Source: domain.StatusSourceWebhook, EventID: "EVT-1"}
+	if err := repo.UpdateStatus(context.Background(), scan); err != nil {
+		t.Fatalf("failed to record scan: %v", err)
+	}
+	duplicate := &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusInTransit, Source: domain.StatusSourceWebhook, EventID: "EVT-1"}
+	if err := repo.UpdateStatus(context.Background(), duplicate); !errors.Is(err, domain.ErrDuplicateStatusChange) {
+		t.Errorf("expected ErrDuplicateStatusChange, got %v", err)
+	}
+
+	found, err := repo.FindByID(context.Background(), record.ID)
+	if err != nil {
+		t.Fatalf("failed to find record: %v", err)
+	}
+	if found.Status != domain.StatusInTransit {
+		t.Errorf("expected status in_transit, got %s", found.Status)
+	}
+
+	history, err := repo.FindStatusHistory(context.Background(), record.ID)
+	if err != nil {
+		t.Fatalf("failed to find status history: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 history entries, got %d", len(history))
+	}
+	if history[0].ToStatus != domain.StatusCreated || history[1].Source != domain.StatusSourcePoller || history[2].EventID != "EVT-1" {
+		t.Errorf("unexpected history: %+v, %+v", history[0], history[1])
+	}
+}
+
+func TestPostgresRepository_ClaimShipmentsForPolling(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		record := &domain.ShipmentRecord{
+			ID:                 uuid.New().String(),
+			Provider:           "A",
+			GenericPayload:     []byte(`{}`),
+			TransformedPayload: []byte(`{}`),
+			ProviderResponse:   []byte(fmt.Sprintf(`{"trackingId": "POLL-%d"}`, i)),
+			Success:            true,
+			CreatedAt:          time.Now().Add(-time.Hour),
+		}
+		if err := repo.Save(context.Background(), record); err != nil {
+			t.Fatalf("failed to save record: %v", err)
+		}
+	}
+
+	awbOnly := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "A",
+		GenericPayload:     []byte(`{}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{"awb": "POLL-AWB"}`),
+		Success:            true,
+		CreatedAt:          time.Now().Add(-time.Hour),
+	}
+	if err := repo.Save(context.Background(), awbOnly); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+
+	policy := domain.TrackingPolicy{BatchSize: 2, TrackBy: domain.TrackByTrackingID}
+	first, releaseFirst, err := repo.ClaimShipmentsForPolling(context.Background(), "A", policy, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim shipments: %v", err)
+	}
+	second, releaseSecond, err := repo.ClaimShipmentsForPolling(context.Background(), "A", policy, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim shipments: %v", err)
+	}
+	if len(first) != 2 || len(second) != 1 {
+		t.Fatalf("expected claims of 2 then 1, got %d and %d", len(first), len(second))
+	}
+	if second[0].ID == first[0].ID || second[0].ID == first[1].ID {
+		t.Error("expected a leased shipment not to be claimed twice")
+	}
+	if err := releaseSecond(); err != nil {
+		t.Fatalf("failed to release claim: %v", err)
+	}
+
+	// The lease of a shipment still being polled runs out.
+	if err := repo.SchedulePoll(context.Background(), first[0].ID, time.Now().Add(-time.Second), 2); err != nil {
+		t.Fatalf("failed to schedule poll: %v", err)
+	}
+	locked, releaseLocked, err := repo.ClaimShipmentsForPolling(context.Background(), "A", policy, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim shipments: %v", err)
+	}
+	if len(locked) != 0 {
+		t.Errorf("expected a shipment locked by another poll not to be claimed, got %d records", len(locked))
+	}
+	releaseLocked()
+
+	if err := releaseFirst(); err != nil {
+		t.Fatalf("failed to release claim: %v", err)
+	}
+	again, releaseAgain, err := repo.ClaimShipmentsForPolling(context.Background(), "A", policy, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim shipments: %v", err)
+	}
+	defer releaseAgain()
+	if len(again) != 1 || again[0].UnchangedPolls != 2 {
+		t.Errorf("expected rescheduled shipment to be claimable with its backoff count, got %d records", len(again))
+	}
+}
+
+func TestPostgresRepository_WebhookDeliveries(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	subscription := &domain.WebhookSubscription{
+		ID:         uuid.New().String(),
+		URL:        "https://orders.test/hooks",
+		EventTypes: []string{domain.EventShipmentCreated},
+		Secret:     "secret",
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}
+	if err := repo.CreateSubscription(context.Background(), subscription); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+
+	delivery := &domain.WebhookDelivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscription.ID,
+		EventID:        uuid.New().String(),
+		EventType:      domain.EventShipmentCreated,
+		Payload:        []byte(`{"type": "shipment.created"}`),
+		Status:         domain.DeliveryPending,
+		NextAttemptAt:  time.Now().Add(-time.Second),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := repo.CreateDelivery(context.Background(), delivery); err != nil {
+		t.Fatalf("failed to create delivery: %v", err)
+	}
+	duplicate := *delivery
+	duplicate.ID = uuid.New().String()
+	if err := repo.CreateDelivery(context.Background(), &duplicate); err != nil {
+		t.Fatalf("expected duplicate delivery to be ignored, got %v", err)
+	}
+
+	claimed, err := repo.ClaimDueDeliveries(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim deliveries: %v", err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("expected one claimed delivery, got %d", len(claimed))
+	}
+	if again, _ := repo.ClaimDueDeliveries(context.Background(), 10, time.Minute); len(again) != 0 {
+		t.Errorf("expected leased delivery not to be claimed twice, got %d", len(again))
+	}
+
+	claimed[0].Attempts = 1
+	claimed[0].Status = domain.DeliveryDead
+	claimed[0].LastStatusCode = 500
+	attempt := &domain.WebhookDeliveryAttempt{
+		ID:            uuid.New().String(),
+		DeliveryID:    delivery.ID,
+		AttemptNumber: 1,
+		StatusCode:    500,
+		Error:         "subscriber responded with status 500",
+		CreatedAt:     time.Now(),
+	}
+	if err := repo.RecordDeliveryAttempt(context.Background(), claimed[0], attempt); err != nil {
+		t.Fatalf("failed to record attempt: %v", err)
+	}
+
+	dead, err := repo.FindDeliveries(context.Background(), domain.DeliveryFilter{Status: domain.DeliveryDead})
+	if err != nil {
+		t.Fatalf("failed to find deliveries: %v", err)
+	}
+	if len(dead) != 1 || dead[0].LastStatusCode != 500 {
+		t.Fatalf("expected one dead delivery, got %+v", dead)
+	}
+	attempts, err := repo.FindDeliveryAttempts(context.Background(), delivery.ID)
+	if err != nil || len(attempts) != 1 {
+		t.Fatalf("expected one logged attempt, got %d (%v)", len(attempts), err)
+	}
+
+	if err := repo.ReplayDelivery(context.Background(), delivery.ID); err != nil {
+		t.Fatalf("failed to replay delivery: %v", err)
+	}
+	if err := repo.ReplayDelivery(context.Background(), delivery.ID); !errors.Is(err, domain.ErrDeliveryNotDead) {
+		t.Errorf("expected ErrDeliveryNotDead on second replay, got %v", err)
+	}
+	replayed, _ := repo.ClaimDueDeliveries(context.Background(), 10, time.Minute)
+	if len(replayed) != 1 || replayed[0].Attempts != 0 {
+		t.Errorf("expected replayed delivery to be due with a reset attempt count")
+	}
+}
+
+func TestPostgresRepository_OutboxOrdering(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	record := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "A",
+		GenericPayload:     []byte(`{}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{"trackingId": "OUTBOX-1"}`),
+		Success:            true,
+		CreatedAt:          time.Now().Add(-time.Minute),
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+	change := &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusPickedUp, Source: domain.StatusSourceAPI}
+	if err := repo.UpdateStatus(context.Background(), change); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	attempt := &domain.ShipmentAttempt{ID: uuid.New().String(), RequestID: "req-1", Provider: "B", ErrorMessage: "rejected", CreatedAt: time.Now()}
+	if err := repo.SaveAttempt(context.Background(), attempt); err != nil {
+		t.Fatalf("failed to save attempt: %v", err)
+	}
+
+	first, err := repo.ClaimOutboxEvents(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim outbox events: %v", err)
+	}
+	if len(first) != 2 || first[0].Event.Type != domain.EventShipmentCreated || first[1].Event.Type != domain.EventShipmentFailed {
+		t.Fatalf("expected shipment.created and shipment.failed, got %d events", len(first))
+	}
+	if again, _ := repo.ClaimOutboxEvents(context.Background(), 10, time.Minute); len(again) != 0 {
+		t.Fatalf("expected status.changed to wait behind the unpublished created event, got %d", len(again))
+	}
+
+	if err := repo.MarkOutboxPublished(context.Background(), first[0].Sequence, time.Now()); err != nil {
+		t.Fatalf("failed to mark published: %v", err)
+	}
+	next, err := repo.ClaimOutboxEvents(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim outbox events: %v", err)
+	}
+	if len(next) != 1 || next[0].Event.Type != domain.EventStatusChanged || next[0].Event.ShipmentID != record.ID {
+		t.Errorf("expected status.changed once created was published, got %d events", len(next))
+	}
+}
+
+func TestPostgresRepository_Jobs(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	job := &domain.Job{
+		ID:          uuid.New().String(),
+		Status:      domain.JobQueued,
+		Provider:    "A",
+		RequestID:   uuid.New().String(),
+		Request:     []byte(`{"senderName": "Test"}`),
+		MaxAttempts: 3,
+		RunAt:       now.Add(-time.Second),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repo.CreateJob(context.Background(), job); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	claimed, err := repo.ClaimJobs(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to claim jobs: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Status != domain.JobRunning || claimed[0].Attempts != 1 || claimed[0].Results != nil {
+		t.Fatalf("expected one running job on its first attempt, got %+v", claimed)
+	}
+	if again, _ := repo.ClaimJobs(context.Background(), 10, time.Minute); len(again) != 0 {
+		t.Fatalf("expected leased job not to be claimed twice, got %d", len(again))
+	}
+
+	completedAt := time.Now()
+	claimed[0].Status = domain.JobCompleted
+	claimed[0].Results = []byte(`[{"provider": "A", "success": true}]`)
+	claimed[0].CompletedAt = &completedAt
+	claimed[0].UpdatedAt = completedAt
+	if err := repo.UpdateJob(context.Background(), claimed[0]); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+
+	found, err := repo.FindJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("failed to find job: %v", err)
+	}
+	if found.Status != domain.JobCompleted || found.CompletedAt == nil || len(found.Results) == 0 {
+		t.Errorf("expected completed job with results, got %+v", found)
+	}
+
+	completed, err := repo.ListJobs(context.Background(), domain.JobFilter{Status: domain.JobCompleted})
+	if err != nil || len(completed) != 1 {
+		t.Errorf("expected one completed job in the listing, got %d (%v)", len(completed), err)
+	}
+	if _, err := repo.FindJob(context.Background(), "missing"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestPostgresRepository_FailExpiredJobs(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	job := &domain.Job{
+		ID:          uuid.New().String(),
+		Status:      domain.JobQueued,
+		Provider:    "A",
+		RequestID:   uuid.New().String(),
+		Request:     []byte(`{"senderName": "Test"}`),
+		MaxAttempts: 3,
+		RunAt:       now.Add(-time.Second),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repo.CreateJob(context.Background(), job); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	if claimed, _ := repo.ClaimJobs(context.Background(), 10, -time.Second); len(claimed) != 1 {
+		t.Fatalf("expected the job to be claimed, got %d", len(claimed))
+	}
+
+	if again, _ := repo.ClaimJobs(context.Background(), 10, time.Minute); len(again) != 0 {
+		t.Fatalf("expected an expired running job not to be claimed again, got %d", len(again))
+	}
+	failed, err := repo.FailExpiredJobs(context.Background(), "interrupted")
+	if err != nil || failed != 1 {
+		t.Fatalf("expected one expired job failed, got %d (%v)", failed, err)
+	}
+
+	found, _ := repo.FindJob(context.Background(), job.ID)
+	if found.Status != domain.JobFailed || found.Error != "interrupted" || found.CompletedAt == nil {
+		t.Errorf("expected the expired job to fail, got %+v", found)
+	}
+}
+
+func TestPostgresRepository_APIKeys(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	key := &domain.APIKey{
+		ID:        uuid.New().String(),
+		Name:      "checkout",
+		Prefix:    "sk_0123456",
+		Hash:      domain.HashAPIKey("sk_0123456789"),
+		Scopes:    []string{domain.ScopeShipmentsCreate, domain.ScopeShipmentsRead},
+		CreatedAt: now,
+	}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	found, err := repo.FindAPIKeyByHash(ctx, key.Hash)
+	if err != nil {
+		t.Fatalf("failed to find api key: %v", err)
+	}
+	if found.ID != key.ID || len(found.Scopes) != 2 || found.ExpiresAt != nil {
+		t.Fatalf("unexpected api key %+v", found)
+	}
+
+	if err := repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+		t.Fatalf("failed to touch api key: %v", err)
+	}
+
+	replacement := &domain.APIKey{
+		ID:        uuid.New().String(),
+		Name:      key.Name,
+		Prefix:    "sk_abcdefg",
+		Hash:      domain.HashAPIKey("sk_abcdefghij"),
+		Scopes:    key.Scopes,
+		CreatedAt: now,
+	}
+	if err := repo.RotateAPIKey(ctx, key.ID, replacement, now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to rotate api key: %v", err)
+	}
+
+	old, _ := repo.FindAPIKey(ctx, key.ID)
+	if old.ReplacedBy != replacement.ID || old.ExpiresAt == nil || old.LastUsedAt == nil {
+		t.Errorf("expected rotated key with expiry and last use, got %+v", old)
+	}
+
+	if err := repo.RevokeAPIKey(ctx, key.ID, now); err != nil {
+		t.Fatalf("failed to revoke api key: %v", err)
+	}
+	if err := repo.RotateAPIKey(ctx, key.ID, &domain.APIKey{ID: uuid.New().String()}, now); !errors.Is(err, domain.ErrAPIKeyInactive) {
+		t.Errorf("expected ErrAPIKeyInactive, got %v", err)
+	}
+	if err := repo.RevokeAPIKey(ctx, "missing", now); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	keys, err := repo.ListAPIKeys(ctx)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("expected two api keys, got %d (%v)", len(keys), err)
+	}
+}
+
+func TestPostgresRepository_TenantScoping(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	acme := domain.WithTenant(context.Background(), &domain.Tenant{ID: "acme"})
+	other := domain.WithTenant(context.Background(), &domain.Tenant{ID: "other"})
+
+	record := &domain.ShipmentRecord{
+		ID:                 uuid.New().String(),
+		Provider:           "TestProvider",
+		GenericPayload:     []byte(`{}`),
+		TransformedPayload: []byte(`{}`),
+		ProviderResponse:   []byte(`{}`),
+		Success:            true,
+		TrackingID:         "TENANT-TRACK",
+		CreatedAt:          time.Now(),
+	}
+	if err := repo.Save(acme, record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+
+	found, err := repo.FindByID(acme, record.ID)
+	if err != nil || found.TenantID != "acme" {
+		t.Fatalf("expected acme to find its record, got %+v (%v)", found, err)
+	}
+	if _, err := repo.FindByID(other, record.ID); err != domain.ErrShipmentNotFound {
+		t.Errorf("expected another tenant to get ErrShipmentNotFound, got %v", err)
+	}
+	if records, _ := repo.FindByTrackingID(other, "TENANT-TRACK"); len(records) != 0 {
+		t.Errorf("expected no records for another tenant, got %d", len(records))
+	}
+	if page, _ := repo.Search(other, domain.ShipmentFilter{}); len(page.Records) != 0 {
+		t.Errorf("expected an empty search for another tenant, got %d", len(page.Records))
+	}
+	if history, _ := repo.FindStatusHistory(acme, record.ID); len(history) == 0 {
+		t.Error("expected acme to see its status history")
+	}
+	if history, _ := repo.FindStatusHistory(other, record.ID); len(history) != 0 {
+		t.Errorf("expected no status history for another tenant, got %d", len(history))
+	}
+	if _, err := repo.FindByID(context.Background(), record.ID); err != nil {
+		t.Errorf("expected an unscoped context to see every tenant, got %v", err)
+	}
+}
+
+func TestPostgresRepository_Usage(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	day := domain.UsageDay(time.Now())
+	for i := 1; i <= 3; i++ {
+		counter := &domain.UsageCounter{TenantID: "acme", Metric: domain.UsageShipments, WindowStart: day, ExpiresAt: day.Add(time.Hour)}
+		allowed, err := repo.IncrementUsage(ctx, counter, 1, 2)
+		if err != nil {
+			t.Fatalf("failed to increment usage: %v", err)
+		}
+		if allowed != (i <= 2) || counter.Count != min(i, 2) {
+			t.Errorf("increment %d: expected allowed %v at count %d, got %v at %d", i, i <= 2, min(i, 2), allowed, counter.Count)
+		}
+	}
+
+	counters, err := repo.ListUsage(ctx, domain.UsageShipments, day)
+	if err != nil || len(counters) != 1 || counters[0].Count != 2 {
+		t.Fatalf("expected one counter at 2, got %+v (%v)", counters, err)
+	}
+
+	batch := &domain.UsageCounter{TenantID: "globex", Metric: domain.UsageShipments, WindowStart: day, ExpiresAt: day.Add(time.Hour)}
+	if allowed, err := repo.IncrementUsage(ctx, batch, 3, 4); err != nil || !allowed || batch.Count != 3 {
+		t.Errorf("expected 3 shipments reserved, got %v at %d (%v)", allowed, batch.Count, err)
+	}
+	if allowed, err := repo.IncrementUsage(ctx, batch, 2, 4); err != nil || allowed || batch.Count != 3 {
+		t.Errorf("expected 2 more shipments refused at 3 of 4, got %v at %d (%v)", allowed, batch.Count, err)
+	}
+	oversized := &domain.UsageCounter{TenantID: "initech", Metric: domain.UsageShipments, WindowStart: day, ExpiresAt: day.Add(time.Hour)}
+	if allowed, err := repo.IncrementUsage(ctx, oversized, 5, 4); err != nil || allowed || oversized.Count != 0 {
+		t.Errorf("expected a reservation over the limit refused, got %v at %d (%v)", allowed, oversized.Count, err)
+	}
+
+	deleted, err := repo.DeleteExpiredUsage(ctx, day.Add(2*time.Hour))
+	if err != nil || deleted != 2 {
+		t.Errorf("expected the expired counter deleted, got %d (%v)", deleted, err)
+	}
+}
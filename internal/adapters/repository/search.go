@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+func (r *PostgresRepository) Search(ctx context.Context, filter domain.ShipmentFilter) (*domain.ShipmentPage, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if tenantID := tenantScope(ctx); tenantID != "" {
+		addCondition("tenant_id = $%d", tenantID)
+	}
+	if filter.Provider != "" {
+		addCondition("provider = $%d", filter.Provider)
+	}
+	if filter.Success != nil {
+		addCondition("success = $%d", *filter.Success)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("created_at < $%d", filter.CreatedTo)
+	}
+	if filter.TrackingID != "" {
+		addCondition("tracking_id = $%d", filter.TrackingID)
+	}
+	if filter.AWB != "" {
+		addCondition("awb = $%d", filter.AWB)
+	}
+	if filter.ReferenceNumber != "" {
+		addCondition("reference_numbers @> ARRAY[$%d]::text[]", filter.ReferenceNumber)
+	}
+	if filter.ConsigneeEmail != "" {
+		addCondition("consignee_email_hash = $%d", domain.HashEmail(filter.ConsigneeEmail))
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.Destination != "" {
+		addCondition("destination_country = $%d", strings.ToUpper(filter.Destination))
+	}
+
+	order := "DESC"
+	comparison := "<"
+	if filter.SortOrder == domain.SortAscending {
+		order = "ASC"
+		comparison = ">"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := domain.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) %s ($%d, $%d)", comparison, len(args)-1, len(args)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	args = append(args, limit+1)
+
+	query := `SELECT ` + recordColumns + ` FROM shipment_records`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT $%d", order, order, len(args))
+
+	records, err := r.queryRecords(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search shipment records: %w", err)
+	}
+
+	page := &domain.ShipmentPage{Records: records}
+
+	if len(page.Records) > limit {
+		page.Records = page.Records[:limit]
+		last := page.Records[limit-1]
+		page.NextCursor = domain.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
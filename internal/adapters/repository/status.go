@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// UpdateStatus validates and applies a status change under a row lock, then
+// appends it to the shipment's timeline and the event outbox. FromStatus is
+// set from the stored state. A change whose EventID is already in the
+// timeline is rejected with domain.ErrDuplicateStatusChange.
+func (r *PostgresRepository) UpdateStatus(ctx context.Context, change *domain.StatusChange) error {
+	defaultStatusChange(change)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin status update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current domain.ShipmentStatus
+	var tenantID string
+	err = tx.QueryRowContext(ctx,
+		`SELECT status, tenant_id FROM shipment_records WHERE id = $1 AND ($2 = '' OR tenant_id = $2) FOR UPDATE`,
+		change.ShipmentID, tenantScope(ctx),
+	).Scan(&current, &tenantID)
+	if err == sql.ErrNoRows {
+		return domain.ErrShipmentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load shipment status: %w", err)
+	}
+
+	if change.EventID != "" {
+		var exists bool
+		err = tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM shipment_status_history WHERE shipment_id = $1 AND event_id = $2)`,
+			change.ShipmentID, change.EventID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check status history: %w", err)
+		}
+		if exists {
+			return domain.ErrDuplicateStatusChange
+		}
+	}
+
+	if err := domain.ValidateTransition(current, change.ToStatus); err != nil {
+		return err
+	}
+	change.FromStatus = current
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE shipment_records SET status = $2, status_updated_at = $3 WHERE id = $1`,
+		change.ShipmentID, change.ToStatus, change.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update shipment status: %w", err)
+	}
+
+	if err := insertStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	events, err := change.Events()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		event.TenantID = tenantID
+	}
+	if err := insertOutboxEvents(ctx, tx, events...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit status update: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) FindStatusHistory(ctx context.Context, shipmentID string) ([]*domain.StatusChange, error) {
+	query := `
+		SELECT h.id, h.shipment_id, h.from_status, h.to_status, h.source,
+			h.description, h.location, h.event_id, h.carrier_status, h.occurred_at, h.created_at
+		FROM shipment_status_history h
+		JOIN shipment_records s ON s.id = h.shipment_id
+		WHERE h.shipment_id = $1 AND ($2 = '' OR s.tenant_id = $2)
+		ORDER BY h.occurred_at, h.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, shipmentID, tenantScope(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*domain.StatusChange
+	for rows.Next() {
+		change := &domain.StatusChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.ShipmentID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Source,
+			&change.Description,
+			&change.Location,
+			&change.EventID,
+			&change.CarrierStatus,
+			&change.OccurredAt,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status history: %w", err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query status history: %w", err)
+	}
+
+	return history, nil
+}
+
+func insertStatusChange(ctx context.Context, db execer, change *domain.StatusChange) error {
+	defaultStatusChange(change)
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO shipment_status_history (
+			id, shipment_id, from_status, to_status, source,
+			description, location, event_id, carrier_status, occurred_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		change.ID,
+		change.ShipmentID,
+		change.FromStatus,
+		change.ToStatus,
+		change.Source,
+		change.Description,
+		change.Location,
+		change.EventID,
+		change.CarrierStatus,
+		change.OccurredAt,
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save status change: %w", err)
+	}
+
+	return nil
+}
+
+func defaultStatusChange(change *domain.StatusChange) {
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	if change.OccurredAt.IsZero() {
+		change.OccurredAt = change.CreatedAt
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"time"
+)
+
+// IncrementUsage upserts the counter, skipping the update when it would pass
+// limit, so concurrent replicas never admit more than limit in a window.
+func (r *PostgresRepository) IncrementUsage(ctx context.Context, counter *domain.UsageCounter, amount, limit int) (bool, error) {
+	if amount > limit {
+		return false, r.currentUsage(ctx, counter)
+	}
+
+	query := `
+		INSERT INTO usage_counters (tenant_id, metric, window_start, count, expires_at)
+		VALUES ($1, $2, $3, $6, $4)
+		ON CONFLICT (tenant_id, metric, window_start)
+		DO UPDATE SET count = usage_counters.count + $6
+		WHERE usage_counters.count + $6 <= $5
+		RETURNING count
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		counter.TenantID,
+		counter.Metric,
+		counter.WindowStart,
+		counter.ExpiresAt,
+		limit,
+		amount,
+	).Scan(&counter.Count)
+	if err == sql.ErrNoRows {
+		return false, r.currentUsage(ctx, counter)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to increment usage: %w", err)
+	}
+	return true, nil
+}
+
+// currentUsage sets counter.Count to the stored count, zero when the counter
+// does not exist yet.
+func (r *PostgresRepository) currentUsage(ctx context.Context, counter *domain.UsageCounter) error {
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count FROM usage_counters
+		WHERE tenant_id = $1 AND metric = $2 AND window_start = $3
+	`, counter.TenantID, counter.Metric, counter.WindowStart).Scan(&counter.Count)
+	if err == sql.ErrNoRows {
+		counter.Count = 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read usage: %w", err)
+	}
+	return nil
+}
+
+// ListUsage returns every tenant's counter for the window; usage reports are
+// filtered by the service.
+func (r *PostgresRepository) ListUsage(ctx context.Context, metric string, windowStart time.Time) ([]*domain.UsageCounter, error) {
+	query := `
+		SELECT tenant_id, metric, window_start, count, expires_at
+		FROM usage_counters
+		WHERE metric = $1 AND window_start = $2
+		ORDER BY tenant_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, metric, windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	var counters []*domain.UsageCounter
+	for rows.Next() {
+		var counter domain.UsageCounter
+		if err := rows.Scan(&counter.TenantID, &counter.Metric, &counter.WindowStart, &counter.Count, &counter.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		counters = append(counters, &counter)
+	}
+	return counters, rows.Err()
+}
+
+func (r *PostgresRepository) DeleteExpiredUsage(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM usage_counters WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired usage: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const subscriptionColumns = `id, tenant_id, url, event_types, secret, active, created_at`
+
+// deliveryTenantCondition limits deliveries to those of the tenant's
+// subscriptions; the placeholder is filled with tenantScope.
+const deliveryTenantCondition = `($%[1]d = '' OR subscription_id IN (SELECT id FROM webhook_subscriptions WHERE tenant_id = $%[1]d))`
+
+const deliveryColumns = `
+	id, subscription_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, created_at, updated_at
+`
+
+func (r *PostgresRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	if subscription.TenantID == "" {
+		subscription.TenantID = domain.TenantIDFromContext(ctx)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (`+subscriptionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		subscription.ID,
+		subscription.TenantID,
+		subscription.URL,
+		pq.Array(subscription.EventTypes),
+		subscription.Secret,
+		subscription.Active,
+		subscription.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`,
+		id, tenantScope(ctx),
+	)
+	subscription, err := scanSubscription(row)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *PostgresRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE ($1 = '' OR tenant_id = $1) ORDER BY created_at`,
+		tenantScope(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresRepository) DeactivateSubscription(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions SET active = false WHERE id = $1 AND ($2 = '' OR tenant_id = $2)`,
+		id, tenantScope(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate webhook subscription: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`,
+		delivery.ID,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries leases pending deliveries whose next attempt is due.
+// SKIP LOCKED keeps concurrent dispatchers from claiming the same rows.
+func (r *PostgresRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	now := time.Now()
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	deliveries, err := r.queryDeliveries(ctx, query, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt stores the delivery's updated state together with
+// the attempt that produced it.
+func (r *PostgresRepository) RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin delivery update: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4,
+			last_status_code = $5, last_error = $6, updated_at = $7
+		WHERE id = $1
+	`,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (
+			id, delivery_id, attempt_number, status_code, error, duration_ms, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		attempt.ID,
+		attempt.DeliveryID,
+		attempt.AttemptNumber,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.DurationMs,
+		attempt.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery attempt: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	deliveries, err := r.queryDeliveries(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1 AND `+fmt.Sprintf(deliveryTenantCondition, 2),
+		id, tenantScope(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	return deliveries[0], nil
+}
+
+func (r *PostgresRepository) FindDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	args := []interface{}{tenantScope(ctx)}
+	conditions := []string{fmt.Sprintf(deliveryTenantCondition, 1)}
+	if filter.SubscriptionID != "" {
+		args = append(args, filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("subscription_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+	args = append(args, limit)
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE ` + strings.Join(conditions, " AND ")
+	query += fmt.Sprintf(" ORDER BY updated_at DESC LIMIT $%d", len(args))
+
+	deliveries, err := r.queryDeliveries(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *PostgresRepository) FindDeliveryAttempts(ctx context.Context, deliveryID string) ([]*domain.WebhookDeliveryAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, delivery_id, attempt_number, status_code, error, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt_number
+	`, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*domain.WebhookDeliveryAttempt
+	for rows.Next() {
+		attempt := &domain.WebhookDeliveryAttempt{}
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.DeliveryID,
+			&attempt.AttemptNumber,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ReplayDelivery returns a dead-lettered delivery to the queue with a fresh
+// attempt budget. Its attempt history is kept.
+func (r *PostgresRepository) ReplayDelivery(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'dead' AND `+fmt.Sprintf(deliveryTenantCondition, 2),
+		id, tenantScope(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil
+	}
+
+	if _, err := r.FindDelivery(ctx, id); err != nil {
+		return err
+	}
+	return domain.ErrDeliveryNotDead
+}
+
+func (r *PostgresRepository) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery := &domain.WebhookDelivery{}
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	subscription := &domain.WebhookSubscription{}
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.TenantID,
+		&subscription.URL,
+		pq.Array(&subscription.EventTypes),
+		&subscription.Secret,
+		&subscription.Active,
+		&subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
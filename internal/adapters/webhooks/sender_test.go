@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"testing"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	var gotSignature, gotTimestamp, gotEvent string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotTimestamp = r.Header.Get(TimestampHeader)
+		gotEvent = r.Header.Get(EventTypeHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription := &domain.WebhookSubscription{URL: server.URL, Secret: "secret"}
+	delivery := &domain.WebhookDelivery{EventID: "E1", EventType: domain.EventShipmentCreated, Payload: []byte(`{"id":"E1"}`)}
+
+	status, err := NewHTTPSender().Send(context.Background(), subscription, delivery)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", status)
+	}
+	if gotEvent != domain.EventShipmentCreated || string(gotBody) != `{"id":"E1"}` {
+		t.Errorf("unexpected request: event %q body %s", gotEvent, gotBody)
+	}
+	if gotSignature != Sign("secret", gotTimestamp, gotBody) {
+		t.Errorf("signature does not verify")
+	}
+}
+
+func TestHTTPSender_Send_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	subscription := &domain.WebhookSubscription{URL: server.URL, Secret: "secret"}
+	status, err := NewHTTPSender().Send(context.Background(), subscription, &domain.WebhookDelivery{Payload: []byte(`{}`)})
+	if err == nil || status != http.StatusBadGateway {
+		t.Errorf("expected error with status 502, got %d (%v)", status, err)
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const (
+	ScopeShipmentsCreate = "shipments:create"
+	ScopeShipmentsRead   = "shipments:read"
+	ScopeAdmin           = "admin"
+)
+
+var APIKeyScopes = []string{ScopeShipmentsCreate, ScopeShipmentsRead, ScopeAdmin}
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+var ErrAPIKeyInactive = errors.New("api key is revoked or expired")
+
+var ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+
+// APIKey is a client credential. Only the SHA-256 hash of the secret is
+// stored; Prefix is kept so a key can be recognised in listings.
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`
+	TenantID   string     `json:"tenantId" db:"tenant_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Hash       string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	ReplacedBy string     `json:"replacedBy,omitempty" db:"replaced_by"`
+}
+
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active reports whether the key may authenticate at the given time. A
+// rotated key stays active until the end of its overlap window.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key grants scope; admin grants every scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidScope(scope string) bool {
+	for _, known := range APIKeyScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
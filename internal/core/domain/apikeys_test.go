@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPIKey_Active(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{"no expiry", APIKey{}, true},
+		{"expires later", APIKey{ExpiresAt: &future}, true},
+		{"expired", APIKey{ExpiresAt: &past}, false},
+		{"revoked", APIKey{RevokedAt: &past, ExpiresAt: &future}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.key.Active(now); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAPIKey_HasScope(t *testing.T) {
+	reader := APIKey{Scopes: []string{ScopeShipmentsRead}}
+	if !reader.HasScope(ScopeShipmentsRead) || reader.HasScope(ScopeShipmentsCreate) {
+		t.Errorf("expected read-only key to grant only %s", ScopeShipmentsRead)
+	}
+
+	admin := APIKey{Scopes: []string{ScopeAdmin}}
+	for _, scope := range APIKeyScopes {
+		if !admin.HasScope(scope) {
+			t.Errorf("expected admin key to grant %s", scope)
+		}
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := HashAPIKey("sk_secret")
+	if len(hash) != 64 || hash != HashAPIKey("sk_secret") || hash == HashAPIKey("sk_other") {
+		t.Errorf("unexpected hash %q", hash)
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ErrorCategoryTimeout    = "timeout"
+	ErrorCategoryNetwork    = "network"
+	ErrorCategoryProvider   = "provider_error"
+	ErrorCategoryValidation = "validation"
+	ErrorCategoryInternal   = "internal"
+)
+
+type ProviderExchange struct {
+	RequestBody  []byte
+	StatusCode   int
+	Headers      map[string][]string
+	ResponseBody []byte
+	Duration     time.Duration
+}
+
+type ProviderError struct {
+	Provider string
+	Category string
+	Exchange *ProviderExchange
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider %s %s: %v", e.Provider, e.Category, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+type ShipmentAttempt struct {
+	ID              string    `json:"id" db:"id"`
+	TenantID        string    `json:"-" db:"tenant_id"`
+	RequestID       string    `json:"requestId" db:"request_id"`
+	ShipmentID      string    `json:"shipmentId,omitempty" db:"shipment_id"`
+	Provider        string    `json:"provider" db:"provider"`
+	AttemptNumber   int       `json:"attemptNumber" db:"attempt_number"`
+	RequestBody     []byte    `json:"requestBody,omitempty" db:"request_body"`
+	ResponseStatus  int       `json:"responseStatus,omitempty" db:"response_status"`
+	ResponseHeaders []byte    `json:"responseHeaders,omitempty" db:"response_headers"`
+	ResponseBody    []byte    `json:"responseBody,omitempty" db:"response_body"`
+	DurationMs      int64     `json:"durationMs" db:"duration_ms"`
+	ErrorCategory   string    `json:"errorCategory,omitempty" db:"error_category"`
+	ErrorMessage    string    `json:"errorMessage,omitempty" db:"error_message"`
+	Success         bool      `json:"success" db:"success"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BatchItem is one decoded entry of a batch upload. Err holds a decode
+// error for the entry, in which case Request is nil.
+type BatchItem struct {
+	Index   int
+	Request *GenericShippingRequest
+	Err     error
+}
+
+// BatchItemResult reports one item's outcome with one provider. Broadcast
+// items produce one result per provider.
+type BatchItemResult struct {
+	Index      int      `json:"index"`
+	Provider   string   `json:"provider,omitempty"`
+	Success    bool     `json:"success"`
+	TrackingID string   `json:"trackingId,omitempty"`
+	AWB        string   `json:"awb,omitempty"`
+	RequestID  string   `json:"requestId,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+func (s *BatchSummary) Add(result *BatchItemResult) {
+	s.Total++
+	if result.Success {
+		s.Succeeded++
+	} else {
+		s.Failed++
+	}
+}
+
+// CSVProfile maps CSV columns onto GenericShippingRequest fields. Fields
+// are addressed by their dotted JSON path, such as consignee.address.city.
+// Defaults are applied to every row before its columns. Columns not in the
+// profile are ignored; without a profile every column must be a field path.
+// List fields take values separated by semicolons.
+type CSVProfile struct {
+	Columns  map[string]string `json:"columns"`
+	Defaults map[string]string `json:"defaults"`
+}
+
+func (p *CSVProfile) Validate() error {
+	probe := &GenericShippingRequest{}
+	for column, path := range p.Columns {
+		if err := setRequestField(probe, path, ""); err != nil {
+			return fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+	for path, value := range p.Defaults {
+		if err := setRequestField(probe, path, value); err != nil {
+			return fmt.Errorf("default %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Request builds a shipping request from one CSV row.
+func (p *CSVProfile) Request(header, row []string) (*GenericShippingRequest, error) {
+	request := &GenericShippingRequest{}
+	if p != nil {
+		for path, value := range p.Defaults {
+			if err := setRequestField(request, path, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var errs []string
+	for i, column := range header {
+		path := column
+		if p != nil {
+			var mapped bool
+			if path, mapped = p.Columns[column]; !mapped {
+				continue
+			}
+		}
+		if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+			continue
+		}
+		if err := setRequestField(request, path, strings.TrimSpace(row[i])); err != nil {
+			errs = append(errs, fmt.Sprintf("column %q: %v", column, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return request, nil
+}
+
+func setRequestField(request *GenericShippingRequest, path, value string) error {
+	field := reflect.ValueOf(request).Elem()
+	for _, name := range strings.Split(path, ".") {
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown field %q", path)
+		}
+		next, ok := fieldByJSONName(field, name)
+		if !ok {
+			return fmt.Errorf("unknown field %q", path)
+		}
+		field = next
+	}
+
+	if value == "" {
+		switch field.Kind() {
+		case reflect.String, reflect.Float64, reflect.Int, reflect.Bool:
+			return nil
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.String {
+				return nil
+			}
+		}
+		return fmt.Errorf("field %q cannot be set from CSV", path)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q for %s", value, path)
+		}
+		field.SetFloat(number)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q for %s", value, path)
+		}
+		field.SetInt(int64(number))
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q for %s", value, path)
+		}
+		field.SetBool(flag)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("field %q cannot be set from CSV", path)
+		}
+		var values []string
+		for _, part := range strings.Split(value, ";") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("field %q cannot be set from CSV", path)
+	}
+	return nil
+}
+
+func fieldByJSONName(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		tag := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVProfile_Request(t *testing.T) {
+	profile := &CSVProfile{
+		Columns: map[string]string{
+			"Order":   "referenceNumbers",
+			"Name":    "consignee.contact.name",
+			"City":    "consignee.address.city",
+			"Country": "consignee.address.countryCode",
+			"Grams":   "weight.value",
+			"Pieces":  "numberOfPieces",
+			"COD":     "isCod",
+		},
+		Defaults: map[string]string{"weight.unit": "Grams", "serviceType": "Express"},
+	}
+	header := []string{"Order", "Name", "City", "Country", "Grams", "Pieces", "COD", "Ignored"}
+
+	request, err := profile.Request(header, []string{"SO-1; SO-2", "Jane", "Dubai", "AE", "1250.5", "2", "true", "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Consignee.Contact.Name != "Jane" || request.Consignee.Address.CountryCode != "AE" {
+		t.Errorf("expected consignee fields to be mapped, got %+v", request.Consignee)
+	}
+	if request.Weight.Value != 1250.5 || request.Weight.Unit != "Grams" || request.ServiceType != "Express" {
+		t.Errorf("expected weight and defaults to be applied, got %+v / %s", request.Weight, request.ServiceType)
+	}
+	if request.NumberOfPieces != 2 || !request.IsCOD {
+		t.Errorf("expected integer and boolean columns to be parsed")
+	}
+	if len(request.ReferenceNumbers) != 2 || request.ReferenceNumbers[1] != "SO-2" {
+		t.Errorf("expected semicolon-separated references, got %v", request.ReferenceNumbers)
+	}
+
+	_, err = profile.Request(header, []string{"SO-3", "Jane", "Dubai", "AE", "heavy", "two", "true"})
+	if err == nil || !strings.Contains(err.Error(), `"Grams"`) || !strings.Contains(err.Error(), `"Pieces"`) {
+		t.Errorf("expected errors for both invalid columns, got %v", err)
+	}
+}
+
+func TestCSVProfile_RequestWithoutProfile(t *testing.T) {
+	var profile *CSVProfile
+	request, err := profile.Request([]string{"productCode", "consignee.address.city"}, []string{"International", "Dubai"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.ProductCode != "International" || request.Consignee.Address.City != "Dubai" {
+		t.Errorf("expected headers to be used as field paths, got %+v", request)
+	}
+
+	if _, err := profile.Request([]string{"unknown"}, []string{"value"}); err == nil {
+		t.Error("expected an error for an unknown field path")
+	}
+}
+
+func TestCSVProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile CSVProfile
+		valid   bool
+	}{
+		{"valid", CSVProfile{Columns: map[string]string{"City": "consignee.address.city"}}, true},
+		{"unknown field", CSVProfile{Columns: map[string]string{"City": "consignee.town"}}, false},
+		{"struct list", CSVProfile{Columns: map[string]string{"Packages": "packages"}}, false},
+		{"invalid default", CSVProfile{Defaults: map[string]string{"weight.value": "heavy"}}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.profile.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrProviderNotFound = errors.New("provider not found")
+
+type ProviderCapabilities struct {
+	OriginCountries      []string `json:"originCountries,omitempty"`
+	DestinationCountries []string `json:"destinationCountries,omitempty"`
+	SupportsCOD          bool     `json:"supportsCod"`
+	CODCountries         []string `json:"codCountries,omitempty"`
+	CODCurrencies        []string `json:"codCurrencies,omitempty"`
+	MaxWeightKg          float64  `json:"maxWeightKg,omitempty"`
+	MaxLengthCm          float64  `json:"maxLengthCm,omitempty"`
+	MaxWidthCm           float64  `json:"maxWidthCm,omitempty"`
+	MaxHeightCm          float64  `json:"maxHeightCm,omitempty"`
+	MaxPieces            int      `json:"maxPieces,omitempty"`
+	ProductCodes         []string `json:"productCodes,omitempty"`
+	SupportsInsurance    bool     `json:"supportsInsurance"`
+}
+
+type ProviderInfo struct {
+	Name         string               `json:"name"`
+	Capabilities ProviderCapabilities `json:"capabilities"`
+}
+
+type EligibilityError struct {
+	Provider string
+	Reasons  []string
+}
+
+func (e *EligibilityError) Error() string {
+	return fmt.Sprintf("provider %s cannot handle this shipment: %s", e.Provider, strings.Join(e.Reasons, "; "))
+}
+
+// CheckEligibility returns the reasons a provider with these capabilities
+// cannot carry the request. Empty lists and zero limits mean unrestricted.
+func (c ProviderCapabilities) CheckEligibility(req *GenericShippingRequest) []string {
+	var reasons []string
+
+	origin := req.Shipper.Address.CountryCode
+	destination := req.Consignee.Address.CountryCode
+
+	if !allows(c.OriginCountries, origin) {
+		reasons = append(reasons, fmt.Sprintf("origin country %q not supported", origin))
+	}
+	if !allows(c.DestinationCountries, destination) {
+		reasons = append(reasons, fmt.Sprintf("destination country %q not supported", destination))
+	}
+
+	if req.IsCOD {
+		switch {
+		case !c.SupportsCOD:
+			reasons = append(reasons, "cash on delivery not supported")
+		case !allows(c.CODCountries, destination):
+			reasons = append(reasons, fmt.Sprintf("cash on delivery not available for destination %q", destination))
+		}
+		if c.SupportsCOD && !allows(c.CODCurrencies, req.DeclaredValue.Currency) {
+			reasons = append(reasons, fmt.Sprintf("cash on delivery currency %q not supported", req.DeclaredValue.Currency))
+		}
+	}
+
+	if c.MaxWeightKg > 0 && req.Weight.Kilograms() > c.MaxWeightKg {
+		reasons = append(reasons, fmt.Sprintf("weight %.2fkg exceeds maximum %.2fkg", req.Weight.Kilograms(), c.MaxWeightKg))
+	}
+
+	length, width, height := req.Dimensions.Centimeters()
+	if c.MaxLengthCm > 0 && length > c.MaxLengthCm {
+		reasons = append(reasons, fmt.Sprintf("length %.1fcm exceeds maximum %.1fcm", length, c.MaxLengthCm))
+	}
+	if c.MaxWidthCm > 0 && width > c.MaxWidthCm {
+		reasons = append(reasons, fmt.Sprintf("width %.1fcm exceeds maximum %.1fcm", width, c.MaxWidthCm))
+	}
+	if c.MaxHeightCm > 0 && height > c.MaxHeightCm {
+		reasons = append(reasons, fmt.Sprintf("height %.1fcm exceeds maximum %.1fcm", height, c.MaxHeightCm))
+	}
+
+	if c.MaxPieces > 0 && req.NumberOfPieces > c.MaxPieces {
+		reasons = append(reasons, fmt.Sprintf("%d pieces exceeds maximum %d", req.NumberOfPieces, c.MaxPieces))
+	}
+
+	if req.ProductCode != "" && !allows(c.ProductCodes, req.ProductCode) {
+		reasons = append(reasons, fmt.Sprintf("product code %q not supported", req.ProductCode))
+	}
+
+	if req.IsInsured && !c.SupportsInsurance {
+		reasons = append(reasons, "insurance not supported")
+	}
+
+	return reasons
+}
+
+func allows(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "testing"
+
+func TestProviderCapabilities_CheckEligibility(t *testing.T) {
+	capabilities := ProviderCapabilities{
+		DestinationCountries: []string{"AE", "IN"},
+		SupportsCOD:          true,
+		CODCountries:         []string{"AE"},
+		CODCurrencies:        []string{"AED"},
+		MaxWeightKg:          30,
+		MaxLengthCm:          120,
+		MaxPieces:            5,
+		SupportsInsurance:    false,
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(req *GenericShippingRequest)
+		eligible bool
+	}{
+		{"eligible", func(req *GenericShippingRequest) {}, true},
+		{"unsupported destination", func(req *GenericShippingRequest) { req.Consignee.Address.CountryCode = "US" }, false},
+		{"cod outside allowed countries", func(req *GenericShippingRequest) {
+			req.IsCOD = true
+			req.Consignee.Address.CountryCode = "IN"
+		}, false},
+		{"cod in allowed country", func(req *GenericShippingRequest) { req.IsCOD = true }, true},
+		{"cod wrong currency", func(req *GenericShippingRequest) {
+			req.IsCOD = true
+			req.DeclaredValue.Currency = "USD"
+		}, false},
+		{"overweight", func(req *GenericShippingRequest) { req.Weight = WeightInfo{Value: 31, Unit: "KG"} }, false},
+		{"too long", func(req *GenericShippingRequest) { req.Dimensions = Dimensions{Length: 2, Unit: "Meter"} }, false},
+		{"too many pieces", func(req *GenericShippingRequest) { req.NumberOfPieces = 6 }, false},
+		{"insured", func(req *GenericShippingRequest) { req.IsInsured = true }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &GenericShippingRequest{
+				Weight:         WeightInfo{Value: 1000, Unit: "Grams"},
+				Consignee:      Party{Address: Address{CountryCode: "AE"}},
+				Dimensions:     Dimensions{Length: 10, Width: 10, Height: 10, Unit: "CM"},
+				NumberOfPieces: 1,
+				DeclaredValue:  DeclaredValue{Amount: 100, Currency: "AED"},
+			}
+			tt.modify(req)
+
+			reasons := capabilities.CheckEligibility(req)
+			if tt.eligible && len(reasons) > 0 {
+				t.Errorf("expected eligible, got reasons %v", reasons)
+			}
+			if !tt.eligible && len(reasons) == 0 {
+				t.Error("expected ineligible, got no reasons")
+			}
+		})
+	}
+}
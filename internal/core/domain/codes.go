@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	CodeFieldProduct  = "productCode"
+	CodeFieldService  = "serviceType"
+	CodeFieldDelivery = "deliveryType"
+	CodeFieldContent  = "contentType"
+)
+
+const (
+	ProductInternational = "International"
+	ProductDomestic      = "Domestic"
+
+	ServiceNone     = "None"
+	ServiceStandard = "Standard"
+	ServiceExpress  = "Express"
+
+	DeliveryDoorToDoor = "DoorToDoor"
+	DeliveryDoorToPort = "DoorToPort"
+	DeliveryPortToDoor = "PortToDoor"
+	DeliveryPortToPort = "PortToPort"
+
+	ContentDocument    = "Document"
+	ContentNonDocument = "NonDocument"
+)
+
+var CanonicalCodes = map[string][]string{
+	CodeFieldProduct:  {ProductInternational, ProductDomestic},
+	CodeFieldService:  {ServiceNone, ServiceStandard, ServiceExpress},
+	CodeFieldDelivery: {DeliveryDoorToDoor, DeliveryDoorToPort, DeliveryPortToDoor, DeliveryPortToPort},
+	CodeFieldContent:  {ContentDocument, ContentNonDocument},
+}
+
+// CodeTable maps a code field to canonical-to-native translations. A field
+// missing from the table is not carried by the provider and passes through.
+type CodeTable map[string]map[string]string
+
+type CodeTranslationError struct {
+	Provider string
+	Field    string
+	Code     string
+}
+
+func (e *CodeTranslationError) Error() string {
+	return fmt.Sprintf("%s %q has no mapping for provider %s", e.Field, e.Code, e.Provider)
+}
+
+func (t CodeTable) Translate(provider, field, code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+	translations, ok := t[field]
+	if !ok {
+		return code, nil
+	}
+	native, ok := translations[code]
+	if !ok {
+		return "", &CodeTranslationError{Provider: provider, Field: field, Code: code}
+	}
+	return native, nil
+}
+
+func (t CodeTable) Codes(field string) []string {
+	codes := make([]string, 0, len(t[field]))
+	for code := range t[field] {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (t CodeTable) Merge(overrides CodeTable) CodeTable {
+	merged := CodeTable{}
+	for _, table := range []CodeTable{t, overrides} {
+		for field, translations := range table {
+			if merged[field] == nil {
+				merged[field] = make(map[string]string, len(translations))
+			}
+			for code, native := range translations {
+				merged[field][code] = native
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeTable_Translate(t *testing.T) {
+	table := CodeTable{
+		CodeFieldProduct: {ProductInternational: "XPS"},
+	}
+
+	native, err := table.Translate("B", CodeFieldProduct, ProductInternational)
+	if err != nil || native != "XPS" {
+		t.Errorf("expected XPS, got %q (err %v)", native, err)
+	}
+
+	native, err = table.Translate("B", CodeFieldDelivery, DeliveryDoorToDoor)
+	if err != nil || native != DeliveryDoorToDoor {
+		t.Errorf("expected untranslated field to pass through, got %q (err %v)", native, err)
+	}
+
+	native, err = table.Translate("B", CodeFieldProduct, "")
+	if err != nil || native != "" {
+		t.Errorf("expected empty code to stay empty, got %q (err %v)", native, err)
+	}
+
+	_, err = table.Translate("B", CodeFieldProduct, ProductDomestic)
+	var codeErr *CodeTranslationError
+	if !errors.As(err, &codeErr) {
+		t.Fatalf("expected code translation error, got %v", err)
+	}
+	if codeErr.Provider != "B" || codeErr.Field != CodeFieldProduct {
+		t.Errorf("expected error naming provider B and field productCode, got %+v", codeErr)
+	}
+}
+
+func TestCodeTable_Merge(t *testing.T) {
+	defaults := CodeTable{CodeFieldProduct: {ProductInternational: "XPS"}}
+	overrides := CodeTable{CodeFieldProduct: {ProductDomestic: "DOM"}}
+
+	merged := defaults.Merge(overrides)
+
+	if merged[CodeFieldProduct][ProductInternational] != "XPS" || merged[CodeFieldProduct][ProductDomestic] != "DOM" {
+		t.Errorf("expected defaults and overrides to be merged, got %v", merged)
+	}
+
+	if len(defaults[CodeFieldProduct]) != 1 {
+		t.Error("expected merge not to modify the defaults")
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "context"
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// RequestIDHeader carries the request ID from clients, back to them and on
+// to the carriers.
+const RequestIDHeader = "X-Request-ID"
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+const apiKeyKey contextKey = "apiKey"
+
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// APIKeyFromContext returns the key the request authenticated with, or nil.
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyKey).(*APIKey)
+	return key
+}
+
+const tenantKey contextKey = "tenant"
+
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the tenant the request acts for, or nil for
+// work not done on behalf of a tenant, such as background workers and
+// carrier webhooks.
+func TenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey).(*Tenant)
+	return tenant
+}
+
+// TenantIDFromContext returns the ID of the tenant in ctx, or
+// DefaultTenantID when there is none.
+func TenantIDFromContext(ctx context.Context) string {
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		return tenant.ID
+	}
+	return DefaultTenantID
+}
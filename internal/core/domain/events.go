@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	EventShipmentCreated   = "shipment.created"
+	EventShipmentFailed    = "shipment.failed"
+	EventStatusChanged     = "status.changed"
+	EventShipmentCancelled = "shipment.cancelled"
+)
+
+var EventTypes = []string{EventShipmentCreated, EventShipmentFailed, EventStatusChanged, EventShipmentCancelled}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+var ErrInvalidSubscription = errors.New("invalid webhook subscription")
+
+var ErrDeliveryNotDead = errors.New("only dead-lettered deliveries can be replayed")
+
+// ShipmentEvent is the body posted to subscribers.
+type ShipmentEvent struct {
+	ID         string          `json:"id"`
+	TenantID   string          `json:"-"`
+	Type       string          `json:"type"`
+	ShipmentID string          `json:"shipmentId,omitempty"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type ShipmentEventData struct {
+	ShipmentID string         `json:"shipmentId,omitempty"`
+	RequestID  string         `json:"requestId,omitempty"`
+	Provider   string         `json:"provider"`
+	TrackingID string         `json:"trackingId,omitempty"`
+	AWB        string         `json:"awb,omitempty"`
+	Status     ShipmentStatus `json:"status,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID         string    `json:"id" db:"id"`
+	TenantID   string    `json:"-" db:"tenant_id"`
+	URL        string    `json:"url" db:"url"`
+	EventTypes []string  `json:"events" db:"event_types"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Receives reports whether the subscription gets event: it must match the
+// event type and belong to the event's tenant.
+func (s *WebhookSubscription) Receives(event *ShipmentEvent) bool {
+	return s.Matches(event.Type) && tenantOrDefault(s.TenantID) == tenantOrDefault(event.TenantID)
+}
+
+func tenantOrDefault(tenantID string) string {
+	if tenantID == "" {
+		return DefaultTenantID
+	}
+	return tenantID
+}
+
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             string    `json:"id" db:"id"`
+	SubscriptionID string    `json:"subscriptionId" db:"subscription_id"`
+	EventID        string    `json:"eventId" db:"event_id"`
+	EventType      string    `json:"eventType" db:"event_type"`
+	Payload        []byte    `json:"-" db:"payload"`
+	Status         string    `json:"status" db:"status"`
+	Attempts       int       `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time `json:"nextAttemptAt" db:"next_attempt_at"`
+	LastStatusCode int       `json:"lastStatusCode,omitempty" db:"last_status_code"`
+	LastError      string    `json:"lastError,omitempty" db:"last_error"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID            string    `json:"id" db:"id"`
+	DeliveryID    string    `json:"deliveryId" db:"delivery_id"`
+	AttemptNumber int       `json:"attemptNumber" db:"attempt_number"`
+	StatusCode    int       `json:"statusCode,omitempty" db:"status_code"`
+	Error         string    `json:"error,omitempty" db:"error"`
+	DurationMs    int64     `json:"durationMs" db:"duration_ms"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
+
+type DeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
+
+// RetryPolicy spaces delivery attempts exponentially. A delivery that has
+// used MaxAttempts is dead-lettered.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 8,
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    time.Hour,
+}
+
+// Delay returns the wait before the attempt after the given number of
+// attempts made so far.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
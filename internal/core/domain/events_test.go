@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempts); got != tt.want {
+			t.Errorf("attempts %d: expected %v, got %v", tt.attempts, tt.want, got)
+		}
+	}
+}
+
+func TestWebhookSubscription_Matches(t *testing.T) {
+	subscription := &WebhookSubscription{EventTypes: []string{EventStatusChanged}, Active: true}
+
+	if !subscription.Matches(EventStatusChanged) {
+		t.Error("expected subscribed event to match")
+	}
+	if subscription.Matches(EventShipmentCreated) {
+		t.Error("expected other event not to match")
+	}
+
+	subscription.Active = false
+	if subscription.Matches(EventStatusChanged) {
+		t.Error("expected inactive subscription not to match")
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+// Job is an asynchronously processed shipment request. An empty Provider
+// means the request is broadcast to every provider.
+type Job struct {
+	ID          string          `json:"id" db:"id"`
+	TenantID    string          `json:"-" db:"tenant_id"`
+	Status      string          `json:"status" db:"status"`
+	Provider    string          `json:"provider,omitempty" db:"provider"`
+	RequestID   string          `json:"requestId" db:"request_id"`
+	Request     json.RawMessage `json:"-" db:"request"`
+	Results     json.RawMessage `json:"results,omitempty" db:"results"`
+	Error       string          `json:"error,omitempty" db:"error"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"maxAttempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"runAt" db:"run_at"`
+	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updatedAt" db:"updated_at"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty" db:"completed_at"`
+}
+
+type JobFilter struct {
+	Status string
+	Limit  int
+}
+
+var DefaultJobRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Second,
+	MaxDelay:    10 * time.Minute,
+}
+
+// IsRetryable reports whether a provider call failed in a way that may
+// succeed later: the carrier answered 429 or 5xx. Timeouts and network errors
+// are not retried, since the carrier may have booked the shipment before the
+// call failed.
+func IsRetryable(response *ShipmentResponse, err error) bool {
+	if err != nil || response == nil || response.Success || response.Exchange == nil {
+		return false
+	}
+	status := response.Exchange.StatusCode
+	return status == http.StatusTooManyRequests || status >= 500
+}
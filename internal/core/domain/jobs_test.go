@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *ShipmentResponse
+		err      error
+		expected bool
+	}{
+		{"timeout", nil, &ProviderError{Category: ErrorCategoryTimeout, Err: errors.New("deadline")}, false},
+		{"network", nil, &ProviderError{Category: ErrorCategoryNetwork, Err: errors.New("refused")}, false},
+		{"internal", nil, errors.New("provider X not found"), false},
+		{"server error", &ShipmentResponse{Exchange: &ProviderExchange{StatusCode: http.StatusBadGateway}}, nil, true},
+		{"rate limited", &ShipmentResponse{Exchange: &ProviderExchange{StatusCode: http.StatusTooManyRequests}}, nil, true},
+		{"rejected", &ShipmentResponse{Exchange: &ProviderExchange{StatusCode: http.StatusBadRequest}}, nil, false},
+		{"accepted", &ShipmentResponse{Success: true, Exchange: &ProviderExchange{StatusCode: http.StatusOK}}, nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.response, tt.err); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
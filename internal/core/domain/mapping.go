@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	WarningDropped   = "dropped"
+	WarningTruncated = "truncated"
+	WarningDefaulted = "defaulted"
+
+	WarningTransliterated = "transliterated"
+)
+
+type MappingWarning struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type MappingError struct {
+	Provider string
+	Warnings []MappingWarning
+}
+
+func (e *MappingError) Error() string {
+	fields := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		fields[i] = fmt.Sprintf("%s (%s)", w.Field, w.Reason)
+	}
+	return fmt.Sprintf("strict mapping rejected request for provider %s: %s", e.Provider, strings.Join(fields, ", "))
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type GenericShippingRequest struct {
 	Weight              WeightInfo             `json:"weight"`
@@ -22,6 +25,7 @@ type GenericShippingRequest struct {
 	IsCOD               bool                   `json:"isCod"`
 	CODAmount           float64                `json:"codAmount"`
 	Packages            []Package              `json:"packages"`
+	StrictMapping       bool                   `json:"strictMapping,omitempty"`
 }
 
 type WeightInfo struct {
@@ -66,6 +70,26 @@ type AccountInfo struct {
 	Password string `json:"password"`
 }
 
+// RedactedValue stands in for credentials in stored payloads.
+const RedactedValue = "[REDACTED]"
+
+// RedactSecret returns RedactedValue for a set secret, so a missing value
+// still shows as missing.
+func RedactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// Redacted returns the account with its credentials masked, for storing and
+// showing alongside the shipment.
+func (a AccountInfo) Redacted() AccountInfo {
+	a.Username = RedactSecret(a.Username)
+	a.Password = RedactSecret(a.Password)
+	return a
+}
+
 type CustomsDeclaration struct {
 	Reference       string     `json:"reference"`
 	Description     string     `json:"description"`
@@ -97,15 +121,38 @@ type ShipmentResponse struct {
 	TrackingID  string                 `json:"trackingId,omitempty"`
 	AWB         string                 `json:"awb,omitempty"`
 	Message     string                 `json:"message,omitempty"`
+	RequestID   string                 `json:"requestId,omitempty"`
+	ShipmentID  string                 `json:"shipmentId,omitempty"`
+	Warnings    []MappingWarning       `json:"warnings,omitempty"`
 	RawResponse map[string]interface{} `json:"rawResponse,omitempty"`
+	Exchange    *ProviderExchange      `json:"-"`
 }
 
 type ShipmentRecord struct {
-	ID                   string    `json:"id" db:"id"`
-	Provider             string    `json:"provider" db:"provider"`
-	GenericPayload       []byte    `json:"genericPayload" db:"generic_payload"`
-	TransformedPayload   []byte    `json:"transformedPayload" db:"transformed_payload"`
-	ProviderResponse     []byte    `json:"providerResponse" db:"provider_response"`
-	Success              bool      `json:"success" db:"success"`
-	CreatedAt            time.Time `json:"createdAt" db:"created_at"`
+	ID                   string         `json:"id" db:"id"`
+	TenantID             string         `json:"-" db:"tenant_id"`
+	Provider             string         `json:"provider" db:"provider"`
+	GenericPayload       []byte         `json:"genericPayload" db:"generic_payload"`
+	TransformedPayload   []byte         `json:"transformedPayload" db:"transformed_payload"`
+	ProviderResponse     []byte         `json:"providerResponse" db:"provider_response"`
+	RawResponse          []byte         `json:"rawResponse" db:"raw_response"`
+	Success              bool           `json:"success" db:"success"`
+	CreatedAt            time.Time      `json:"createdAt" db:"created_at"`
+	TrackingID           string         `json:"trackingId,omitempty" db:"tracking_id"`
+	AWB                  string         `json:"awb,omitempty" db:"awb"`
+	ReferenceNumbers     []string       `json:"referenceNumbers,omitempty" db:"reference_numbers"`
+	DestinationCountry   string         `json:"destinationCountry,omitempty" db:"destination_country"`
+	ConsigneeEmailHash   string         `json:"-" db:"consignee_email_hash"`
+	Status               ShipmentStatus `json:"status" db:"status"`
+	StatusUpdatedAt      time.Time      `json:"statusUpdatedAt" db:"status_updated_at"`
+	NextPollAt           time.Time      `json:"-" db:"next_poll_at"`
+	UnchangedPolls       int            `json:"-" db:"unchanged_polls"`
+}
+
+type TransformResult struct {
+	Provider string           `json:"provider"`
+	Eligible bool             `json:"eligible"`
+	Errors   []string         `json:"errors,omitempty"`
+	Payload  json.RawMessage  `json:"payload,omitempty"`
+	Warnings []MappingWarning `json:"warnings,omitempty"`
 }
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxEvent is a shipment event stored in the same transaction as the
+// change that produced it, waiting to be relayed to the event sinks.
+// Sequence orders events per shipment. DeadAt is set once the relay gives up
+// on the event.
+type OutboxEvent struct {
+	Sequence      int64
+	Event         *ShipmentEvent
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	PublishedAt   *time.Time
+	DeadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// DefaultOutboxRetryPolicy spaces relay retries over about an hour and a
+// half. An event still failing then is dead-lettered, so it stops holding
+// back later events for its shipment.
+var DefaultOutboxRetryPolicy = RetryPolicy{
+	MaxAttempts: 20,
+	BaseDelay:   5 * time.Second,
+	MaxDelay:    5 * time.Minute,
+}
+
+func NewShipmentEvent(eventType, shipmentID string, occurredAt time.Time, data interface{}) (*ShipmentEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event data: %w", eventType, err)
+	}
+	return &ShipmentEvent{
+		Type:       eventType,
+		ShipmentID: shipmentID,
+		OccurredAt: occurredAt,
+		Data:       payload,
+	}, nil
+}
+
+// CreatedEvent returns the shipment.created event for a newly saved record.
+func (r *ShipmentRecord) CreatedEvent(requestID string) (*ShipmentEvent, error) {
+	event, err := NewShipmentEvent(EventShipmentCreated, r.ID, r.CreatedAt, ShipmentEventData{
+		ShipmentID: r.ID,
+		RequestID:  requestID,
+		Provider:   r.Provider,
+		TrackingID: r.TrackingID,
+		AWB:        r.AWB,
+		Status:     r.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	event.TenantID = r.TenantID
+	return event, nil
+}
+
+// FailedEvent returns the shipment.failed event for an attempt that did not
+// produce a shipment, or nil when the attempt is not a failure.
+func (a *ShipmentAttempt) FailedEvent() (*ShipmentEvent, error) {
+	if a.ShipmentID != "" || (a.Success && a.ErrorMessage == "") {
+		return nil, nil
+	}
+	event, err := NewShipmentEvent(EventShipmentFailed, "", a.CreatedAt, ShipmentEventData{
+		RequestID: a.RequestID,
+		Provider:  a.Provider,
+		Error:     a.ErrorMessage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	event.TenantID = a.TenantID
+	return event, nil
+}
+
+// Events returns status.changed for an applied change, followed by
+// shipment.cancelled when the change cancels the shipment.
+func (c *StatusChange) Events() ([]*ShipmentEvent, error) {
+	changed, err := NewShipmentEvent(EventStatusChanged, c.ShipmentID, c.OccurredAt, c)
+	if err != nil {
+		return nil, err
+	}
+	events := []*ShipmentEvent{changed}
+
+	if c.ToStatus == StatusCancelled {
+		cancelled, err := NewShipmentEvent(EventShipmentCancelled, c.ShipmentID, c.OccurredAt, c)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, cancelled)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestShipmentAttempt_FailedEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  ShipmentAttempt
+		expected bool
+	}{
+		{"created shipment", ShipmentAttempt{ShipmentID: "s1", Success: true}, false},
+		{"saved after success", ShipmentAttempt{Success: true}, false},
+		{"provider rejection", ShipmentAttempt{Provider: "A", ErrorMessage: "rejected"}, true},
+		{"save failure", ShipmentAttempt{Success: true, ErrorMessage: "db down"}, true},
+	}
+
+	for _, tt := range tests {
+		event, err := tt.attempt.FailedEvent()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if (event != nil) != tt.expected {
+			t.Errorf("%s: expected event=%v, got %+v", tt.name, tt.expected, event)
+		}
+	}
+}
+
+func TestStatusChange_Events(t *testing.T) {
+	change := &StatusChange{ShipmentID: "s1", FromStatus: StatusCreated, ToStatus: StatusCancelled, OccurredAt: time.Now()}
+
+	events, err := change.Events()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != EventStatusChanged || events[1].Type != EventShipmentCancelled {
+		t.Fatalf("expected status.changed and shipment.cancelled, got %+v", events)
+	}
+
+	var data StatusChange
+	if err := json.Unmarshal(events[0].Data, &data); err != nil {
+		t.Fatalf("failed to unmarshal event data: %v", err)
+	}
+	if data.ToStatus != StatusCancelled || events[0].ShipmentID != "s1" {
+		t.Errorf("expected event data to carry the change, got %+v", data)
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// TrackingPolicy controls how often a provider's tracking endpoint is polled.
+// BatchSize is the number of shipments sent per tracking call and
+// RequestsPerMinute caps tracking calls per instance. TrackBy names the
+// identifier the tracking endpoint looks shipments up by; shipments without
+// it are not polled. Empty means either identifier works.
+type TrackingPolicy struct {
+	BatchSize         int
+	RequestsPerMinute int
+	PollInterval      time.Duration
+	MaxPollInterval   time.Duration
+	TrackBy           string
+}
+
+const (
+	TrackByTrackingID = "trackingId"
+	TrackByAWB        = "awb"
+)
+
+// Trackable reports whether record has the identifier the policy tracks by.
+func (p TrackingPolicy) Trackable(record *ShipmentRecord) bool {
+	switch p.TrackBy {
+	case TrackByTrackingID:
+		return record.TrackingID != ""
+	case TrackByAWB:
+		return record.AWB != ""
+	default:
+		return record.TrackingID != "" || record.AWB != ""
+	}
+}
+
+// NextPoll doubles the poll interval for every consecutive poll that found
+// no change, up to MaxPollInterval.
+func (p TrackingPolicy) NextPoll(now time.Time, unchangedPolls int) time.Time {
+	interval := p.PollInterval
+	for i := 0; i < unchangedPolls && interval < p.MaxPollInterval; i++ {
+		interval *= 2
+	}
+	if p.MaxPollInterval > 0 && interval > p.MaxPollInterval {
+		interval = p.MaxPollInterval
+	}
+	return now.Add(interval)
+}
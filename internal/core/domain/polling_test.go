@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackingPolicy_NextPoll(t *testing.T) {
+	policy := TrackingPolicy{PollInterval: 15 * time.Minute, MaxPollInterval: 2 * time.Hour}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		unchanged int
+		want      time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 30 * time.Minute},
+		{3, 2 * time.Hour},
+		{10, 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := policy.NextPoll(now, tt.unchanged).Sub(now); got != tt.want {
+			t.Errorf("unchanged %d: expected %v, got %v", tt.unchanged, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// PopulateIndexFields fills the searchable columns from the stored payloads
+// when they have not been set explicitly.
+func (r *ShipmentRecord) PopulateIndexFields() {
+	var response ShipmentResponse
+	if err := json.Unmarshal(r.ProviderResponse, &response); err == nil {
+		if r.TrackingID == "" {
+			r.TrackingID = response.TrackingID
+		}
+		if r.AWB == "" {
+			r.AWB = response.AWB
+		}
+	}
+
+	var request GenericShippingRequest
+	if err := json.Unmarshal(r.GenericPayload, &request); err == nil {
+		if r.ReferenceNumbers == nil {
+			r.ReferenceNumbers = request.ReferenceNumbers
+		}
+		if r.DestinationCountry == "" {
+			r.DestinationCountry = strings.ToUpper(request.Consignee.Address.CountryCode)
+		}
+		if r.ConsigneeEmailHash == "" {
+			r.ConsigneeEmailHash = HashEmail(request.Consignee.Contact.EmailAddress)
+		}
+	}
+}
+
+func HashEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
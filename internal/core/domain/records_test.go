@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestShipmentRecord_PopulateIndexFields(t *testing.T) {
+	record := &ShipmentRecord{
+		GenericPayload:   []byte(`{"referenceNumbers": ["REF-1"], "consignee": {"address": {"countryCode": "ae"}, "contact": {"emailAddress": " User@Example.com "}}}`),
+		ProviderResponse: []byte(`{"trackingId": "T-1", "awb": "A-1"}`),
+	}
+
+	record.PopulateIndexFields()
+
+	if record.TrackingID != "T-1" || record.AWB != "A-1" {
+		t.Errorf("expected tracking T-1 and AWB A-1, got %q and %q", record.TrackingID, record.AWB)
+	}
+	if len(record.ReferenceNumbers) != 1 || record.ReferenceNumbers[0] != "REF-1" {
+		t.Errorf("expected reference REF-1, got %v", record.ReferenceNumbers)
+	}
+	if record.DestinationCountry != "AE" {
+		t.Errorf("expected destination AE, got %q", record.DestinationCountry)
+	}
+	if record.ConsigneeEmailHash != HashEmail("user@example.com") {
+		t.Errorf("expected email hash to ignore case and whitespace")
+	}
+}
+
+func TestShipmentRecord_PopulateIndexFieldsKeepsExplicitValues(t *testing.T) {
+	record := &ShipmentRecord{
+		ProviderResponse: []byte(`{"trackingId": "T-1"}`),
+		TrackingID:       "T-2",
+	}
+
+	record.PopulateIndexFields()
+
+	if record.TrackingID != "T-2" {
+		t.Errorf("expected explicit tracking ID to be kept, got %q", record.TrackingID)
+	}
+}
+
+func TestHashEmail_Empty(t *testing.T) {
+	if got := HashEmail("  "); got != "" {
+		t.Errorf("expected empty hash for blank email, got %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RequestSchemaVersion is bumped whenever a change to GenericShippingRequest
+// would reject payloads the previous schema accepted.
+const RequestSchemaVersion = "v1"
+
+const RequestSchemaID = "/schemas/generic-shipping-request/" + RequestSchemaVersion
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) used to describe
+// and validate GenericShippingRequest.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 []string               `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+}
+
+type fieldConstraint struct {
+	enum             []string
+	pattern          string
+	minimum          *float64
+	exclusiveMinimum *float64
+}
+
+var nonNegative = 0.0
+
+// Empty strings stay valid for codes and units: they select the provider
+// default, and clients that serialise zero values send them.
+var requestConstraints = map[string]fieldConstraint{
+	"GenericShippingRequest.productCode":    {enum: CanonicalCodes[CodeFieldProduct]},
+	"GenericShippingRequest.serviceType":    {enum: CanonicalCodes[CodeFieldService]},
+	"GenericShippingRequest.deliveryType":   {enum: CanonicalCodes[CodeFieldDelivery]},
+	"GenericShippingRequest.contentType":    {enum: CanonicalCodes[CodeFieldContent]},
+	"GenericShippingRequest.numberOfPieces": {minimum: &nonNegative},
+	"GenericShippingRequest.codAmount":      {minimum: &nonNegative},
+	"WeightInfo.value":                      {exclusiveMinimum: &nonNegative},
+	"WeightInfo.unit":                       {enum: WeightUnits},
+	"Dimensions.length":                     {minimum: &nonNegative},
+	"Dimensions.height":                     {minimum: &nonNegative},
+	"Dimensions.width":                      {minimum: &nonNegative},
+	"Dimensions.unit":                       {enum: DimensionUnits},
+	"Address.countryCode":                   {pattern: "^[A-Z]{2}$"},
+	"DeclaredValue.amount":                  {minimum: &nonNegative},
+	"DeclaredValue.currency":                {pattern: "^([A-Z]{3})?$"},
+	"CustomsDeclaration.countryOfOrigin":    {pattern: "^([A-Z]{2})?$"},
+	"CustomsDeclaration.weight":             {minimum: &nonNegative},
+	"CustomsDeclaration.quantity":           {minimum: &nonNegative},
+	"CustomsDeclaration.value":              {minimum: &nonNegative},
+	"Package.width":                         {minimum: &nonNegative},
+	"Package.height":                        {minimum: &nonNegative},
+	"Package.length":                        {minimum: &nonNegative},
+	"Package.weight":                        {minimum: &nonNegative},
+	"Package.pieces":                        {minimum: &nonNegative},
+	"Package.value":                         {minimum: &nonNegative},
+}
+
+var requiredFields = map[string][]string{
+	"GenericShippingRequest": {"weight", "shipper", "consignee"},
+	"Party":                  {"contact", "address"},
+}
+
+// RequestSchema generates the JSON Schema for GenericShippingRequest from
+// the struct's json tags and the constraints above.
+func RequestSchema() *JSONSchema {
+	schema := schemaFor(reflect.TypeOf(GenericShippingRequest{}))
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.ID = RequestSchemaID
+	schema.Title = "GenericShippingRequest"
+	return schema
+}
+
+func schemaFor(typ reflect.Type) *JSONSchema {
+	switch typ.Kind() {
+	case reflect.Struct:
+		closed := false
+		schema := &JSONSchema{
+			Type:                 []string{"object"},
+			Properties:           map[string]*JSONSchema{},
+			Required:             requiredFields[typ.Name()],
+			AdditionalProperties: &closed,
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			property := schemaFor(field.Type)
+			if constraint, ok := requestConstraints[typ.Name()+"."+name]; ok {
+				if len(constraint.enum) > 0 {
+					property.Enum = append([]string{""}, constraint.enum...)
+				}
+				property.Pattern = constraint.pattern
+				property.Minimum = constraint.minimum
+				property.ExclusiveMinimum = constraint.exclusiveMinimum
+			}
+			schema.Properties[name] = property
+		}
+		return schema
+	case reflect.Slice:
+		return &JSONSchema{Type: []string{"array", "null"}, Items: schemaFor(typ.Elem())}
+	case reflect.String:
+		return &JSONSchema{Type: []string{"string"}}
+	case reflect.Bool:
+		return &JSONSchema{Type: []string{"boolean"}}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: []string{"number"}}
+	default:
+		return &JSONSchema{Type: []string{"integer"}}
+	}
+}
+
+type SchemaViolation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = fmt.Sprintf("%s: %s", v.Pointer, v.Message)
+	}
+	return "request does not match schema: " + strings.Join(parts, "; ")
+}
+
+// Validate checks a JSON document against the schema and reports every
+// violation by its JSON pointer (RFC 6901).
+func (s *JSONSchema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return &SchemaError{Violations: []SchemaViolation{{Pointer: "", Message: "invalid JSON: " + err.Error()}}}
+	}
+	if decoder.More() {
+		return &SchemaError{Violations: []SchemaViolation{{Pointer: "", Message: "unexpected data after the JSON document"}}}
+	}
+
+	var violations []SchemaViolation
+	s.validate(document, "", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Pointer < violations[j].Pointer
+	})
+	return &SchemaError{Violations: violations}
+}
+
+func (s *JSONSchema) validate(value interface{}, pointer string, violations *[]SchemaViolation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	kind := jsonKind(value)
+	if kind == "integer" && !s.allows("integer") && s.allows("number") {
+		kind = "number"
+	}
+	if !s.allows(kind) {
+		report("expected %s, got %s", strings.Join(s.Type, " or "), kind)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{Pointer: pointer + "/" + escapePointer(name), Message: "required field is missing"})
+			}
+		}
+		for name, item := range v {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*violations = append(*violations, SchemaViolation{Pointer: pointer + "/" + escapePointer(name), Message: "unknown field"})
+				}
+				continue
+			}
+			property.validate(item, pointer+"/"+escapePointer(name), violations)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s/%d", pointer, i), violations)
+			}
+		}
+	case string:
+		if len(s.Enum) > 0 && !includes(s.Enum, v) {
+			report("%q is not one of %q", v, s.Enum)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			report("%q does not match %s", v, s.Pattern)
+		}
+	case json.Number:
+		number, _ := v.Float64()
+		if s.Minimum != nil && number < *s.Minimum {
+			report("must be at least %v", *s.Minimum)
+		}
+		if s.ExclusiveMinimum != nil && number <= *s.ExclusiveMinimum {
+			report("must be greater than %v", *s.ExclusiveMinimum)
+		}
+	}
+}
+
+func (s *JSONSchema) allows(kind string) bool {
+	for _, allowed := range s.Type {
+		if allowed == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonKind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func includes(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleRequestJSON(t *testing.T, mutate func(map[string]interface{})) []byte {
+	t.Helper()
+	request := GenericShippingRequest{
+		Weight:    WeightInfo{Value: 1000, Unit: "Grams"},
+		Shipper:   Party{Address: Address{CountryCode: "AE"}},
+		Consignee: Party{Address: Address{CountryCode: "IN"}},
+		Packages:  []Package{{Weight: 1, Pieces: 1}},
+	}
+	encoded, _ := json.Marshal(request)
+	var document map[string]interface{}
+	json.Unmarshal(encoded, &document)
+	if mutate != nil {
+		mutate(document)
+	}
+	encoded, _ = json.Marshal(document)
+	return encoded
+}
+
+func TestRequestSchema_CoversRequestFields(t *testing.T) {
+	schema := RequestSchema()
+	if schema.ID != RequestSchemaID || schema.Properties["weight"].Properties["unit"].Enum == nil {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+
+	typ := reflect.TypeOf(GenericShippingRequest{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("field %s missing from schema", name)
+		}
+	}
+	if items := schema.Properties["packages"].Items; items == nil || items.Properties["pieces"].Minimum == nil {
+		t.Errorf("expected package constraints, got %+v", items)
+	}
+}
+
+func TestRequestSchema_Validate(t *testing.T) {
+	schema := RequestSchema()
+
+	if err := schema.Validate(sampleRequestJSON(t, nil)); err != nil {
+		t.Fatalf("expected sample request to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(map[string]interface{})
+		pointer string
+		message string
+	}{
+		{"unknown field", func(d map[string]interface{}) {
+			d["consignee"].(map[string]interface{})["contact"].(map[string]interface{})["mobileNo"] = "123"
+		}, "/consignee/contact/mobileNo", "unknown field"},
+		{"wrong type", func(d map[string]interface{}) {
+			d["weight"].(map[string]interface{})["value"] = "1000"
+		}, "/weight/value", "expected number, got string"},
+		{"fractional integer", func(d map[string]interface{}) {
+			d["numberOfPieces"] = 1.5
+		}, "/numberOfPieces", "expected integer, got number"},
+		{"out of range", func(d map[string]interface{}) {
+			d["packages"].([]interface{})[0].(map[string]interface{})["pieces"] = -1
+		}, "/packages/0/pieces", "must be at least 0"},
+		{"zero weight", func(d map[string]interface{}) {
+			d["weight"].(map[string]interface{})["value"] = 0
+		}, "/weight/value", "must be greater than 0"},
+		{"unknown unit", func(d map[string]interface{}) {
+			d["weight"].(map[string]interface{})["unit"] = "Gram"
+		}, "/weight/unit", `"Gram" is not one of`},
+		{"bad country", func(d map[string]interface{}) {
+			d["shipper"].(map[string]interface{})["address"].(map[string]interface{})["countryCode"] = "uae"
+		}, "/shipper/address/countryCode", "does not match"},
+		{"missing required", func(d map[string]interface{}) {
+			delete(d, "shipper")
+		}, "/shipper", "required field is missing"},
+		{"escaped pointer", func(d map[string]interface{}) {
+			d["a/b~c"] = true
+		}, "/a~1b~0c", "unknown field"},
+	}
+
+	for _, tt := range tests {
+		err := schema.Validate(sampleRequestJSON(t, tt.mutate))
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("%s: expected SchemaError, got %v", tt.name, err)
+			continue
+		}
+		if len(schemaErr.Violations) != 1 || schemaErr.Violations[0].Pointer != tt.pointer || !strings.Contains(schemaErr.Violations[0].Message, tt.message) {
+			t.Errorf("%s: unexpected violations %+v", tt.name, schemaErr.Violations)
+		}
+	}
+}
+
+func TestRequestSchema_ValidateInvalidJSON(t *testing.T) {
+	for _, body := range []string{`{"weight":`, `{} {}`, `[]`} {
+		var schemaErr *SchemaError
+		if err := RequestSchema().Validate([]byte(body)); !errors.As(err, &schemaErr) || schemaErr.Violations[0].Pointer != "" {
+			t.Errorf("%s: expected a root violation, got %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrShipmentNotFound = errors.New("shipment record not found")
+
+// ErrShipmentNotSaved means the carrier booked the shipment but its record
+// could not be stored.
+var ErrShipmentNotSaved = errors.New("shipment booked but not saved")
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+type ShipmentFilter struct {
+	Provider        string
+	Success         *bool
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	TrackingID      string
+	AWB             string
+	ReferenceNumber string
+	ConsigneeEmail  string
+	Destination     string
+	Status          ShipmentStatus
+	Cursor          string
+	Limit           int
+	SortOrder       string
+}
+
+type ShipmentPage struct {
+	Records    []*ShipmentRecord
+	NextCursor string
+}
+
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ShipmentStatus string
+
+const (
+	StatusCreated        ShipmentStatus = "created"
+	StatusLabelGenerated ShipmentStatus = "label_generated"
+	StatusPickedUp       ShipmentStatus = "picked_up"
+	StatusInTransit      ShipmentStatus = "in_transit"
+	StatusOutForDelivery ShipmentStatus = "out_for_delivery"
+	StatusDelivered      ShipmentStatus = "delivered"
+	StatusException      ShipmentStatus = "exception"
+	StatusReturned       ShipmentStatus = "returned"
+	StatusCancelled      ShipmentStatus = "cancelled"
+)
+
+var ShipmentStatuses = []ShipmentStatus{
+	StatusCreated, StatusLabelGenerated, StatusPickedUp, StatusInTransit, StatusOutForDelivery,
+	StatusDelivered, StatusException, StatusReturned, StatusCancelled,
+}
+
+const (
+	StatusSourceAPI     = "api"
+	StatusSourceWebhook = "webhook"
+	StatusSourcePoller  = "poller"
+)
+
+var ErrInvalidStatus = errors.New("invalid shipment status")
+
+var statusTransitions = map[ShipmentStatus][]ShipmentStatus{
+	StatusCreated:        {StatusLabelGenerated, StatusPickedUp, StatusInTransit, StatusException, StatusCancelled},
+	StatusLabelGenerated: {StatusPickedUp, StatusInTransit, StatusException, StatusCancelled},
+	StatusPickedUp:       {StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned},
+	StatusInTransit:      {StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned},
+	StatusOutForDelivery: {StatusInTransit, StatusDelivered, StatusException, StatusReturned},
+	StatusException:      {StatusPickedUp, StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusReturned, StatusCancelled},
+	StatusDelivered:      nil,
+	StatusReturned:       nil,
+	StatusCancelled:      nil,
+}
+
+func ParseShipmentStatus(value string) (ShipmentStatus, error) {
+	status := ShipmentStatus(value)
+	if _, ok := statusTransitions[status]; !ok {
+		return "", fmt.Errorf("%w %q", ErrInvalidStatus, value)
+	}
+	return status, nil
+}
+
+// IsTerminal reports whether no further transitions are allowed.
+func (s ShipmentStatus) IsTerminal() bool {
+	next, ok := statusTransitions[s]
+	return ok && len(next) == 0
+}
+
+// CanTransitionTo reports whether next is a valid successor. Repeating a
+// non-terminal status is allowed so intermediate scans land in the timeline.
+func (s ShipmentStatus) CanTransitionTo(next ShipmentStatus) bool {
+	if s == next {
+		return !s.IsTerminal()
+	}
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type TransitionError struct {
+	From ShipmentStatus
+	To   ShipmentStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("shipment cannot move from %s to %s", e.From, e.To)
+}
+
+func ValidateTransition(from, to ShipmentStatus) error {
+	if _, ok := statusTransitions[to]; !ok {
+		return fmt.Errorf("%w %q", ErrInvalidStatus, to)
+	}
+	if !from.CanTransitionTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// StatusChange is one entry in a shipment's timeline. FromStatus is empty for
+// the entry recorded when the shipment is created.
+type StatusChange struct {
+	ID            string         `json:"id" db:"id"`
+	ShipmentID    string         `json:"shipmentId" db:"shipment_id"`
+	FromStatus    ShipmentStatus `json:"fromStatus,omitempty" db:"from_status"`
+	ToStatus      ShipmentStatus `json:"toStatus" db:"to_status"`
+	Source        string         `json:"source" db:"source"`
+	Description   string         `json:"description,omitempty" db:"description"`
+	Location      string         `json:"location,omitempty" db:"location"`
+	EventID       string         `json:"eventId,omitempty" db:"event_id"`
+	CarrierStatus string         `json:"carrierStatus,omitempty" db:"carrier_status"`
+	OccurredAt    time.Time      `json:"occurredAt" db:"occurred_at"`
+	CreatedAt     time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// InitialStatusChange defaults the record's status fields and returns the
+// timeline entry that is stored with a new shipment.
+func (r *ShipmentRecord) InitialStatusChange() *StatusChange {
+	if r.Status == "" {
+		r.Status = StatusCreated
+	}
+	if r.StatusUpdatedAt.IsZero() {
+		r.StatusUpdatedAt = r.CreatedAt
+	}
+	return &StatusChange{
+		ShipmentID: r.ID,
+		ToStatus:   r.Status,
+		Source:     StatusSourceAPI,
+		OccurredAt: r.StatusUpdatedAt,
+		CreatedAt:  r.CreatedAt,
+	}
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from  ShipmentStatus
+		to    ShipmentStatus
+		valid bool
+	}{
+		{StatusCreated, StatusLabelGenerated, true},
+		{StatusCreated, StatusCancelled, true},
+		{StatusInTransit, StatusInTransit, true},
+		{StatusInTransit, StatusDelivered, true},
+		{StatusException, StatusInTransit, true},
+		{StatusCreated, StatusDelivered, false},
+		{StatusInTransit, StatusCancelled, false},
+		{StatusDelivered, StatusInTransit, false},
+		{StatusDelivered, StatusDelivered, false},
+		{StatusCancelled, StatusCreated, false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateTransition(tt.from, tt.to)
+		if tt.valid && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.from, tt.to, err)
+		}
+		if !tt.valid {
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Errorf("%s -> %s: expected TransitionError, got %v", tt.from, tt.to, err)
+			}
+		}
+	}
+}
+
+func TestValidateTransition_UnknownStatus(t *testing.T) {
+	if err := ValidateTransition(StatusCreated, "lost"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("expected ErrInvalidStatus, got %v", err)
+	}
+	if _, err := ParseShipmentStatus("lost"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("expected ErrInvalidStatus from parse, got %v", err)
+	}
+}
+
+func TestShipmentStatus_IsTerminal(t *testing.T) {
+	for _, status := range []ShipmentStatus{StatusDelivered, StatusReturned, StatusCancelled} {
+		if !status.IsTerminal() {
+			t.Errorf("expected %s to be terminal", status)
+		}
+	}
+	for _, status := range []ShipmentStatus{StatusCreated, StatusInTransit, StatusException} {
+		if status.IsTerminal() {
+			t.Errorf("expected %s not to be terminal", status)
+		}
+	}
+}
+
+func TestShipmentStatuses_CoversTransitions(t *testing.T) {
+	if len(ShipmentStatuses) != len(statusTransitions) {
+		t.Fatalf("expected %d statuses, got %d", len(statusTransitions), len(ShipmentStatuses))
+	}
+	for _, status := range ShipmentStatuses {
+		if _, err := ParseShipmentStatus(string(status)); err != nil {
+			t.Errorf("unexpected error for %s: %v", status, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultTenantID owns data created before tenants existed and keys issued
+// without a tenant. Its admin keys manage keys for every tenant.
+const DefaultTenantID = "default"
+
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// Tenant is a business unit sharing the deployment. Empty Providers enables
+// every registered provider.
+type Tenant struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Providers   []string               `json:"providers"`
+	Credentials map[string]AccountInfo `json:"credentials"`
+	Routing     []RoutingRule          `json:"routing"`
+	RateLimit   RateLimit              `json:"rateLimit"`
+}
+
+// RoutingRule sends broadcasts to destinations in Countries only to
+// Providers. A rule without countries matches every destination.
+type RoutingRule struct {
+	Countries []string `json:"countries"`
+	Providers []string `json:"providers"`
+}
+
+// RateLimit caps requests per second with a burst allowance, and the
+// shipments booked per UTC day. Zero means unlimited.
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+	DailyShipments    int     `json:"dailyShipments"`
+}
+
+func (t *Tenant) Validate() error {
+	if t.ID == "" {
+		return errors.New("id is required")
+	}
+	for i, rule := range t.Routing {
+		if len(rule.Providers) == 0 {
+			return fmt.Errorf("routing rule %d has no providers", i)
+		}
+		for _, provider := range rule.Providers {
+			if !t.ProviderEnabled(provider) {
+				return fmt.Errorf("routing rule %d uses disabled provider %q", i, provider)
+			}
+		}
+	}
+	if t.RateLimit.RequestsPerSecond < 0 || t.RateLimit.Burst < 0 || t.RateLimit.DailyShipments < 0 {
+		return errors.New("rate limit must not be negative")
+	}
+	return nil
+}
+
+func (t *Tenant) ProviderEnabled(provider string) bool {
+	if len(t.Providers) == 0 {
+		return true
+	}
+	for _, enabled := range t.Providers {
+		if enabled == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Route returns the providers of the first routing rule matching the
+// request's destination, or nil when no rule applies.
+func (t *Tenant) Route(request *GenericShippingRequest) []string {
+	country := strings.ToUpper(request.Consignee.Address.CountryCode)
+	for _, rule := range t.Routing {
+		if len(rule.Countries) == 0 {
+			return rule.Providers
+		}
+		for _, candidate := range rule.Countries {
+			if strings.EqualFold(candidate, country) {
+				return rule.Providers
+			}
+		}
+	}
+	return nil
+}
+
+// WithCredentials returns a copy of request carrying the tenant's account
+// for provider, or request itself when the tenant has none configured.
+func (t *Tenant) WithCredentials(request *GenericShippingRequest, provider string) *GenericShippingRequest {
+	account, ok := t.Credentials[provider]
+	if !ok {
+		return request
+	}
+	copied := *request
+	copied.Account = account
+	return &copied
+}
@@ -0,0 +1,98 @@
+package domain
+
+import "testing"
+
+func TestTenant_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenant  Tenant
+		wantErr bool
+	}{
+		{"minimal", Tenant{ID: "acme"}, false},
+		{"missing id", Tenant{}, true},
+		{"rule without providers", Tenant{ID: "acme", Routing: []RoutingRule{{Countries: []string{"AE"}}}}, true},
+		{"rule uses disabled provider", Tenant{ID: "acme", Providers: []string{"A"}, Routing: []RoutingRule{{Providers: []string{"B"}}}}, true},
+		{"negative rate limit", Tenant{ID: "acme", RateLimit: RateLimit{RequestsPerSecond: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.tenant.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestTenant_ProviderEnabled(t *testing.T) {
+	all := Tenant{ID: "acme"}
+	if !all.ProviderEnabled("A") {
+		t.Error("expected every provider enabled without a list")
+	}
+
+	some := Tenant{ID: "acme", Providers: []string{"A"}}
+	if !some.ProviderEnabled("A") || some.ProviderEnabled("B") {
+		t.Error("expected only provider A enabled")
+	}
+}
+
+func TestTenant_Route(t *testing.T) {
+	tenant := Tenant{
+		ID: "acme",
+		Routing: []RoutingRule{
+			{Countries: []string{"in", "LK"}, Providers: []string{"A"}},
+			{Providers: []string{"A", "B"}},
+		},
+	}
+
+	request := &GenericShippingRequest{}
+	request.Consignee.Address.CountryCode = "IN"
+	if got := tenant.Route(request); len(got) != 1 || got[0] != "A" {
+		t.Errorf("expected IN routed to A, got %v", got)
+	}
+
+	request.Consignee.Address.CountryCode = "GB"
+	if got := tenant.Route(request); len(got) != 2 {
+		t.Errorf("expected GB to fall through to the catch-all rule, got %v", got)
+	}
+
+	if got := (&Tenant{ID: "acme"}).Route(request); got != nil {
+		t.Errorf("expected no routing without rules, got %v", got)
+	}
+}
+
+func TestTenant_WithCredentials(t *testing.T) {
+	tenant := Tenant{ID: "acme", Credentials: map[string]AccountInfo{"A": {Number: "acme-1"}}}
+	request := &GenericShippingRequest{Account: AccountInfo{Number: "client"}}
+
+	withAccount := tenant.WithCredentials(request, "A")
+	if withAccount.Account.Number != "acme-1" {
+		t.Errorf("expected tenant account, got %q", withAccount.Account.Number)
+	}
+	if request.Account.Number != "client" {
+		t.Error("expected the original request to be left unchanged")
+	}
+	if tenant.WithCredentials(request, "B") != request {
+		t.Error("expected the request unchanged for a provider without credentials")
+	}
+}
+
+func TestWebhookSubscription_Receives(t *testing.T) {
+	event := &ShipmentEvent{Type: EventShipmentCreated, TenantID: "acme"}
+
+	tests := []struct {
+		name         string
+		subscription WebhookSubscription
+		event        *ShipmentEvent
+		want         bool
+	}{
+		{"same tenant", WebhookSubscription{TenantID: "acme", Active: true, EventTypes: []string{EventShipmentCreated}}, event, true},
+		{"other tenant", WebhookSubscription{TenantID: "other", Active: true, EventTypes: []string{EventShipmentCreated}}, event, false},
+		{"unset tenants are default", WebhookSubscription{Active: true, EventTypes: []string{EventShipmentCreated}}, &ShipmentEvent{Type: EventShipmentCreated, TenantID: DefaultTenantID}, true},
+		{"other event type", WebhookSubscription{TenantID: "acme", Active: true, EventTypes: []string{EventStatusChanged}}, event, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.subscription.Receives(tt.event); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
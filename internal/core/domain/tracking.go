@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateStatusChange = errors.New("status change already recorded")
+
+var ErrWebhookSignature = errors.New("invalid webhook signature")
+
+var ErrWebhookNotSupported = errors.New("provider does not accept webhooks")
+
+var ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+
+const (
+	TrackingResultApplied   = "applied"
+	TrackingResultDuplicate = "duplicate"
+	TrackingResultUnmatched = "unmatched"
+	TrackingResultIgnored   = "ignored"
+)
+
+// TrackingEvent is a carrier status event normalized to a canonical status.
+// Status is empty when the carrier code has no mapping.
+type TrackingEvent struct {
+	Provider      string
+	EventID       string
+	TrackingID    string
+	AWB           string
+	Status        ShipmentStatus
+	CarrierStatus string
+	Description   string
+	Location      string
+	OccurredAt    time.Time
+}
+
+// Key identifies the event for de-duplication. Carriers that do not send an
+// event ID get a hash of the fields that make the event unique.
+func (e *TrackingEvent) Key() string {
+	if e.EventID != "" {
+		return e.EventID
+	}
+	parts := []string{e.Provider, e.TrackingID, e.AWB, e.CarrierStatus, e.Location, e.OccurredAt.UTC().Format(time.RFC3339Nano)}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+type TrackingEventResult struct {
+	EventID    string         `json:"eventId"`
+	ShipmentID string         `json:"shipmentId,omitempty"`
+	Status     ShipmentStatus `json:"status,omitempty"`
+	Result     string         `json:"result"`
+	Detail     string         `json:"detail,omitempty"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "strings"
+
+// WeightUnits and DimensionUnits are the documented unit names. The
+// conversions below also accept common abbreviations in any case.
+var WeightUnits = []string{"Grams", "Kilograms", "Pounds"}
+
+var DimensionUnits = []string{"Centimeters", "Meter", "Millimeters", "Inches"}
+
+// Kilograms converts the weight to kilograms. Unknown units are treated as
+// grams, which is what the generic payload uses by default.
+func (w WeightInfo) Kilograms() float64 {
+	switch strings.ToLower(w.Unit) {
+	case "kg", "kgs", "kilogram", "kilograms":
+		return w.Value
+	case "lb", "lbs", "pound", "pounds":
+		return w.Value * 0.45359237
+	default:
+		return w.Value / 1000
+	}
+}
+
+// Centimeters returns length, width and height in centimeters. Unknown units
+// are treated as centimeters.
+func (d Dimensions) Centimeters() (length, width, height float64) {
+	factor := 1.0
+	switch strings.ToLower(d.Unit) {
+	case "m", "meter", "meters", "metre", "metres":
+		factor = 100
+	case "mm", "millimeter", "millimeters":
+		factor = 0.1
+	case "in", "inch", "inches":
+		factor = 2.54
+	}
+	return d.Length * factor, d.Width * factor, d.Height * factor
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Usage metrics counted per tenant.
+const (
+	UsageRequests  = "requests"
+	UsageShipments = "shipments"
+)
+
+// UsageRetention is how long daily shipment counters are kept for reporting.
+const UsageRetention = 31 * 24 * time.Hour
+
+// UsageCounter counts a tenant's use of a metric in the window starting at
+// WindowStart. Counters live in the database so every replica shares them.
+type UsageCounter struct {
+	TenantID    string    `db:"tenant_id"`
+	Metric      string    `db:"metric"`
+	WindowStart time.Time `db:"window_start"`
+	Count       int       `db:"count"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+// LimitDecision is the outcome of counting requests or shipments against a
+// limit.
+type LimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Window    time.Duration
+	Reset     time.Time
+}
+
+// QuotaError means the tenant's daily quota cannot cover the shipments a
+// request would book, so none of them were booked.
+type QuotaError struct {
+	Shipments int
+	Decision  *LimitDecision
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("daily shipment quota exceeded: %d shipments requested, %d of %d remaining", e.Shipments, e.Decision.Remaining, e.Decision.Limit)
+}
+
+// TenantUsage reports a tenant's shipment quota use on one day. A zero
+// Quota means unlimited, in which case Remaining is omitted.
+type TenantUsage struct {
+	TenantID  string `json:"tenantId"`
+	Date      string `json:"date"`
+	Shipments int    `json:"shipments"`
+	Quota     int    `json:"quota"`
+	Remaining *int   `json:"remaining,omitempty"`
+}
+
+// RequestWindow turns the rate limit into a fixed window that admits Burst
+// requests, so the average rate stays at RequestsPerSecond. Burst defaults
+// to one second's worth of requests.
+func (l RateLimit) RequestWindow() (time.Duration, int) {
+	burst := l.Burst
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(l.RequestsPerSecond)))
+	}
+	return time.Duration(float64(burst) / l.RequestsPerSecond * float64(time.Second)), burst
+}
+
+// UsageDay returns the start of the UTC day containing t, the window daily
+// quotas are counted in.
+func UsageDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimit_RequestWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  RateLimit
+		window time.Duration
+		burst  int
+	}{
+		{"burst defaults to one second", RateLimit{RequestsPerSecond: 5}, time.Second, 5},
+		{"burst stretches the window", RateLimit{RequestsPerSecond: 5, Burst: 10}, 2 * time.Second, 10},
+		{"slow rate", RateLimit{RequestsPerSecond: 0.5}, 2 * time.Second, 1},
+	}
+
+	for _, tt := range tests {
+		window, burst := tt.limit.RequestWindow()
+		if window != tt.window || burst != tt.burst {
+			t.Errorf("%s: expected %d per %v, got %d per %v", tt.name, tt.burst, tt.window, burst, window)
+		}
+	}
+}
+
+func TestUsageDay(t *testing.T) {
+	local := time.Date(2024, 3, 2, 1, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	if got := UsageDay(local); !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the UTC day 2024-03-01, got %v", got)
+	}
+}
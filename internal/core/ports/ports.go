@@ -2,22 +2,163 @@ package ports
 
 import (
 	"context"
+	"net/http"
 	"shipping-api/internal/core/domain"
+	"time"
 )
 
 type ShippingProvider interface {
+	// CreateShipment books the shipment with the carrier. The response's
+	// Exchange must hold the exact request bytes sent and the raw response
+	// body received so both can be persisted as-is.
 	CreateShipment(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error)
+	TransformRequest(request *domain.GenericShippingRequest) (*domain.TransformResult, error)
 	GetProviderName() string
 	GetEndpoint() string
+	GetCapabilities() domain.ProviderCapabilities
+}
+
+// WebhookParser is implemented by providers that push status events.
+type WebhookParser interface {
+	GetProviderName() string
+	VerifyWebhook(header http.Header, body []byte) error
+	ParseWebhook(body []byte) ([]*domain.TrackingEvent, error)
+}
+
+// TrackingProvider is implemented by providers with a tracking endpoint that
+// the poller can query.
+type TrackingProvider interface {
+	GetProviderName() string
+	GetTrackingPolicy() domain.TrackingPolicy
+	TrackShipments(ctx context.Context, shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error)
 }
 
 type ShipmentRepository interface {
 	Save(ctx context.Context, record *domain.ShipmentRecord) error
 	FindByID(ctx context.Context, id string) (*domain.ShipmentRecord, error)
 	FindByProvider(ctx context.Context, provider string, limit int) ([]*domain.ShipmentRecord, error)
+	FindByTrackingID(ctx context.Context, trackingID string) ([]*domain.ShipmentRecord, error)
+	FindByAWB(ctx context.Context, awb string) ([]*domain.ShipmentRecord, error)
+	FindByReference(ctx context.Context, reference string) ([]*domain.ShipmentRecord, error)
+	Search(ctx context.Context, filter domain.ShipmentFilter) (*domain.ShipmentPage, error)
+	SaveAttempt(ctx context.Context, attempt *domain.ShipmentAttempt) error
+	FindAttemptsByRequestID(ctx context.Context, requestID string) ([]*domain.ShipmentAttempt, error)
+	FindAttemptsByShipmentID(ctx context.Context, shipmentID string) ([]*domain.ShipmentAttempt, error)
+	UpdateStatus(ctx context.Context, change *domain.StatusChange) error
+	FindStatusHistory(ctx context.Context, shipmentID string) ([]*domain.StatusChange, error)
+	ClaimShipmentsForPolling(ctx context.Context, provider string, policy domain.TrackingPolicy, lease time.Duration) ([]*domain.ShipmentRecord, func() error, error)
+	SchedulePoll(ctx context.Context, shipmentID string, nextPollAt time.Time, unchangedPolls int) error
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	FindSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeactivateSubscription(ctx context.Context, id string) error
+	// CreateDelivery is idempotent per subscription and event ID.
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt) error
+	FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error)
+	FindDeliveryAttempts(ctx context.Context, deliveryID string) ([]*domain.WebhookDeliveryAttempt, error)
+	ReplayDelivery(ctx context.Context, id string) error
+}
+
+type JobRepository interface {
+	CreateJob(ctx context.Context, job *domain.Job) error
+	ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]*domain.Job, error)
+	FailExpiredJobs(ctx context.Context, reason string) (int, error)
+	UpdateJob(ctx context.Context, job *domain.Job) error
+	FindJob(ctx context.Context, id string) (*domain.Job, error)
+	ListJobs(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error)
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	FindAPIKey(ctx context.Context, id string) (*domain.APIKey, error)
+	FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	// RotateAPIKey stores replacement and moves the old key's expiry to
+	// oldExpiresAt unless it already expires sooner.
+	RotateAPIKey(ctx context.Context, oldID string, replacement *domain.APIKey, oldExpiresAt time.Time) error
+	RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// UsageRepository keeps the usage counters shared by every replica.
+type UsageRepository interface {
+	// IncrementUsage adds amount to counter unless that would take it past
+	// limit and reports whether it did; counter.Count is set to the resulting
+	// count, or to the current count when nothing was added.
+	IncrementUsage(ctx context.Context, counter *domain.UsageCounter, amount, limit int) (bool, error)
+	ListUsage(ctx context.Context, metric string, windowStart time.Time) ([]*domain.UsageCounter, error)
+	DeleteExpiredUsage(ctx context.Context, now time.Time) (int64, error)
+}
+
+// WebhookSender posts a signed delivery to a subscriber and returns the HTTP
+// status received.
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error)
+}
+
+// OutboxRepository is read by the relay that publishes events written in the
+// same transaction as the change that produced them.
+type OutboxRepository interface {
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error)
+	MarkOutboxPublished(ctx context.Context, sequence int64, publishedAt time.Time) error
+	RescheduleOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error
+	DeadLetterOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error
+}
+
+// EventSink receives relayed shipment events. Delivery is at least once, so
+// sinks may see an event ID more than once.
+type EventSink interface {
+	Publish(ctx context.Context, event *domain.ShipmentEvent) error
+}
+
+type SubscriptionService interface {
+	CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (*domain.WebhookSubscription, error)
+}
+
+// TenantDirectory resolves the tenant an API key belongs to.
+type TenantDirectory interface {
+	Tenant(id string) (*domain.Tenant, error)
+	Tenants() []*domain.Tenant
+}
+
+// UsageService counts tenant requests and shipments against their limits.
+type UsageService interface {
+	AllowRequest(ctx context.Context, tenant *domain.Tenant) (*domain.LimitDecision, error)
+	ReserveShipments(ctx context.Context, tenant *domain.Tenant, shipments int) (*domain.LimitDecision, error)
+	Usage(ctx context.Context, tenantID string, day time.Time) ([]*domain.TenantUsage, error)
+}
+
+// APIKeyService issues and checks client credentials. Secrets are only
+// returned when a key is issued or rotated.
+type APIKeyService interface {
+	Authenticate(ctx context.Context, secret string) (*domain.APIKey, error)
+	IssueKey(ctx context.Context, tenantID, name string, scopes []string, expiresAt *time.Time) (*domain.APIKey, string, error)
+	RotateKey(ctx context.Context, id string, overlap time.Duration) (*domain.APIKey, string, error)
+	RevokeKey(ctx context.Context, id string) error
 }
 
 type ShippingService interface {
+	ValidateShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) error
 	ProcessShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.ShipmentResponse, error)
 	BroadcastShipment(ctx context.Context, request *domain.GenericShippingRequest) ([]*domain.ShipmentResponse, error)
+	StreamBroadcast(ctx context.Context, request *domain.GenericShippingRequest, emit func(*domain.ShipmentResponse)) error
+	TransformShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) ([]*domain.TransformResult, error)
+	ProcessBatch(ctx context.Context, items []*domain.BatchItem, providerName string, emit func(*domain.BatchItemResult)) error
+	Providers(ctx context.Context) []domain.ProviderInfo
+}
+
+type JobService interface {
+	SubmitShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.Job, error)
+}
+
+type TrackingService interface {
+	HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) ([]*domain.TrackingEventResult, error)
+	ApplyEvents(ctx context.Context, source string, events []*domain.TrackingEvent) ([]*domain.TrackingEventResult, error)
+	UpdateStatus(ctx context.Context, change *domain.StatusChange) error
 }
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "sk_"
+	// lastUsedResolution limits last-used writes to one per key per minute.
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyService struct {
+	repository ports.APIKeyRepository
+	tenants    ports.TenantDirectory
+}
+
+func NewAPIKeyService(repository ports.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repository: repository,
+	}
+}
+
+// SetTenantDirectory makes IssueKey reject tenants the directory does not
+// know.
+func (s *APIKeyService) SetTenantDirectory(tenants ports.TenantDirectory) {
+	s.tenants = tenants
+}
+
+// Authenticate resolves a presented secret to its key. Unknown, expired and
+// revoked keys all give ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	key, err := s.repository.FindAPIKeyByHash(ctx, domain.HashAPIKey(secret))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repository.TouchAPIKey(ctx, key.ID, now); err != nil {
+			log.Printf("failed to record use of api key %s: %v", key.ID, err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// IssueKey creates a key for tenantID, or the default tenant when empty, and
+// returns its secret, which is not stored.
+func (s *APIKeyService) IssueKey(ctx context.Context, tenantID, name string, scopes []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	if s.tenants != nil {
+		if _, err := s.tenants.Tenant(tenantID); err != nil {
+			return nil, "", fmt.Errorf("%w: unknown tenant %q", domain.ErrInvalidAPIKeyRequest, tenantID)
+		}
+	}
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", domain.ErrInvalidAPIKeyRequest)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", domain.ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range scopes {
+		if !domain.ValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiresAt must be in the future", domain.ErrInvalidAPIKeyRequest)
+	}
+
+	key, secret, err := newAPIKey(tenantID, name, scopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.repository.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// EnsureKey stores a default tenant key with a known secret unless it
+// already exists. It is used to bootstrap the first admin key from
+// configuration.
+func (s *APIKeyService) EnsureKey(ctx context.Context, name, secret string, scopes []string) error {
+	_, err := s.repository.FindAPIKeyByHash(ctx, domain.HashAPIKey(secret))
+	if err == nil || !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return err
+	}
+
+	key := &domain.APIKey{
+		ID:        uuid.New().String(),
+		TenantID:  domain.DefaultTenantID,
+		Name:      name,
+		Prefix:    keyPrefix(secret),
+		Hash:      domain.HashAPIKey(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	return s.repository.CreateAPIKey(ctx, key)
+}
+
+// RotateKey issues a replacement with the same tenant, name, scopes and
+// expiry. The old key keeps working for overlap, then expires.
+func (s *APIKeyService) RotateKey(ctx context.Context, id string, overlap time.Duration) (*domain.APIKey, string, error) {
+	if overlap < 0 {
+		return nil, "", fmt.Errorf("%w: overlap must not be negative", domain.ErrInvalidAPIKeyRequest)
+	}
+
+	old, err := s.repository.FindAPIKey(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !old.Active(time.Now()) {
+		return nil, "", domain.ErrAPIKeyInactive
+	}
+
+	replacement, secret, err := newAPIKey(old.TenantID, old.Name, old.Scopes, old.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.repository.RotateAPIKey(ctx, old.ID, replacement, replacement.CreatedAt.Add(overlap)); err != nil {
+		return nil, "", err
+	}
+	return replacement, secret, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, id string) error {
+	return s.repository.RevokeAPIKey(ctx, id, time.Now())
+}
+
+func newAPIKey(tenantID, name string, scopes []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(buf)
+
+	return &domain.APIKey{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    keyPrefix(secret),
+		Hash:      domain.HashAPIKey(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}, secret, nil
+}
+
+func keyPrefix(secret string) string {
+	if len(secret) > 11 {
+		return secret[:11]
+	}
+	return secret
+}
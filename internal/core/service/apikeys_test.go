@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyService_IssueAndAuthenticate(t *testing.T) {
+	repo := testutil.NewMockAPIKeyRepository()
+	keys := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	key, secret, err := keys.IssueKey(ctx, "", "checkout", []string{domain.ScopeShipmentsCreate}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(secret, key.Prefix) || key.Hash == secret || key.Hash != domain.HashAPIKey(secret) {
+		t.Fatalf("expected only the hash of the secret to be stored, got %+v", key)
+	}
+
+	authenticated, err := keys.Authenticate(ctx, secret)
+	if err != nil || authenticated.ID != key.ID {
+		t.Fatalf("expected key %s, got %v (%v)", key.ID, authenticated, err)
+	}
+	if authenticated.LastUsedAt == nil {
+		t.Error("expected last use to be recorded")
+	}
+
+	keys.Authenticate(ctx, secret)
+	if repo.Touches() != 1 {
+		t.Errorf("expected one last-used write within a minute, got %d", repo.Touches())
+	}
+
+	if _, err := keys.Authenticate(ctx, "sk_unknown"); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey, got %v", err)
+	}
+}
+
+func TestAPIKeyService_IssueValidation(t *testing.T) {
+	keys := NewAPIKeyService(testutil.NewMockAPIKeyRepository())
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+	}{
+		{"missing name", "", []string{domain.ScopeAdmin}, nil},
+		{"no scopes", "ci", nil, nil},
+		{"unknown scope", "ci", []string{"shipments:delete"}, nil},
+		{"expiry in the past", "ci", []string{domain.ScopeAdmin}, &past},
+	}
+
+	for _, tt := range tests {
+		_, _, err := keys.IssueKey(context.Background(), "", tt.keyName, tt.scopes, tt.expiresAt)
+		if !errors.Is(err, domain.ErrInvalidAPIKeyRequest) {
+			t.Errorf("%s: expected ErrInvalidAPIKeyRequest, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestAPIKeyService_RotateKeepsOldKeyDuringOverlap(t *testing.T) {
+	repo := testutil.NewMockAPIKeyRepository()
+	keys := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	old, oldSecret, _ := keys.IssueKey(ctx, "", "checkout", []string{domain.ScopeShipmentsCreate, domain.ScopeShipmentsRead}, nil)
+
+	replacement, newSecret, err := keys.RotateKey(ctx, old.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replacement.Name != old.Name || len(replacement.Scopes) != 2 || newSecret == oldSecret {
+		t.Fatalf("expected replacement with the same name and scopes, got %+v", replacement)
+	}
+
+	for _, secret := range []string{oldSecret, newSecret} {
+		if _, err := keys.Authenticate(ctx, secret); err != nil {
+			t.Errorf("expected both keys to work during the overlap, got %v", err)
+		}
+	}
+
+	stored, _ := repo.FindAPIKey(ctx, old.ID)
+	if stored.ReplacedBy != replacement.ID || stored.ExpiresAt == nil {
+		t.Fatalf("expected old key to expire and point at its replacement, got %+v", stored)
+	}
+
+	repo.ExpireAPIKey(old.ID)
+	if _, err := keys.Authenticate(ctx, oldSecret); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("expected old key to stop working after the overlap, got %v", err)
+	}
+	if _, _, err := keys.RotateKey(ctx, old.ID, time.Hour); !errors.Is(err, domain.ErrAPIKeyInactive) {
+		t.Errorf("expected expired key rotation to fail with ErrAPIKeyInactive, got %v", err)
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	keys := NewAPIKeyService(testutil.NewMockAPIKeyRepository())
+	ctx := context.Background()
+
+	key, secret, _ := keys.IssueKey(ctx, "", "partner", []string{domain.ScopeShipmentsRead}, nil)
+	if err := keys.RevokeKey(ctx, key.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := keys.Authenticate(ctx, secret); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+	if _, _, err := keys.RotateKey(ctx, key.ID, 0); !errors.Is(err, domain.ErrAPIKeyInactive) {
+		t.Errorf("expected ErrAPIKeyInactive, got %v", err)
+	}
+	if err := keys.RevokeKey(ctx, "missing"); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+}
+
+func TestAPIKeyService_EnsureKeyIsIdempotent(t *testing.T) {
+	repo := testutil.NewMockAPIKeyRepository()
+	keys := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := keys.EnsureKey(ctx, "bootstrap", "sk_bootstrap", []string{domain.ScopeAdmin}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	stored, _ := repo.ListAPIKeys(ctx)
+	if len(stored) != 1 {
+		t.Fatalf("expected a single bootstrap key, got %d", len(stored))
+	}
+	if key, err := keys.Authenticate(ctx, "sk_bootstrap"); err != nil || !key.HasScope(domain.ScopeAdmin) {
+		t.Errorf("expected bootstrap key to authenticate as admin, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sync"
+)
+
+const defaultBatchConcurrency = 8
+
+func (s *ShippingService) SetBatchConcurrency(concurrency int) {
+	if concurrency > 0 {
+		s.batchConcurrency = concurrency
+	}
+}
+
+// ProcessBatch books each item with at most the configured number of items
+// in flight and calls emit with each result as it completes. emit is never
+// called concurrently. A failed item is reported through emit and does not
+// stop the batch; items not yet started when ctx is cancelled are skipped.
+// Each item gets its own request ID, derived from the batch's. The shipments
+// of the whole batch are counted against the tenant's quota up front; when
+// they do not fit it returns a QuotaError before emitting anything.
+func (s *ShippingService) ProcessBatch(ctx context.Context, items []*domain.BatchItem, providerName string, emit func(*domain.BatchItemResult)) error {
+	ctx, batchID := ensureRequestID(ctx)
+
+	var provider ports.ShippingProvider
+	var providerErr error
+	if providerName != "" {
+		provider, providerErr = s.provider(ctx, providerName)
+	}
+	if err := s.reserveShipments(ctx, s.batchShipments(ctx, items, providerName, provider)); err != nil {
+		return err
+	}
+
+	concurrency := s.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	var mu sync.Mutex
+	report := func(result *domain.BatchItemResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		emit(result)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, item := range items {
+		if item.Err != nil {
+			report(&domain.BatchItemResult{Index: item.Index, Provider: providerName, Errors: []string{item.Err.Error()}})
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(item *domain.BatchItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			itemCtx := domain.WithRequestID(ctx, fmt.Sprintf("%s-%d", batchID, item.Index))
+			for _, result := range s.processBatchItem(itemCtx, item, providerName, provider, providerErr) {
+				report(result)
+			}
+		}(item)
+	}
+	wg.Wait()
+	return nil
+}
+
+// batchShipments counts the shipments a batch would book: one for each valid
+// item the provider can take, or one per eligible provider when broadcasting.
+func (s *ShippingService) batchShipments(ctx context.Context, items []*domain.BatchItem, providerName string, provider ports.ShippingProvider) int {
+	shipments := 0
+	for _, item := range items {
+		switch {
+		case item.Err != nil:
+		case providerName == "":
+			shipments += countEligible(s.broadcastProviders(ctx, item.Request), item.Request)
+		case provider != nil && checkEligibility(provider, item.Request) == nil:
+			shipments++
+		}
+	}
+	return shipments
+}
+
+func (s *ShippingService) processBatchItem(ctx context.Context, item *domain.BatchItem, providerName string, provider ports.ShippingProvider, providerErr error) []*domain.BatchItemResult {
+	requestID := domain.RequestIDFromContext(ctx)
+
+	var responses []*domain.ShipmentResponse
+	if providerName == "" {
+		s.broadcast(ctx, item.Request, s.broadcastProviders(ctx, item.Request), func(response *domain.ShipmentResponse) {
+			responses = append(responses, response)
+		})
+	} else {
+		err := providerErr
+		if err == nil {
+			err = checkEligibility(provider, item.Request)
+		}
+		var response *domain.ShipmentResponse
+		if err == nil {
+			response, err = s.bookShipment(ctx, provider, item.Request)
+		}
+		if response == nil {
+			return []*domain.BatchItemResult{{
+				Index:     item.Index,
+				Provider:  providerName,
+				RequestID: requestID,
+				Errors:    []string{err.Error()},
+			}}
+		}
+		if err != nil {
+			response.Success = false
+			response.Message = err.Error()
+		}
+		responses = []*domain.ShipmentResponse{response}
+	}
+
+	results := make([]*domain.BatchItemResult, 0, len(responses))
+	for _, response := range responses {
+		result := &domain.BatchItemResult{
+			Index:      item.Index,
+			Provider:   response.Provider,
+			Success:    response.Success,
+			TrackingID: response.TrackingID,
+			AWB:        response.AWB,
+			RequestID:  requestID,
+		}
+		if !response.Success {
+			message := response.Message
+			if message == "" {
+				message = "provider did not accept the shipment"
+			}
+			result.Errors = []string{message}
+		}
+		results = append(results, result)
+	}
+	return results
+}
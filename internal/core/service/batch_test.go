@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShippingService_ProcessBatch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	provider := testutil.NewMockShippingProvider("A", "http://a.test")
+	provider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if request.ProductCode == "fail" {
+			return nil, errors.New("carrier rejected")
+		}
+		return &domain.ShipmentResponse{Provider: "A", Success: true, TrackingID: "T-" + request.Remarks}, nil
+	})
+
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+	service.RegisterProvider(provider)
+	service.SetBatchConcurrency(3)
+
+	var items []*domain.BatchItem
+	for i := 0; i < 10; i++ {
+		request := testutil.CreateSampleShippingRequest()
+		request.Remarks = string(rune('a' + i))
+		if i == 4 {
+			request.ProductCode = "fail"
+		}
+		items = append(items, &domain.BatchItem{Index: i, Request: request})
+	}
+	items = append(items, &domain.BatchItem{Index: 10, Err: errors.New("invalid request")})
+
+	var mu sync.Mutex
+	results := make(map[int]*domain.BatchItemResult)
+	ctx := domain.WithRequestID(context.Background(), "batch-1")
+	service.ProcessBatch(ctx, items, "A", func(result *domain.BatchItemResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[result.Index] = result
+	})
+
+	if len(results) != 11 {
+		t.Fatalf("expected a result per item, got %d", len(results))
+	}
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 items in flight, got %d", maxInFlight)
+	}
+	if results[4].Success || len(results[4].Errors) == 0 {
+		t.Errorf("expected item 4 to fail, got %+v", results[4])
+	}
+	if !results[5].Success || results[5].TrackingID != "T-f" || results[5].RequestID != "batch-1-5" {
+		t.Errorf("expected item 5 to succeed with its own request ID, got %+v", results[5])
+	}
+	if results[10].Success || !strings.Contains(results[10].Errors[0], "invalid request") {
+		t.Errorf("expected decode error to be reported for item 10, got %+v", results[10])
+	}
+
+	attempts, _ := mockRepo.FindAttemptsByRequestID(context.Background(), "batch-1-4")
+	if len(attempts) != 1 {
+		t.Errorf("expected the failed item's attempt under its request ID, got %d", len(attempts))
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveryBatchSize = 50
+	// deliveryTimeout bounds each send. A batch is sent in order, one
+	// delivery after another, so its claim is leased for every send timing
+	// out plus a margin for recording the attempts.
+	deliveryTimeout      = 10 * time.Second
+	defaultDeliveryLease = defaultDeliveryBatchSize*deliveryTimeout + time.Minute
+)
+
+type WebhookDispatcher struct {
+	repository ports.WebhookRepository
+	sender     ports.WebhookSender
+	policy     domain.RetryPolicy
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+}
+
+func NewWebhookDispatcher(repository ports.WebhookRepository, sender ports.WebhookSender, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repository: repository,
+		sender:     sender,
+		policy:     domain.DefaultRetryPolicy,
+		interval:   interval,
+		batchSize:  defaultDeliveryBatchSize,
+		lease:      defaultDeliveryLease,
+	}
+}
+
+func (d *WebhookDispatcher) SetRetryPolicy(policy domain.RetryPolicy) {
+	d.policy = policy
+}
+
+// CreateSubscription validates and stores a subscription. A secret is
+// generated when none is given.
+func (d *WebhookDispatcher) CreateSubscription(ctx context.Context, target string, eventTypes []string, secret string) (*domain.WebhookSubscription, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidSubscription)
+	}
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", domain.ErrInvalidSubscription)
+	}
+	for _, eventType := range eventTypes {
+		if !isEventType(eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidSubscription, eventType)
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	subscription := &domain.WebhookSubscription{
+		ID:         uuid.New().String(),
+		URL:        target,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}
+	if err := d.repository.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Publish queues one delivery per active subscription of the event's tenant
+// to the event type.
+func (d *WebhookDispatcher) Publish(ctx context.Context, event *domain.ShipmentEvent) error {
+	subscriptions, err := d.repository.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	now := time.Now()
+	for _, subscription := range subscriptions {
+		if !subscription.Receives(event) {
+			continue
+		}
+		delivery := &domain.WebhookDelivery{
+			ID:             uuid.New().String(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         domain.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := d.repository.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			sent, err := d.DispatchDue(context.WithoutCancel(ctx))
+			if err != nil {
+				log.Printf("webhook dispatch failed: %v", err)
+				break
+			}
+			if sent < d.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends one batch of due deliveries and returns how many were
+// attempted.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := d.repository.ClaimDueDeliveries(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[string]*domain.WebhookSubscription)
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = d.repository.FindSubscription(ctx, delivery.SubscriptionID)
+			if err != nil {
+				return 0, err
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+		d.deliver(ctx, subscription, delivery)
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) {
+	start := time.Now()
+	var statusCode int
+	var sendErr error
+	if subscription.Active {
+		sendCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+		statusCode, sendErr = d.sender.Send(sendCtx, subscription, delivery)
+		cancel()
+	} else {
+		sendErr = fmt.Errorf("subscription %s is inactive", subscription.ID)
+	}
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	delivery.UpdatedAt = now
+
+	attempt := &domain.WebhookDeliveryAttempt{
+		ID:            uuid.New().String(),
+		DeliveryID:    delivery.ID,
+		AttemptNumber: delivery.Attempts,
+		StatusCode:    statusCode,
+		DurationMs:    now.Sub(start).Milliseconds(),
+		CreatedAt:     now,
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.DeliveryDelivered
+	case !subscription.Active || delivery.Attempts >= d.policy.MaxAttempts:
+		delivery.Status = domain.DeliveryDead
+	default:
+		delivery.NextAttemptAt = now.Add(d.policy.Delay(delivery.Attempts))
+	}
+	if sendErr != nil {
+		delivery.LastError = sendErr.Error()
+		attempt.Error = sendErr.Error()
+	}
+
+	if err := d.repository.RecordDeliveryAttempt(ctx, delivery, attempt); err != nil {
+		log.Printf("failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func isEventType(eventType string) bool {
+	for _, known := range domain.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookDispatcher_CreateSubscription_Validation(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(testutil.NewMockWebhookRepository(), testutil.NewMockWebhookSender(200, nil), time.Second)
+
+	tests := []struct {
+		name   string
+		url    string
+		events []string
+	}{
+		{"relative url", "/hooks", []string{domain.EventShipmentCreated}},
+		{"no events", "https://orders.test/hooks", nil},
+		{"unknown event", "https://orders.test/hooks", []string{"shipment.lost"}},
+	}
+	for _, tt := range tests {
+		if _, err := dispatcher.CreateSubscription(context.Background(), tt.url, tt.events, ""); !errors.Is(err, domain.ErrInvalidSubscription) {
+			t.Errorf("%s: expected ErrInvalidSubscription, got %v", tt.name, err)
+		}
+	}
+
+	subscription, err := dispatcher.CreateSubscription(context.Background(), "https://orders.test/hooks", []string{domain.EventShipmentCreated}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subscription.Secret) != 64 {
+		t.Errorf("expected a generated 32-byte hex secret, got %q", subscription.Secret)
+	}
+}
+
+func TestWebhookDispatcher_PublishAndDeliver(t *testing.T) {
+	repo := testutil.NewMockWebhookRepository()
+	sender := testutil.NewMockWebhookSender(http.StatusOK, nil)
+	dispatcher := NewWebhookDispatcher(repo, sender, time.Second)
+
+	dispatcher.CreateSubscription(context.Background(), "https://orders.test/created", []string{domain.EventShipmentCreated}, "s1")
+	dispatcher.CreateSubscription(context.Background(), "https://orders.test/status", []string{domain.EventStatusChanged}, "s2")
+
+	event, _ := domain.NewShipmentEvent(domain.EventShipmentCreated, "shipment-1", time.Now(), domain.ShipmentEventData{Provider: "A"})
+	event.ID = "event-1"
+	if err := dispatcher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dispatcher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent, err := dispatcher.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 1 || sender.GetSentCount() != 1 {
+		t.Fatalf("expected one delivery to the matching subscription, got %d", sent)
+	}
+
+	deliveries, _ := repo.FindDeliveries(context.Background(), domain.DeliveryFilter{Status: domain.DeliveryDelivered})
+	if len(deliveries) != 1 || !strings.Contains(string(deliveries[0].Payload), `"shipment.created"`) {
+		t.Errorf("expected delivered shipment.created payload, got %+v", deliveries)
+	}
+}
+
+func TestWebhookDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	repo := testutil.NewMockWebhookRepository()
+	sender := testutil.NewMockWebhookSender(http.StatusInternalServerError, errors.New("subscriber responded with status 500"))
+	dispatcher := NewWebhookDispatcher(repo, sender, time.Second)
+	dispatcher.SetRetryPolicy(domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+
+	dispatcher.CreateSubscription(context.Background(), "https://orders.test/hooks", []string{domain.EventShipmentFailed}, "secret")
+	event, _ := domain.NewShipmentEvent(domain.EventShipmentFailed, "", time.Now(), domain.ShipmentEventData{Provider: "A"})
+	event.ID = "event-1"
+	dispatcher.Publish(context.Background(), event)
+
+	var delivery *domain.WebhookDelivery
+	for i := 1; i <= 3; i++ {
+		if sent, _ := dispatcher.DispatchDue(context.Background()); sent != 1 {
+			t.Fatalf("attempt %d: expected one delivery, got %d", i, sent)
+		}
+		deliveries, _ := repo.FindDeliveries(context.Background(), domain.DeliveryFilter{})
+		delivery = deliveries[0]
+		if i < 3 {
+			if delivery.Status != domain.DeliveryPending || !delivery.NextAttemptAt.After(time.Now()) {
+				t.Fatalf("attempt %d: expected pending delivery scheduled in the future, got %s", i, delivery.Status)
+			}
+			delivery.NextAttemptAt = time.Now()
+		}
+	}
+
+	if delivery.Status != domain.DeliveryDead || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Errorf("expected dead delivery with last status 500, got %s/%d", delivery.Status, delivery.LastStatusCode)
+	}
+	history, _ := repo.FindDeliveryAttempts(context.Background(), delivery.ID)
+	if len(history) != 3 {
+		t.Errorf("expected 3 logged attempts, got %d", len(history))
+	}
+
+	if err := repo.ReplayDelivery(context.Background(), delivery.ID); err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+	if sent, _ := dispatcher.DispatchDue(context.Background()); sent != 1 {
+		t.Errorf("expected replayed delivery to be sent again")
+	}
+}
+
+type deadlineSender struct {
+	deadlines []time.Duration
+}
+
+func (s *deadlineSender) Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadlines = append(s.deadlines, time.Until(deadline))
+	return http.StatusOK, nil
+}
+
+func TestWebhookDispatcher_LeaseCoversBatch(t *testing.T) {
+	repo := testutil.NewMockWebhookRepository()
+	sender := &deadlineSender{}
+	dispatcher := NewWebhookDispatcher(repo, sender, time.Second)
+
+	dispatcher.CreateSubscription(context.Background(), "https://orders.test/created", []string{domain.EventShipmentCreated}, "s1")
+	for i := 0; i < 3; i++ {
+		dispatcher.Publish(context.Background(), &domain.ShipmentEvent{ID: fmt.Sprintf("evt-%d", i), Type: domain.EventShipmentCreated})
+	}
+
+	if _, err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sender.deadlines) != 3 {
+		t.Fatalf("expected 3 sends, got %d", len(sender.deadlines))
+	}
+	for _, remaining := range sender.deadlines {
+		if remaining <= 0 || remaining > deliveryTimeout {
+			t.Errorf("expected each send bounded by %v, got %v", deliveryTimeout, remaining)
+		}
+	}
+	if worst := time.Duration(defaultDeliveryBatchSize) * deliveryTimeout; dispatcher.lease <= worst {
+		t.Errorf("expected the lease to outlast a batch of timed out sends (%v), got %v", worst, dispatcher.lease)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultJobConcurrency = 4
+	defaultJobLease       = 5 * time.Minute
+	interruptedJobError   = "job was interrupted while running; the shipment may have been booked"
+)
+
+// JobRunner queues shipment requests in the job repository and processes
+// them through the shipping service in the background.
+type JobRunner struct {
+	repository  ports.JobRepository
+	shipping    ports.ShippingService
+	policy      domain.RetryPolicy
+	interval    time.Duration
+	concurrency int
+	lease       time.Duration
+	tenants     ports.TenantDirectory
+}
+
+func NewJobRunner(repository ports.JobRepository, shipping ports.ShippingService, interval time.Duration) *JobRunner {
+	return &JobRunner{
+		repository:  repository,
+		shipping:    shipping,
+		policy:      domain.DefaultJobRetryPolicy,
+		interval:    interval,
+		concurrency: defaultJobConcurrency,
+		lease:       defaultJobLease,
+	}
+}
+
+func (j *JobRunner) SetRetryPolicy(policy domain.RetryPolicy) {
+	j.policy = policy
+}
+
+func (j *JobRunner) SetConcurrency(concurrency int) {
+	if concurrency > 0 {
+		j.concurrency = concurrency
+	}
+}
+
+// SetTenantDirectory lets jobs run with their tenant's providers, carrier
+// credentials and routing. Without it jobs run with only the tenant ID.
+func (j *JobRunner) SetTenantDirectory(tenants ports.TenantDirectory) {
+	j.tenants = tenants
+}
+
+// SubmitShipment checks the request against the provider like a synchronous
+// call would, then queues it and returns the job. The job reuses the request
+// ID from ctx so its provider attempts can be looked up by it.
+func (j *JobRunner) SubmitShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.Job, error) {
+	if err := j.shipping.ValidateShipment(ctx, request, providerName); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	_, requestID := ensureRequestID(ctx)
+	now := time.Now()
+	job := &domain.Job{
+		ID:          uuid.New().String(),
+		Status:      domain.JobQueued,
+		Provider:    providerName,
+		RequestID:   requestID,
+		Request:     payload,
+		MaxAttempts: j.policy.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := j.repository.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Run processes due jobs until ctx is cancelled. Jobs in flight when ctx is
+// cancelled are finished first.
+func (j *JobRunner) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := j.ProcessDue(context.WithoutCancel(ctx))
+			if err != nil {
+				log.Printf("job processing failed: %v", err)
+				break
+			}
+			if processed < j.concurrency {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue fails jobs left running by a worker that died, then claims up to
+// concurrency jobs, runs them in parallel and returns how many were claimed.
+// An interrupted job is never run again, since the carrier may already have
+// booked it.
+func (j *JobRunner) ProcessDue(ctx context.Context) (int, error) {
+	if failed, err := j.repository.FailExpiredJobs(ctx, interruptedJobError); err != nil {
+		return 0, err
+	} else if failed > 0 {
+		log.Printf("failed %d jobs interrupted while running", failed)
+	}
+
+	jobs, err := j.repository.ClaimJobs(ctx, j.concurrency, j.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *domain.Job) {
+			defer wg.Done()
+			j.process(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+
+	return len(jobs), nil
+}
+
+func (j *JobRunner) process(ctx context.Context, job *domain.Job) {
+	results, retryable, err := j.execute(ctx, job)
+
+	now := time.Now()
+	job.UpdatedAt = now
+	job.Error = ""
+	switch {
+	case err == nil:
+		job.Status = domain.JobCompleted
+		job.Results = results
+		job.CompletedAt = &now
+	case retryable && job.Attempts < job.MaxAttempts:
+		job.Status = domain.JobQueued
+		job.Error = err.Error()
+		job.RunAt = now.Add(j.policy.Delay(job.Attempts))
+	default:
+		job.Status = domain.JobFailed
+		job.Results = results
+		job.Error = err.Error()
+		job.CompletedAt = &now
+	}
+
+	if err := j.repository.UpdateJob(ctx, job); err != nil {
+		log.Printf("failed to update job %s: %v", job.ID, err)
+	}
+}
+
+// execute runs the job's request once. Broadcasts are never retried because
+// providers that already accepted the shipment would book it twice, and
+// neither is a single-provider call that may have reached the carrier.
+func (j *JobRunner) execute(ctx context.Context, job *domain.Job) (json.RawMessage, bool, error) {
+	if job.Attempts > job.MaxAttempts {
+		return nil, false, fmt.Errorf("job exceeded %d attempts", job.MaxAttempts)
+	}
+
+	var request domain.GenericShippingRequest
+	if err := json.Unmarshal(job.Request, &request); err != nil {
+		return nil, false, fmt.Errorf("invalid job request: %w", err)
+	}
+
+	tenant := &domain.Tenant{ID: job.TenantID}
+	if j.tenants != nil {
+		var err error
+		if tenant, err = j.tenants.Tenant(job.TenantID); err != nil {
+			return nil, false, err
+		}
+	}
+	ctx = domain.WithTenant(domain.WithRequestID(ctx, job.RequestID), tenant)
+
+	var output interface{}
+	var retryable bool
+	var err error
+	if job.Provider == "" {
+		output, err = j.shipping.BroadcastShipment(ctx, &request)
+	} else {
+		var response *domain.ShipmentResponse
+		response, err = j.shipping.ProcessShipment(ctx, &request, job.Provider)
+		retryable = domain.IsRetryable(response, err)
+		if err == nil && !response.Success {
+			err = fmt.Errorf("provider %s did not accept the shipment: %s", job.Provider, response.Message)
+		}
+		if response != nil {
+			output = []*domain.ShipmentResponse{response}
+		}
+	}
+
+	if output == nil {
+		return nil, retryable, err
+	}
+	results, marshalErr := json.Marshal(output)
+	if marshalErr != nil && err == nil {
+		err = fmt.Errorf("failed to marshal job results: %w", marshalErr)
+	}
+	return results, retryable, err
+}
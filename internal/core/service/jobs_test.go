@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestJobRunner(provider *testutil.MockShippingProvider) (*JobRunner, *testutil.MockJobRepository, *testutil.MockRepository) {
+	shipmentRepo := testutil.NewMockRepository()
+	shipping := NewShippingService(shipmentRepo)
+	shipping.RegisterProvider(provider)
+
+	jobRepo := testutil.NewMockJobRepository()
+	runner := NewJobRunner(jobRepo, shipping, time.Second)
+	runner.SetRetryPolicy(domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+	return runner, jobRepo, shipmentRepo
+}
+
+func TestJobRunner_CompletesJob(t *testing.T) {
+	runner, jobRepo, shipmentRepo := newTestJobRunner(testutil.NewMockShippingProvider("A", "http://a.test"))
+
+	ctx := domain.WithRequestID(context.Background(), "req-async")
+	job, err := runner.SubmitShipment(ctx, testutil.CreateSampleShippingRequest(), "A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != domain.JobQueued || job.RequestID != "req-async" {
+		t.Fatalf("expected queued job with the caller's request ID, got %+v", job)
+	}
+
+	if processed, err := runner.ProcessDue(context.Background()); err != nil || processed != 1 {
+		t.Fatalf("expected one processed job, got %d (%v)", processed, err)
+	}
+
+	found, _ := jobRepo.FindJob(context.Background(), job.ID)
+	if found.Status != domain.JobCompleted || found.CompletedAt == nil {
+		t.Fatalf("expected completed job, got %s: %s", found.Status, found.Error)
+	}
+	var results []*domain.ShipmentResponse
+	if err := json.Unmarshal(found.Results, &results); err != nil || len(results) != 1 || results[0].TrackingID != "TRACK123" {
+		t.Errorf("expected one result with the tracking ID, got %s", found.Results)
+	}
+
+	attempts, _ := shipmentRepo.FindAttemptsByRequestID(context.Background(), "req-async")
+	if len(attempts) != 1 {
+		t.Errorf("expected the provider attempt to carry the job's request ID, got %d attempts", len(attempts))
+	}
+}
+
+func TestJobRunner_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	provider := testutil.NewMockShippingProvider("A", "http://a.test")
+	provider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return &domain.ShipmentResponse{
+				Provider: "A",
+				Message:  "unavailable",
+				Exchange: &domain.ProviderExchange{StatusCode: http.StatusServiceUnavailable},
+			}, nil
+		}
+		return &domain.ShipmentResponse{Provider: "A", Success: true, TrackingID: "TRACK-RETRY"}, nil
+	})
+	runner, jobRepo, _ := newTestJobRunner(provider)
+
+	job, _ := runner.SubmitShipment(context.Background(), testutil.CreateSampleShippingRequest(), "A")
+	runner.ProcessDue(context.Background())
+
+	found, _ := jobRepo.FindJob(context.Background(), job.ID)
+	if found.Status != domain.JobQueued || found.Error == "" || !found.RunAt.After(time.Now()) {
+		t.Fatalf("expected job requeued for a later retry, got %s at %v", found.Status, found.RunAt)
+	}
+	if processed, _ := runner.ProcessDue(context.Background()); processed != 0 {
+		t.Fatalf("expected retry not to run before its backoff")
+	}
+
+	jobRepo.MakeJobDue(job.ID)
+	runner.ProcessDue(context.Background())
+
+	found, _ = jobRepo.FindJob(context.Background(), job.ID)
+	if found.Status != domain.JobCompleted || found.Attempts != 2 || found.Error != "" {
+		t.Errorf("expected completion on the second attempt, got %s after %d attempts", found.Status, found.Attempts)
+	}
+}
+
+func TestJobRunner_FailsPermanently(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *domain.ShipmentResponse
+		err      error
+		attempts int
+	}{
+		{
+			name:     "rejected",
+			response: &domain.ShipmentResponse{Provider: "A", Message: "invalid postcode", Exchange: &domain.ProviderExchange{StatusCode: http.StatusBadRequest}},
+			attempts: 1,
+		},
+		{
+			name:     "timeout",
+			err:      &domain.ProviderError{Provider: "A", Category: domain.ErrorCategoryTimeout, Err: errors.New("deadline exceeded")},
+			attempts: 1,
+		},
+		{
+			name:     "retries exhausted",
+			response: &domain.ShipmentResponse{Provider: "A", Message: "unavailable", Exchange: &domain.ProviderExchange{StatusCode: http.StatusServiceUnavailable}},
+			attempts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := testutil.NewMockShippingProvider("A", "http://a.test")
+			provider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+				return tt.response, tt.err
+			})
+			runner, jobRepo, _ := newTestJobRunner(provider)
+
+			job, _ := runner.SubmitShipment(context.Background(), testutil.CreateSampleShippingRequest(), "A")
+			for i := 0; i < 5; i++ {
+				jobRepo.MakeJobDue(job.ID)
+				runner.ProcessDue(context.Background())
+			}
+
+			found, _ := jobRepo.FindJob(context.Background(), job.ID)
+			if found.Status != domain.JobFailed || found.Attempts != tt.attempts || found.Error == "" {
+				t.Errorf("expected failed job after %d attempts, got %s after %d", tt.attempts, found.Status, found.Attempts)
+			}
+		})
+	}
+}
+
+func TestJobRunner_SubmitShipment_ValidatesRequest(t *testing.T) {
+	provider := testutil.NewMockShippingProvider("A", "http://a.test")
+	provider.SetCapabilities(domain.ProviderCapabilities{DestinationCountries: []string{"ZZ"}})
+	runner, jobRepo, _ := newTestJobRunner(provider)
+
+	if _, err := runner.SubmitShipment(context.Background(), testutil.CreateSampleShippingRequest(), "missing"); !errors.Is(err, domain.ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+	var eligibilityErr *domain.EligibilityError
+	if _, err := runner.SubmitShipment(context.Background(), testutil.CreateSampleShippingRequest(), "A"); !errors.As(err, &eligibilityErr) {
+		t.Errorf("expected an eligibility error, got %v", err)
+	}
+	if jobs, _ := jobRepo.ListJobs(context.Background(), domain.JobFilter{}); len(jobs) != 0 {
+		t.Errorf("expected no queued jobs, got %d", len(jobs))
+	}
+}
+
+func TestJobRunner_FailsInterruptedJobs(t *testing.T) {
+	var calls int32
+	provider := testutil.NewMockShippingProvider("A", "http://a.test")
+	provider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return &domain.ShipmentResponse{Provider: "A", Success: true, TrackingID: "TRACK123"}, nil
+	})
+	runner, jobRepo, _ := newTestJobRunner(provider)
+
+	job, _ := runner.SubmitShipment(context.Background(), testutil.CreateSampleShippingRequest(), "A")
+	if claimed, _ := jobRepo.ClaimJobs(context.Background(), 1, 0); len(claimed) != 1 {
+		t.Fatalf("expected the job to be claimed")
+	}
+
+	if processed, err := runner.ProcessDue(context.Background()); err != nil || processed != 0 {
+		t.Fatalf("expected the interrupted job not to be claimed again, got %d (%v)", processed, err)
+	}
+
+	found, _ := jobRepo.FindJob(context.Background(), job.ID)
+	if found.Status != domain.JobFailed || found.Error == "" || found.CompletedAt == nil {
+		t.Errorf("expected the interrupted job to fail, got %s: %s", found.Status, found.Error)
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("expected the carrier not to be called, got %d calls", calls)
+	}
+}
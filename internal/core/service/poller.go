@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"log"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sync"
+	"time"
+)
+
+const defaultPollLease = 5 * time.Minute
+
+type TrackingPoller struct {
+	providers  []ports.TrackingProvider
+	repository ports.ShipmentRepository
+	tracking   ports.TrackingService
+	interval   time.Duration
+	lease      time.Duration
+}
+
+func NewTrackingPoller(repository ports.ShipmentRepository, tracking ports.TrackingService, interval time.Duration) *TrackingPoller {
+	return &TrackingPoller{
+		repository: repository,
+		tracking:   tracking,
+		interval:   interval,
+		lease:      defaultPollLease,
+	}
+}
+
+func (p *TrackingPoller) RegisterProvider(provider ports.TrackingProvider) {
+	p.providers = append(p.providers, provider)
+}
+
+// Run polls every registered provider until ctx is cancelled. It returns once
+// the batches in flight at cancellation have been recorded.
+func (p *TrackingPoller) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, provider := range p.providers {
+		wg.Add(1)
+		go func(provider ports.TrackingProvider) {
+			defer wg.Done()
+			p.runProvider(ctx, provider)
+		}(provider)
+	}
+	wg.Wait()
+}
+
+func (p *TrackingPoller) runProvider(ctx context.Context, provider ports.TrackingProvider) {
+	policy := provider.GetTrackingPolicy()
+	limiter := newRateLimiter(policy.RequestsPerMinute)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for limiter.wait(ctx) {
+			claimed, err := p.PollBatch(context.WithoutCancel(ctx), provider)
+			if err != nil {
+				log.Printf("tracking poll for provider %s failed: %v", provider.GetProviderName(), err)
+				break
+			}
+			if claimed < policy.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollBatch claims one batch of due shipments, fetches their tracking events
+// and reschedules each one before releasing the claim. Shipments that did not change back off according
+// to the provider's policy. It returns the number of shipments claimed.
+func (p *TrackingPoller) PollBatch(ctx context.Context, provider ports.TrackingProvider) (int, error) {
+	policy := provider.GetTrackingPolicy()
+
+	records, release, err := p.repository.ClaimShipmentsForPolling(ctx, provider.GetProviderName(), policy, p.lease)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := release(); err != nil {
+			log.Printf("tracking poll for provider %s: %v", provider.GetProviderName(), err)
+		}
+	}()
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	changed := make(map[string]bool)
+	events, err := provider.TrackShipments(ctx, records)
+	if err == nil {
+		var results []*domain.TrackingEventResult
+		results, err = p.tracking.ApplyEvents(ctx, domain.StatusSourcePoller, events)
+		for _, result := range results {
+			if result.Result == domain.TrackingResultApplied {
+				changed[result.ShipmentID] = true
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		unchanged := record.UnchangedPolls + 1
+		if changed[record.ID] {
+			unchanged = 0
+		}
+		if scheduleErr := p.repository.SchedulePoll(ctx, record.ID, policy.NextPoll(now, unchanged), unchanged); scheduleErr != nil {
+			log.Printf("failed to reschedule tracking poll for shipment %s: %v", record.ID, scheduleErr)
+		}
+	}
+
+	return len(records), err
+}
+
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	limiter := &rateLimiter{}
+	if perMinute > 0 {
+		limiter.interval = time.Minute / time.Duration(perMinute)
+	}
+	return limiter
+}
+
+// wait blocks until the next call is allowed and reports false if ctx is
+// cancelled first.
+func (l *rateLimiter) wait(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if delay := time.Until(l.next); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+		}
+	}
+
+	l.next = time.Now().Add(l.interval)
+	return true
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"testing"
+	"time"
+)
+
+var testPolicy = domain.TrackingPolicy{
+	BatchSize:       2,
+	PollInterval:    time.Minute,
+	MaxPollInterval: time.Hour,
+}
+
+func TestTrackingPoller_PollBatch(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	moving := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-1", "A-AWB-1")
+	idle := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-2", "A-AWB-2")
+	delivered := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-3", "A-AWB-3")
+	mockRepo.UpdateStatus(context.Background(), &domain.StatusChange{ShipmentID: delivered.ID, ToStatus: domain.StatusInTransit})
+	mockRepo.UpdateStatus(context.Background(), &domain.StatusChange{ShipmentID: delivered.ID, ToStatus: domain.StatusDelivered})
+
+	provider := testutil.NewMockTrackingProvider("A", testPolicy, func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+		return []*domain.TrackingEvent{{Provider: "A", EventID: "E1", TrackingID: "A-TRACK-1", Status: domain.StatusInTransit, OccurredAt: time.Now()}}, nil
+	})
+	poller := NewTrackingPoller(mockRepo, NewTrackingService(mockRepo), time.Minute)
+
+	claimed, err := poller.PollBatch(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claimed != 2 {
+		t.Fatalf("expected 2 non-terminal shipments claimed, got %d", claimed)
+	}
+
+	if moving.Status != domain.StatusInTransit || moving.UnchangedPolls != 0 {
+		t.Errorf("expected moving shipment in transit with no backoff, got %s/%d", moving.Status, moving.UnchangedPolls)
+	}
+	if idle.UnchangedPolls != 1 {
+		t.Errorf("expected idle shipment to back off, got %d unchanged polls", idle.UnchangedPolls)
+	}
+	if !idle.NextPollAt.After(moving.NextPollAt) {
+		t.Errorf("expected idle shipment to be polled later than the moving one")
+	}
+
+	history, _ := mockRepo.FindStatusHistory(context.Background(), moving.ID)
+	if last := history[len(history)-1]; last.Source != domain.StatusSourcePoller {
+		t.Errorf("expected poller source, got %s", last.Source)
+	}
+
+	claimed, err = poller.PollBatch(context.Background(), provider)
+	if err != nil || claimed != 0 {
+		t.Errorf("expected nothing due on second poll, got %d (%v)", claimed, err)
+	}
+}
+
+func TestTrackingPoller_PollBatch_ProviderError(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	record := seedTrackedShipment(t, mockRepo, "B", "", "B-AWB-1")
+
+	provider := testutil.NewMockTrackingProvider("B", testPolicy, func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+		return nil, errors.New("carrier unavailable")
+	})
+	poller := NewTrackingPoller(mockRepo, NewTrackingService(mockRepo), time.Minute)
+
+	if _, err := poller.PollBatch(context.Background(), provider); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if record.UnchangedPolls != 1 || !record.NextPollAt.After(time.Now()) {
+		t.Errorf("expected failed poll to be rescheduled with backoff, got %d unchanged, next %v", record.UnchangedPolls, record.NextPollAt)
+	}
+}
+
+func TestTrackingPoller_PollBatch_SkipsUntrackableShipments(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	awbOnly := seedTrackedShipment(t, mockRepo, "A", "", "A-AWB-1")
+	tracked := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-2", "")
+
+	policy := testPolicy
+	policy.TrackBy = domain.TrackByTrackingID
+	var polled []*domain.ShipmentRecord
+	provider := testutil.NewMockTrackingProvider("A", policy, func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+		polled = shipments
+		return nil, nil
+	})
+	poller := NewTrackingPoller(mockRepo, NewTrackingService(mockRepo), time.Minute)
+
+	if _, err := poller.PollBatch(context.Background(), provider); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(polled) != 1 || polled[0].ID != tracked.ID {
+		t.Errorf("expected only the shipment with a tracking ID to be polled, got %d shipments", len(polled))
+	}
+	if awbOnly.UnchangedPolls != 0 {
+		t.Error("expected the AWB-only shipment not to be claimed")
+	}
+}
+
+func TestTrackingPoller_RunStopsOnCancel(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	provider := testutil.NewMockTrackingProvider("A", testPolicy, func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+		return nil, nil
+	})
+	poller := NewTrackingPoller(mockRepo, NewTrackingService(mockRepo), 10*time.Millisecond)
+	poller.RegisterProvider(provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		poller.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("poller did not stop after cancellation")
+	}
+}
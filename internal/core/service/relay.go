@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"log"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+)
+
+const (
+	defaultRelayBatchSize = 100
+	defaultRelayLease     = time.Minute
+)
+
+// OutboxRelay publishes outbox events to every sink. An event is marked
+// published only after all sinks accept it; otherwise it is retried, so
+// sinks see each event at least once. Events that exhaust the retry policy
+// are dead-lettered and logged.
+type OutboxRelay struct {
+	repository ports.OutboxRepository
+	sinks      []ports.EventSink
+	policy     domain.RetryPolicy
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+}
+
+func NewOutboxRelay(repository ports.OutboxRepository, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repository: repository,
+		policy:     domain.DefaultOutboxRetryPolicy,
+		interval:   interval,
+		batchSize:  defaultRelayBatchSize,
+		lease:      defaultRelayLease,
+	}
+}
+
+func (r *OutboxRelay) AddSink(sink ports.EventSink) {
+	r.sinks = append(r.sinks, sink)
+}
+
+func (r *OutboxRelay) SetRetryPolicy(policy domain.RetryPolicy) {
+	r.policy = policy
+}
+
+// Run relays pending events until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			relayed, err := r.RelayPending(context.WithoutCancel(ctx))
+			if err != nil {
+				log.Printf("outbox relay failed: %v", err)
+				break
+			}
+			if relayed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of due events and returns how many were
+// claimed. Each batch holds at most one event per shipment, so callers drain
+// until nothing is claimed.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	events, err := r.repository.ClaimOutboxEvents(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := r.publish(ctx, event.Event); err != nil {
+			event.Attempts++
+			event.LastError = err.Error()
+			if r.policy.MaxAttempts > 0 && event.Attempts >= r.policy.MaxAttempts {
+				now := time.Now()
+				event.DeadAt = &now
+				log.Printf("outbox event %d dead-lettered after %d attempts: %v", event.Sequence, event.Attempts, err)
+				if err := r.repository.DeadLetterOutboxEvent(ctx, event); err != nil {
+					log.Printf("failed to dead-letter outbox event %d: %v", event.Sequence, err)
+				}
+				continue
+			}
+			event.NextAttemptAt = time.Now().Add(r.policy.Delay(event.Attempts))
+			if err := r.repository.RescheduleOutboxEvent(ctx, event); err != nil {
+				log.Printf("failed to reschedule outbox event %d: %v", event.Sequence, err)
+			}
+			continue
+		}
+
+		if err := r.repository.MarkOutboxPublished(ctx, event.Sequence, time.Now()); err != nil {
+			log.Printf("failed to mark outbox event %d published: %v", event.Sequence, err)
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, event *domain.ShipmentEvent) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
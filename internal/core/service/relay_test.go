@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"testing"
+	"time"
+)
+
+func outboxTypes(repo *testutil.MockRepository) []string {
+	var types []string
+	for _, event := range repo.GetOutbox() {
+		types = append(types, event.Event.Type)
+	}
+	return types
+}
+
+func TestShippingService_WritesOutboxEvents(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	service.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.test"))
+	failing := testutil.NewMockShippingProvider("B", "http://b.test")
+	failing.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return nil, errors.New("carrier down")
+	})
+	service.RegisterProvider(failing)
+
+	service.ProcessShipment(context.Background(), testutil.CreateSampleShippingRequest(), "A")
+	service.ProcessShipment(context.Background(), testutil.CreateSampleShippingRequest(), "B")
+
+	types := outboxTypes(mockRepo)
+	if len(types) != 2 || types[0] != domain.EventShipmentCreated || types[1] != domain.EventShipmentFailed {
+		t.Errorf("expected created then failed events, got %v", types)
+	}
+}
+
+func TestOutboxRelay_PublishesInOrderPerShipment(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	record := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-1", "A-AWB-1")
+	tracking := NewTrackingService(mockRepo)
+	if err := tracking.UpdateStatus(context.Background(), &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusCancelled, Source: domain.StatusSourceAPI}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink := testutil.NewMockEventSink()
+	relay := NewOutboxRelay(mockRepo, time.Second)
+	relay.AddSink(sink)
+
+	for {
+		relayed, err := relay.RelayPending(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if relayed == 0 {
+			break
+		}
+		if relayed != 1 {
+			t.Fatalf("expected one event per shipment per batch, got %d", relayed)
+		}
+	}
+
+	var types []string
+	for _, event := range sink.GetEvents() {
+		types = append(types, event.Type)
+	}
+	expected := []string{domain.EventShipmentCreated, domain.EventStatusChanged, domain.EventShipmentCancelled}
+	if len(types) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, types)
+			break
+		}
+	}
+}
+
+func TestOutboxRelay_RetriesFailedEvent(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	record := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-1", "A-AWB-1")
+	NewTrackingService(mockRepo).UpdateStatus(context.Background(), &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusPickedUp, Source: domain.StatusSourceAPI})
+
+	sink := testutil.NewMockEventSink()
+	sink.SetError(errors.New("sink unavailable"))
+	relay := NewOutboxRelay(mockRepo, time.Second)
+	relay.AddSink(sink)
+
+	if relayed, _ := relay.RelayPending(context.Background()); relayed != 1 {
+		t.Fatalf("expected the first event to be claimed, got %d", relayed)
+	}
+	if relayed, _ := relay.RelayPending(context.Background()); relayed != 0 {
+		t.Fatalf("expected later events to wait behind the failed one, got %d", relayed)
+	}
+
+	outbox := mockRepo.GetOutbox()
+	if outbox[0].Attempts != 1 || outbox[0].LastError == "" || !outbox[0].NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected failed event to be rescheduled, got %+v", outbox[0])
+	}
+
+	sink.SetError(nil)
+	outbox[0].NextAttemptAt = time.Now()
+	for {
+		if relayed, _ := relay.RelayPending(context.Background()); relayed == 0 {
+			break
+		}
+	}
+
+	events := sink.GetEvents()
+	if len(events) != 2 || events[0].Type != domain.EventShipmentCreated || events[1].Type != domain.EventStatusChanged {
+		t.Errorf("expected created then status.changed after retry, got %d events", len(events))
+	}
+	for _, event := range mockRepo.GetOutbox() {
+		if event.PublishedAt == nil {
+			t.Errorf("expected outbox event %d to be published", event.Sequence)
+		}
+	}
+}
+
+func TestOutboxRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	record := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-1", "A-AWB-1")
+	NewTrackingService(mockRepo).UpdateStatus(context.Background(), &domain.StatusChange{ShipmentID: record.ID, ToStatus: domain.StatusPickedUp, Source: domain.StatusSourceAPI})
+
+	sink := testutil.NewMockEventSink()
+	sink.SetError(errors.New("event rejected"))
+	relay := NewOutboxRelay(mockRepo, time.Second)
+	relay.SetRetryPolicy(domain.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour})
+	relay.AddSink(sink)
+
+	relay.RelayPending(context.Background())
+	outbox := mockRepo.GetOutbox()
+	outbox[0].NextAttemptAt = time.Now()
+	relay.RelayPending(context.Background())
+
+	if outbox[0].DeadAt == nil || outbox[0].Attempts != 2 || outbox[0].PublishedAt != nil {
+		t.Fatalf("expected the event to be dead-lettered after 2 attempts, got %+v", outbox[0])
+	}
+
+	sink.SetError(nil)
+	if relayed, _ := relay.RelayPending(context.Background()); relayed != 1 {
+		t.Fatalf("expected the next event for the shipment to be released, got %d", relayed)
+	}
+	if events := sink.GetEvents(); len(events) != 1 || events[0].Type != domain.EventStatusChanged {
+		t.Errorf("expected status.changed to be published after the dead-lettered event, got %d events", len(events))
+	}
+}
@@ -3,23 +3,29 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"log"
 	"shipping-api/internal/core/domain"
 	"shipping-api/internal/core/ports"
+	"sort"
 	"sync"
 	"time"
 )
 
 type ShippingService struct {
-	providers  map[string]ports.ShippingProvider
-	repository ports.ShipmentRepository
+	providers        map[string]ports.ShippingProvider
+	repository       ports.ShipmentRepository
+	usage            ports.UsageService
+	batchConcurrency int
 }
 
 func NewShippingService(repository ports.ShipmentRepository) *ShippingService {
 	return &ShippingService{
-		providers:  make(map[string]ports.ShippingProvider),
-		repository: repository,
+		providers:        make(map[string]ports.ShippingProvider),
+		repository:       repository,
+		batchConcurrency: defaultBatchConcurrency,
 	}
 }
 
@@ -27,50 +33,214 @@ func (s *ShippingService) RegisterProvider(provider ports.ShippingProvider) {
 	s.providers[provider.GetProviderName()] = provider
 }
 
-func (s *ShippingService) ProcessShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.ShipmentResponse, error) {
-	provider, exists := s.providers[providerName]
+// SetUsageService counts booked shipments against each tenant's daily quota.
+func (s *ShippingService) SetUsageService(usage ports.UsageService) {
+	s.usage = usage
+}
+
+// reserveShipments counts shipments the request is about to book against
+// the daily quota of the tenant in ctx. Like the rate limiter it fails open
+// when the counters cannot be reached.
+func (s *ShippingService) reserveShipments(ctx context.Context, shipments int) error {
+	tenant := domain.TenantFromContext(ctx)
+	if s.usage == nil || tenant == nil || shipments == 0 {
+		return nil
+	}
+
+	decision, err := s.usage.ReserveShipments(ctx, tenant, shipments)
+	if err != nil {
+		log.Printf("quota check failed, allowing shipments: %v", err)
+		return nil
+	}
+	if decision != nil && !decision.Allowed {
+		return &domain.QuotaError{Shipments: shipments, Decision: decision}
+	}
+	return nil
+}
+
+// provider returns the named provider if the tenant in ctx has it enabled.
+func (s *ShippingService) provider(ctx context.Context, name string) (ports.ShippingProvider, error) {
+	provider, exists := s.providers[name]
+	if tenant := domain.TenantFromContext(ctx); exists && tenant != nil && !tenant.ProviderEnabled(name) {
+		exists = false
+	}
 	if !exists {
-		return nil, fmt.Errorf("provider %s not found", providerName)
+		return nil, fmt.Errorf("%w: %s", domain.ErrProviderNotFound, name)
 	}
+	return provider, nil
+}
 
-	response, err := provider.CreateShipment(ctx, request)
+// enabledProviders returns the providers the tenant in ctx may use, or all of
+// them outside a tenant.
+func (s *ShippingService) enabledProviders(ctx context.Context) []ports.ShippingProvider {
+	tenant := domain.TenantFromContext(ctx)
+	providers := make([]ports.ShippingProvider, 0, len(s.providers))
+	for name, provider := range s.providers {
+		if tenant == nil || tenant.ProviderEnabled(name) {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+// broadcastProviders narrows the enabled providers to those of the tenant's
+// first routing rule matching the request.
+func (s *ShippingService) broadcastProviders(ctx context.Context, request *domain.GenericShippingRequest) []ports.ShippingProvider {
+	providers := s.enabledProviders(ctx)
+	tenant := domain.TenantFromContext(ctx)
+	if tenant == nil {
+		return providers
+	}
+	routed := tenant.Route(request)
+	if routed == nil {
+		return providers
+	}
+
+	selected := providers[:0]
+	for _, provider := range providers {
+		for _, name := range routed {
+			if provider.GetProviderName() == name {
+				selected = append(selected, provider)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// withCredentials applies the tenant's carrier account for provider.
+func withCredentials(ctx context.Context, request *domain.GenericShippingRequest, provider ports.ShippingProvider) *domain.GenericShippingRequest {
+	if tenant := domain.TenantFromContext(ctx); tenant != nil {
+		return tenant.WithCredentials(request, provider.GetProviderName())
+	}
+	return request
+}
+
+// ValidateShipment resolves the provider and checks the request is eligible
+// for it, without booking. A broadcast, with no provider, always passes.
+func (s *ShippingService) ValidateShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) error {
+	if providerName == "" {
+		return nil
+	}
+	provider, err := s.provider(ctx, providerName)
 	if err != nil {
+		return err
+	}
+	return checkEligibility(provider, request)
+}
+
+func (s *ShippingService) ProcessShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.ShipmentResponse, error) {
+	provider, err := s.provider(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEligibility(provider, request); err != nil {
+		return nil, err
+	}
+	if err := s.reserveShipments(ctx, 1); err != nil {
+		return nil, err
+	}
+
+	return s.bookShipment(ctx, provider, request)
+}
+
+// bookShipment sends the request to provider and saves the booked shipment.
+func (s *ShippingService) bookShipment(ctx context.Context, provider ports.ShippingProvider, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+	ctx, requestID := ensureRequestID(ctx)
+
+	response, err := provider.CreateShipment(ctx, withCredentials(ctx, request, provider))
+	if err != nil {
+		s.recordAttempt(ctx, provider.GetProviderName(), "", nil, err)
 		return nil, err
 	}
+	response.RequestID = requestID
 
+	var shipmentID string
 	if response.Success {
-		if err := s.saveShipmentRecord(ctx, request, response); err != nil {
-			return response, fmt.Errorf("failed to save shipment record: %w", err)
+		shipmentID, err = s.saveShipmentRecord(ctx, request, response)
+		if err != nil {
+			s.recordAttempt(ctx, provider.GetProviderName(), "", response, nil)
+			return response, fmt.Errorf("%w: %w", domain.ErrShipmentNotSaved, err)
 		}
 	}
 
+	response.ShipmentID = shipmentID
+	s.recordAttempt(ctx, provider.GetProviderName(), shipmentID, response, nil)
+
 	return response, nil
 }
 
 func (s *ShippingService) BroadcastShipment(ctx context.Context, request *domain.GenericShippingRequest) ([]*domain.ShipmentResponse, error) {
-	var wg sync.WaitGroup
 	results := make([]*domain.ShipmentResponse, 0, len(s.providers))
-	resultsChan := make(chan *domain.ShipmentResponse, len(s.providers))
+	err := s.StreamBroadcast(ctx, request, func(response *domain.ShipmentResponse) {
+		results = append(results, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StreamBroadcast sends the request to every provider the tenant routes it
+// to and calls emit with each response as it arrives, ineligible providers
+// first. emit is called from the caller's goroutine. Cancelling ctx cancels
+// the provider calls still in flight; they are reported as failed. When the
+// tenant's quota cannot cover every eligible provider it returns a
+// QuotaError before emitting anything.
+func (s *ShippingService) StreamBroadcast(ctx context.Context, request *domain.GenericShippingRequest, emit func(*domain.ShipmentResponse)) error {
+	providers := s.broadcastProviders(ctx, request)
+	if err := s.reserveShipments(ctx, countEligible(providers, request)); err != nil {
+		return err
+	}
+
+	s.broadcast(ctx, request, providers, emit)
+	return nil
+}
+
+// broadcast books the request with each eligible provider and reports every
+// provider through emit.
+func (s *ShippingService) broadcast(ctx context.Context, request *domain.GenericShippingRequest, providers []ports.ShippingProvider, emit func(*domain.ShipmentResponse)) {
+	ctx, requestID := ensureRequestID(ctx)
+
+	var wg sync.WaitGroup
+	resultsChan := make(chan *domain.ShipmentResponse, len(providers))
+
+	for _, provider := range providers {
+		if err := checkEligibility(provider, request); err != nil {
+			emit(&domain.ShipmentResponse{
+				Provider:  provider.GetProviderName(),
+				Success:   false,
+				Message:   err.Error(),
+				RequestID: requestID,
+			})
+			continue
+		}
 
-	for _, provider := range s.providers {
 		wg.Add(1)
 		go func(p ports.ShippingProvider) {
 			defer wg.Done()
 
-			response, err := p.CreateShipment(ctx, request)
+			response, err := p.CreateShipment(ctx, withCredentials(ctx, request, p))
 			if err != nil {
+				s.recordAttempt(ctx, p.GetProviderName(), "", nil, err)
 				response = &domain.ShipmentResponse{
 					Provider: p.GetProviderName(),
 					Success:  false,
 					Message:  err.Error(),
 				}
-			}
-
-			if response.Success {
-				if err := s.saveShipmentRecord(ctx, request, response); err != nil {
-					response.Message = fmt.Sprintf("saved failed: %v", err)
+			} else {
+				var shipmentID string
+				if response.Success {
+					shipmentID, err = s.saveShipmentRecord(ctx, request, response)
+					if err != nil {
+						response.Message = fmt.Sprintf("saved failed: %v", err)
+					}
+					response.ShipmentID = shipmentID
 				}
+				s.recordAttempt(ctx, p.GetProviderName(), shipmentID, response, nil)
 			}
+			response.RequestID = requestID
 
 			resultsChan <- response
 		}(provider)
@@ -82,37 +252,188 @@ func (s *ShippingService) BroadcastShipment(ctx context.Context, request *domain
 	}()
 
 	for result := range resultsChan {
+		emit(result)
+	}
+}
+
+// Providers lists the providers enabled for the tenant in ctx by name.
+func (s *ShippingService) Providers(ctx context.Context) []domain.ProviderInfo {
+	enabled := s.enabledProviders(ctx)
+	providers := make([]domain.ProviderInfo, 0, len(enabled))
+	for _, provider := range enabled {
+		providers = append(providers, domain.ProviderInfo{Name: provider.GetProviderName(), Capabilities: provider.GetCapabilities()})
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers
+}
+
+func (s *ShippingService) TransformShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) ([]*domain.TransformResult, error) {
+	var providers []ports.ShippingProvider
+	if providerName != "" {
+		provider, err := s.provider(ctx, providerName)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	} else {
+		providers = s.broadcastProviders(ctx, request)
+		sort.Slice(providers, func(i, j int) bool {
+			return providers[i].GetProviderName() < providers[j].GetProviderName()
+		})
+	}
+
+	results := make([]*domain.TransformResult, 0, len(providers))
+	for _, provider := range providers {
+		result, err := provider.TransformRequest(request)
+		if err != nil {
+			result = &domain.TransformResult{
+				Provider: provider.GetProviderName(),
+				Errors:   []string{err.Error()},
+			}
+		}
+
+		reasons := provider.GetCapabilities().CheckEligibility(request)
+		if request.StrictMapping && len(result.Warnings) > 0 {
+			mappingErr := &domain.MappingError{Provider: provider.GetProviderName(), Warnings: result.Warnings}
+			reasons = append(reasons, mappingErr.Error())
+		}
+		result.Eligible = len(reasons) == 0 && err == nil
+		result.Errors = append(result.Errors, reasons...)
+
 		results = append(results, result)
 	}
 
 	return results, nil
 }
 
-func (s *ShippingService) saveShipmentRecord(ctx context.Context, request *domain.GenericShippingRequest, response *domain.ShipmentResponse) error {
-	genericPayload, err := json.Marshal(request)
-	if err != nil {
-		return err
+func countEligible(providers []ports.ShippingProvider, request *domain.GenericShippingRequest) int {
+	eligible := 0
+	for _, provider := range providers {
+		if checkEligibility(provider, request) == nil {
+			eligible++
+		}
 	}
+	return eligible
+}
 
-	transformedPayload, err := json.Marshal(response.RawResponse)
+func checkEligibility(provider ports.ShippingProvider, request *domain.GenericShippingRequest) error {
+	reasons := provider.GetCapabilities().CheckEligibility(request)
+	if len(reasons) > 0 {
+		return &domain.EligibilityError{
+			Provider: provider.GetProviderName(),
+			Reasons:  reasons,
+		}
+	}
+	return nil
+}
+
+func (s *ShippingService) saveShipmentRecord(ctx context.Context, request *domain.GenericShippingRequest, response *domain.ShipmentResponse) (string, error) {
+	stored := *request
+	stored.Account = request.Account.Redacted()
+	genericPayload, err := json.Marshal(&stored)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	transformedPayload := []byte("null")
+	var rawResponse []byte
+	if response.Exchange != nil {
+		if len(response.Exchange.RequestBody) > 0 {
+			transformedPayload = response.Exchange.RequestBody
+		}
+		rawResponse = response.Exchange.ResponseBody
 	}
 
 	providerResponse, err := json.Marshal(response)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	record := &domain.ShipmentRecord{
 		ID:                 uuid.New().String(),
+		TenantID:           domain.TenantIDFromContext(ctx),
 		Provider:           response.Provider,
 		GenericPayload:     genericPayload,
 		TransformedPayload: transformedPayload,
 		ProviderResponse:   providerResponse,
+		RawResponse:        rawResponse,
 		Success:            response.Success,
 		CreatedAt:          time.Now(),
 	}
 
-	return s.repository.Save(ctx, record)
+	// The carrier has booked the shipment, so store it even if the caller
+	// has gone away.
+	if err := s.repository.Save(context.WithoutCancel(ctx), record); err != nil {
+		return "", err
+	}
+
+	return record.ID, nil
+}
+
+func (s *ShippingService) recordAttempt(ctx context.Context, providerName, shipmentID string, response *domain.ShipmentResponse, callErr error) {
+	attempt := &domain.ShipmentAttempt{
+		ID:         uuid.New().String(),
+		TenantID:   domain.TenantIDFromContext(ctx),
+		RequestID:  domain.RequestIDFromContext(ctx),
+		ShipmentID: shipmentID,
+		Provider:   providerName,
+		CreatedAt:  time.Now(),
+	}
+
+	var exchange *domain.ProviderExchange
+	if response != nil {
+		exchange = response.Exchange
+		attempt.Success = response.Success
+		if !response.Success {
+			attempt.ErrorCategory = domain.ErrorCategoryProvider
+			attempt.ErrorMessage = response.Message
+		}
+	}
+
+	if callErr != nil {
+		attempt.ErrorCategory = categorizeError(callErr)
+		attempt.ErrorMessage = callErr.Error()
+		var providerErr *domain.ProviderError
+		if errors.As(callErr, &providerErr) {
+			exchange = providerErr.Exchange
+		}
+	}
+
+	if exchange != nil {
+		attempt.RequestBody = exchange.RequestBody
+		attempt.ResponseStatus = exchange.StatusCode
+		attempt.ResponseBody = exchange.ResponseBody
+		attempt.DurationMs = exchange.Duration.Milliseconds()
+		if exchange.Headers != nil {
+			attempt.ResponseHeaders, _ = json.Marshal(exchange.Headers)
+		}
+	}
+
+	if err := s.repository.SaveAttempt(context.WithoutCancel(ctx), attempt); err != nil {
+		log.Printf("failed to save shipment attempt for provider %s: %v", providerName, err)
+	}
+}
+
+func categorizeError(err error) string {
+	var providerErr *domain.ProviderError
+	var mappingErr *domain.MappingError
+	var codeErr *domain.CodeTranslationError
+	switch {
+	case errors.As(err, &providerErr):
+		return providerErr.Category
+	case errors.As(err, &mappingErr), errors.As(err, &codeErr):
+		return domain.ErrorCategoryValidation
+	default:
+		return domain.ErrorCategoryInternal
+	}
+}
+
+func ensureRequestID(ctx context.Context) (context.Context, string) {
+	if requestID := domain.RequestIDFromContext(ctx); requestID != "" {
+		return ctx, requestID
+	}
+	requestID := uuid.New().String()
+	return domain.WithRequestID(ctx, requestID), requestID
 }
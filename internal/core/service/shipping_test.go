@@ -191,3 +191,207 @@ func TestShippingService_RegisterProvider(t *testing.T) {
 		t.Errorf("expected provider name TestProvider, got %s", registeredProvider.GetProviderName())
 	}
 }
+
+func TestShippingService_ProcessShipment_IneligibleProvider(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	called := false
+	mockProvider := testutil.NewMockShippingProvider("B", "http://b.local")
+	mockProvider.SetCapabilities(domain.ProviderCapabilities{
+		SupportsCOD:       true,
+		CODCountries:      []string{"AE", "SA"},
+		SupportsInsurance: true,
+	})
+	mockProvider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		called = true
+		return &domain.ShipmentResponse{Provider: "B", Success: true}, nil
+	})
+	service.RegisterProvider(mockProvider)
+
+	request := testutil.CreateSampleShippingRequest()
+	request.IsCOD = true
+	request.CODAmount = 100
+
+	_, err := service.ProcessShipment(context.Background(), request, "B")
+
+	var eligibilityErr *domain.EligibilityError
+	if !errors.As(err, &eligibilityErr) {
+		t.Fatalf("expected eligibility error, got %v", err)
+	}
+
+	if called {
+		t.Error("expected provider not to be called")
+	}
+
+	if mockRepo.GetRecordCount() != 0 {
+		t.Errorf("expected 0 records in repository, got %d", mockRepo.GetRecordCount())
+	}
+}
+
+func TestShippingService_BroadcastShipment_SkipsIneligibleProviders(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	providerA := testutil.NewMockShippingProvider("A", "http://a.local")
+
+	called := false
+	providerB := testutil.NewMockShippingProvider("B", "http://b.local")
+	providerB.SetCapabilities(domain.ProviderCapabilities{MaxWeightKg: 0.5, SupportsInsurance: true})
+	providerB.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		called = true
+		return &domain.ShipmentResponse{Provider: "B", Success: true}, nil
+	})
+
+	service.RegisterProvider(providerA)
+	service.RegisterProvider(providerB)
+
+	responses, err := service.BroadcastShipment(context.Background(), testutil.CreateSampleShippingRequest())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if called {
+		t.Error("expected ineligible provider B not to be called")
+	}
+
+	for _, resp := range responses {
+		if resp.Provider == "B" && resp.Success {
+			t.Error("expected provider B to be reported as skipped")
+		}
+		if resp.Provider == "A" && !resp.Success {
+			t.Error("expected provider A to succeed")
+		}
+	}
+
+	if mockRepo.GetRecordCount() != 1 {
+		t.Errorf("expected 1 record in repository, got %d", mockRepo.GetRecordCount())
+	}
+}
+
+func TestShippingService_ProcessShipment_RecordsAttempts(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	mockProvider := testutil.NewMockShippingProvider("ErrorProvider", "http://test.local")
+	mockProvider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return nil, &domain.ProviderError{
+			Provider: "ErrorProvider",
+			Category: domain.ErrorCategoryTimeout,
+			Exchange: &domain.ProviderExchange{RequestBody: []byte(`{"sent":true}`)},
+			Err:      errors.New("deadline exceeded"),
+		}
+	})
+	service.RegisterProvider(mockProvider)
+
+	ctx := domain.WithRequestID(context.Background(), "req-1")
+	request := testutil.CreateSampleShippingRequest()
+
+	if _, err := service.ProcessShipment(ctx, request, "ErrorProvider"); err == nil {
+		t.Fatal("expected error from provider, got nil")
+	}
+	if _, err := service.ProcessShipment(ctx, request, "ErrorProvider"); err == nil {
+		t.Fatal("expected error from provider, got nil")
+	}
+
+	attempts, _ := mockRepo.FindAttemptsByRequestID(context.Background(), "req-1")
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+
+	if attempts[0].ErrorCategory != domain.ErrorCategoryTimeout {
+		t.Errorf("expected timeout category, got %s", attempts[0].ErrorCategory)
+	}
+
+	if string(attempts[0].RequestBody) != `{"sent":true}` {
+		t.Errorf("expected request body to be recorded, got %s", attempts[0].RequestBody)
+	}
+
+	if attempts[1].AttemptNumber != 2 {
+		t.Errorf("expected second attempt number 2, got %d", attempts[1].AttemptNumber)
+	}
+
+	if mockRepo.GetRecordCount() != 0 {
+		t.Errorf("expected 0 records in repository, got %d", mockRepo.GetRecordCount())
+	}
+}
+
+func TestShippingService_BroadcastShipment_RecordsAttemptPerProvider(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	providerB := testutil.NewMockShippingProvider("B", "http://b.local")
+	providerB.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return &domain.ShipmentResponse{Provider: "B", Success: false, Message: "rejected"}, nil
+	})
+
+	service.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	service.RegisterProvider(providerB)
+
+	responses, _ := service.BroadcastShipment(context.Background(), testutil.CreateSampleShippingRequest())
+
+	if mockRepo.GetAttemptCount() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", mockRepo.GetAttemptCount())
+	}
+
+	requestID := responses[0].RequestID
+	if requestID == "" || responses[1].RequestID != requestID {
+		t.Errorf("expected both responses to share a request ID, got %q and %q", requestID, responses[1].RequestID)
+	}
+
+	attempts, _ := mockRepo.FindAttemptsByRequestID(context.Background(), requestID)
+	for _, attempt := range attempts {
+		switch attempt.Provider {
+		case "A":
+			if !attempt.Success || attempt.ShipmentID == "" {
+				t.Errorf("expected successful attempt linked to a shipment, got %+v", attempt)
+			}
+		case "B":
+			if attempt.Success || attempt.ErrorCategory != domain.ErrorCategoryProvider {
+				t.Errorf("expected provider_error attempt, got %+v", attempt)
+			}
+		}
+	}
+}
+
+func TestShippingService_StreamBroadcast_CancelsOutstandingCalls(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	service := NewShippingService(mockRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelled := make(chan struct{})
+	slow := testutil.NewMockShippingProvider("A", "http://a.local")
+	slow.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, &domain.ProviderError{Provider: "A", Category: domain.ErrorCategoryNetwork, Err: ctx.Err()}
+	})
+	fast := testutil.NewMockShippingProvider("B", "http://b.local")
+	fast.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		cancel()
+		return &domain.ShipmentResponse{Provider: "B", Success: true, TrackingID: "B-1"}, nil
+	})
+	service.RegisterProvider(slow)
+	service.RegisterProvider(fast)
+
+	var providers []string
+	service.StreamBroadcast(ctx, testutil.CreateSampleShippingRequest(), func(response *domain.ShipmentResponse) {
+		providers = append(providers, response.Provider)
+	})
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("expected the outstanding provider call to be cancelled")
+	}
+	if len(providers) != 2 || providers[0] != "B" {
+		t.Errorf("expected B's response before A's cancellation, got %v", providers)
+	}
+
+	records, _ := mockRepo.FindByTrackingID(context.Background(), "B-1")
+	if len(records) != 1 {
+		t.Errorf("expected the booked shipment to be saved despite the cancellation, got %d records", len(records))
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"shipping-api/internal/core/domain"
+	"sort"
+)
+
+// TenantRegistry holds the tenants loaded from configuration. The default
+// tenant always exists, with every provider enabled unless configured.
+type TenantRegistry struct {
+	tenants map[string]*domain.Tenant
+}
+
+func NewTenantRegistry(tenants map[string]*domain.Tenant) *TenantRegistry {
+	registry := &TenantRegistry{
+		tenants: map[string]*domain.Tenant{
+			domain.DefaultTenantID: {ID: domain.DefaultTenantID, Name: "Default"},
+		},
+	}
+	for id, tenant := range tenants {
+		registry.tenants[id] = tenant
+	}
+	return registry
+}
+
+func (r *TenantRegistry) Tenant(id string) (*domain.Tenant, error) {
+	tenant, exists := r.tenants[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTenantNotFound, id)
+	}
+	return tenant, nil
+}
+
+func (r *TenantRegistry) Tenants() []*domain.Tenant {
+	tenants := make([]*domain.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newAcmeTenant() *domain.Tenant {
+	return &domain.Tenant{
+		ID:          "acme",
+		Providers:   []string{"A", "B"},
+		Credentials: map[string]domain.AccountInfo{"A": {Number: "acme-account"}},
+		Routing:     []domain.RoutingRule{{Countries: []string{"IN"}, Providers: []string{"A"}}},
+	}
+}
+
+// accountRecorder records the account number each call to provider carries.
+func accountRecorder(provider *testutil.MockShippingProvider) func() []string {
+	var mu sync.Mutex
+	var accounts []string
+	provider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		accounts = append(accounts, request.Account.Number)
+		return &domain.ShipmentResponse{Provider: provider.GetProviderName(), Success: true, TrackingID: "TRACK123"}, nil
+	})
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), accounts...)
+	}
+}
+
+func TestShippingService_TenantProvidersAndCredentials(t *testing.T) {
+	repo := testutil.NewMockRepository()
+	service := NewShippingService(repo)
+	providerA := testutil.NewMockShippingProvider("A", "http://a.local")
+	accounts := accountRecorder(providerA)
+	service.RegisterProvider(providerA)
+	service.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.local"))
+	service.RegisterProvider(testutil.NewMockShippingProvider("C", "http://c.local"))
+
+	ctx := domain.WithTenant(context.Background(), newAcmeTenant())
+
+	if _, err := service.ProcessShipment(ctx, testutil.CreateSampleShippingRequest(), "C"); !errors.Is(err, domain.ErrProviderNotFound) {
+		t.Errorf("expected a disabled provider to be not found, got %v", err)
+	}
+	if providers := service.Providers(ctx); len(providers) != 2 {
+		t.Errorf("expected only the tenant's 2 providers listed, got %d", len(providers))
+	}
+
+	request := testutil.CreateSampleShippingRequest()
+	response, err := service.ProcessShipment(ctx, request, "A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := accounts(); len(got) != 1 || got[0] != "acme-account" {
+		t.Errorf("expected the tenant's carrier account, got %v", got)
+	}
+	if request.Account.Number != "123" {
+		t.Errorf("expected the client's request left unchanged, got account %q", request.Account.Number)
+	}
+
+	record, err := repo.FindByID(ctx, response.ShipmentID)
+	if err != nil || record.TenantID != "acme" {
+		t.Fatalf("expected record owned by acme, got %+v (%v)", record, err)
+	}
+	other := domain.WithTenant(context.Background(), &domain.Tenant{ID: "other"})
+	if _, err := repo.FindByID(other, response.ShipmentID); !errors.Is(err, domain.ErrShipmentNotFound) {
+		t.Errorf("expected another tenant not to see the shipment, got %v", err)
+	}
+}
+
+func TestShippingService_BroadcastFollowsTenantRouting(t *testing.T) {
+	service := NewShippingService(testutil.NewMockRepository())
+	service.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	service.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.local"))
+	ctx := domain.WithTenant(context.Background(), newAcmeTenant())
+
+	request := testutil.CreateSampleShippingRequest()
+	responses, _ := service.BroadcastShipment(ctx, request)
+	if len(responses) != 1 || responses[0].Provider != "A" {
+		t.Errorf("expected IN routed to A only, got %d responses", len(responses))
+	}
+
+	request.Consignee.Address.CountryCode = "GB"
+	responses, _ = service.BroadcastShipment(ctx, request)
+	if len(responses) != 2 {
+		t.Errorf("expected an unrouted destination to use every enabled provider, got %d responses", len(responses))
+	}
+}
+
+func TestWebhookDispatcher_DeliversOnlyToEventTenant(t *testing.T) {
+	sender := testutil.NewMockWebhookSender(http.StatusOK, nil)
+	dispatcher := NewWebhookDispatcher(testutil.NewMockWebhookRepository(), sender, time.Second)
+
+	acme := domain.WithTenant(context.Background(), &domain.Tenant{ID: "acme"})
+	dispatcher.CreateSubscription(acme, "https://acme.test/hooks", []string{domain.EventShipmentCreated}, "")
+	dispatcher.CreateSubscription(context.Background(), "https://default.test/hooks", []string{domain.EventShipmentCreated}, "")
+
+	event, _ := domain.NewShipmentEvent(domain.EventShipmentCreated, "shipment-1", time.Now(), domain.ShipmentEventData{Provider: "A"})
+	event.ID = "event-1"
+	event.TenantID = "acme"
+	if err := dispatcher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sent, _ := dispatcher.DispatchDue(context.Background()); sent != 1 {
+		t.Errorf("expected one delivery to acme's subscription, got %d", sent)
+	}
+}
+
+func TestJobRunner_RunsJobAsItsTenant(t *testing.T) {
+	provider := testutil.NewMockShippingProvider("A", "http://a.test")
+	accounts := accountRecorder(provider)
+	runner, jobRepo, _ := newTestJobRunner(provider)
+	runner.SetTenantDirectory(NewTenantRegistry(map[string]*domain.Tenant{"acme": newAcmeTenant()}))
+
+	ctx := domain.WithTenant(context.Background(), newAcmeTenant())
+	job, err := runner.SubmitShipment(ctx, testutil.CreateSampleShippingRequest(), "A")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := jobRepo.FindJob(domain.WithTenant(context.Background(), &domain.Tenant{ID: "other"}), job.ID); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected another tenant not to see the job, got %v", err)
+	}
+
+	if processed, err := runner.ProcessDue(context.Background()); err != nil || processed != 1 {
+		t.Fatalf("expected one processed job, got %d (%v)", processed, err)
+	}
+	if got := accounts(); len(got) != 1 || got[0] != "acme-account" {
+		t.Errorf("expected the job to use the tenant's carrier account, got %v", got)
+	}
+}
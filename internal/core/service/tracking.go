@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sort"
+)
+
+type TrackingService struct {
+	parsers    map[string]ports.WebhookParser
+	repository ports.ShipmentRepository
+}
+
+func NewTrackingService(repository ports.ShipmentRepository) *TrackingService {
+	return &TrackingService{
+		parsers:    make(map[string]ports.WebhookParser),
+		repository: repository,
+	}
+}
+
+func (s *TrackingService) RegisterWebhookParser(parser ports.WebhookParser) {
+	s.parsers[parser.GetProviderName()] = parser
+}
+
+// UpdateStatus applies a status change. The repository queues status.changed,
+// plus shipment.cancelled when the shipment is cancelled, in the outbox.
+func (s *TrackingService) UpdateStatus(ctx context.Context, change *domain.StatusChange) error {
+	return s.repository.UpdateStatus(ctx, change)
+}
+
+func (s *TrackingService) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) ([]*domain.TrackingEventResult, error) {
+	parser, exists := s.parsers[providerName]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebhookNotSupported, providerName)
+	}
+
+	if err := parser.VerifyWebhook(header, body); err != nil {
+		return nil, err
+	}
+
+	events, err := parser.ParseWebhook(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWebhookPayload, err)
+	}
+
+	return s.ApplyEvents(ctx, domain.StatusSourceWebhook, events)
+}
+
+// ApplyEvents matches each event to its shipment and records it, oldest
+// first. Events that cannot be applied are reported in the results rather than
+// failing the batch; only repository failures return an error.
+func (s *TrackingService) ApplyEvents(ctx context.Context, source string, events []*domain.TrackingEvent) ([]*domain.TrackingEventResult, error) {
+	sorted := make([]*domain.TrackingEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].OccurredAt.Before(sorted[j].OccurredAt) })
+
+	results := make([]*domain.TrackingEventResult, 0, len(sorted))
+	for _, event := range sorted {
+		result, err := s.applyEvent(ctx, source, event)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *TrackingService) applyEvent(ctx context.Context, source string, event *domain.TrackingEvent) (*domain.TrackingEventResult, error) {
+	result := &domain.TrackingEventResult{EventID: event.Key(), Status: event.Status}
+
+	if event.Status == "" {
+		result.Result = domain.TrackingResultIgnored
+		result.Detail = fmt.Sprintf("unmapped carrier status %q", event.CarrierStatus)
+		return result, nil
+	}
+
+	record, err := s.findShipment(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		result.Result = domain.TrackingResultUnmatched
+		return result, nil
+	}
+	result.ShipmentID = record.ID
+
+	change := &domain.StatusChange{
+		ShipmentID:    record.ID,
+		ToStatus:      event.Status,
+		Source:        source,
+		Description:   event.Description,
+		Location:      event.Location,
+		EventID:       result.EventID,
+		CarrierStatus: event.CarrierStatus,
+		OccurredAt:    event.OccurredAt,
+	}
+
+	var transitionErr *domain.TransitionError
+	err = s.UpdateStatus(ctx, change)
+	switch {
+	case errors.Is(err, domain.ErrDuplicateStatusChange):
+		result.Result = domain.TrackingResultDuplicate
+	case errors.As(err, &transitionErr):
+		result.Result = domain.TrackingResultIgnored
+		result.Detail = err.Error()
+	case err != nil:
+		return nil, err
+	default:
+		result.Result = domain.TrackingResultApplied
+	}
+
+	return result, nil
+}
+
+func (s *TrackingService) findShipment(ctx context.Context, event *domain.TrackingEvent) (*domain.ShipmentRecord, error) {
+	if event.TrackingID != "" {
+		records, err := s.repository.FindByTrackingID(ctx, event.TrackingID)
+		if err != nil {
+			return nil, err
+		}
+		if record := firstForProvider(records, event.Provider); record != nil {
+			return record, nil
+		}
+	}
+
+	if event.AWB != "" {
+		records, err := s.repository.FindByAWB(ctx, event.AWB)
+		if err != nil {
+			return nil, err
+		}
+		return firstForProvider(records, event.Provider), nil
+	}
+
+	return nil, nil
+}
+
+func firstForProvider(records []*domain.ShipmentRecord, provider string) *domain.ShipmentRecord {
+	for _, record := range records {
+		if record.Provider == provider && record.Success {
+			return record
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"testing"
+	"time"
+)
+
+func seedTrackedShipment(t *testing.T, repo *testutil.MockRepository, provider, trackingID, awb string) *domain.ShipmentRecord {
+	record := &domain.ShipmentRecord{
+		ID:               provider + "-" + awb,
+		Provider:         provider,
+		ProviderResponse: []byte(`{}`),
+		TrackingID:       trackingID,
+		AWB:              awb,
+		Success:          true,
+		CreatedAt:        time.Now(),
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("failed to seed record: %v", err)
+	}
+	return record
+}
+
+func TestTrackingService_ApplyEvents(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	record := seedTrackedShipment(t, mockRepo, "A", "A-TRACK-1", "A-AWB-1")
+	service := NewTrackingService(mockRepo)
+
+	base := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	events := []*domain.TrackingEvent{
+		{Provider: "A", EventID: "E2", AWB: "A-AWB-1", Status: domain.StatusDelivered, OccurredAt: base.Add(2 * time.Hour)},
+		{Provider: "A", EventID: "E1", TrackingID: "A-TRACK-1", Status: domain.StatusPickedUp, OccurredAt: base},
+		{Provider: "A", EventID: "E3", TrackingID: "UNKNOWN", Status: domain.StatusInTransit, OccurredAt: base},
+		{Provider: "A", EventID: "E4", TrackingID: "A-TRACK-1", CarrierStatus: "WEIGHED", OccurredAt: base},
+	}
+
+	results, err := service.ApplyEvents(context.Background(), domain.StatusSourceWebhook, events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"E1": domain.TrackingResultApplied,
+		"E2": domain.TrackingResultApplied,
+		"E3": domain.TrackingResultUnmatched,
+		"E4": domain.TrackingResultIgnored,
+	}
+	for _, result := range results {
+		if result.Result != want[result.EventID] {
+			t.Errorf("event %s: expected %s, got %s (%s)", result.EventID, want[result.EventID], result.Result, result.Detail)
+		}
+	}
+
+	found, _ := mockRepo.FindByID(context.Background(), record.ID)
+	if found.Status != domain.StatusDelivered {
+		t.Errorf("expected status delivered, got %s", found.Status)
+	}
+
+	results, err = service.ApplyEvents(context.Background(), domain.StatusSourceWebhook, events[:1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Result != domain.TrackingResultDuplicate {
+		t.Errorf("expected repeated event to be a duplicate, got %s", results[0].Result)
+	}
+}
+
+func TestTrackingService_ApplyEvents_OtherProviderNotMatched(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	seedTrackedShipment(t, mockRepo, "B", "SHARED-1", "B-AWB-1")
+	service := NewTrackingService(mockRepo)
+
+	events := []*domain.TrackingEvent{{Provider: "A", TrackingID: "SHARED-1", Status: domain.StatusPickedUp, OccurredAt: time.Now()}}
+	results, err := service.ApplyEvents(context.Background(), domain.StatusSourceWebhook, events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Result != domain.TrackingResultUnmatched {
+		t.Errorf("expected unmatched, got %s", results[0].Result)
+	}
+}
+
+func TestTrackingService_HandleWebhook_Errors(t *testing.T) {
+	service := NewTrackingService(testutil.NewMockRepository())
+	service.RegisterWebhookParser(testutil.NewMockWebhookParser("A", "secret"))
+
+	if _, err := service.HandleWebhook(context.Background(), "Z", http.Header{}, []byte(`{}`)); !errors.Is(err, domain.ErrWebhookNotSupported) {
+		t.Errorf("expected ErrWebhookNotSupported, got %v", err)
+	}
+
+	if _, err := service.HandleWebhook(context.Background(), "A", http.Header{}, []byte(`{}`)); !errors.Is(err, domain.ErrWebhookSignature) {
+		t.Errorf("expected ErrWebhookSignature, got %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("X-Webhook-Token", "secret")
+	if _, err := service.HandleWebhook(context.Background(), "A", header, []byte(`not json`)); !errors.Is(err, domain.ErrInvalidWebhookPayload) {
+		t.Errorf("expected ErrInvalidWebhookPayload, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"log"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+)
+
+const defaultUsagePruneInterval = time.Hour
+
+// UsageService enforces tenant rate limits and daily shipment quotas with
+// counters in the repository, so the limits hold across replicas.
+type UsageService struct {
+	repository ports.UsageRepository
+	tenants    ports.TenantDirectory
+	interval   time.Duration
+	now        func() time.Time
+}
+
+func NewUsageService(repository ports.UsageRepository, tenants ports.TenantDirectory) *UsageService {
+	return &UsageService{
+		repository: repository,
+		tenants:    tenants,
+		interval:   defaultUsagePruneInterval,
+		now:        time.Now,
+	}
+}
+
+// AllowRequest counts a request in the tenant's current rate limit window.
+// It returns nil when the tenant has no rate limit.
+func (s *UsageService) AllowRequest(ctx context.Context, tenant *domain.Tenant) (*domain.LimitDecision, error) {
+	if tenant.RateLimit.RequestsPerSecond <= 0 {
+		return nil, nil
+	}
+	window, limit := tenant.RateLimit.RequestWindow()
+	start := s.now().UTC().Truncate(window)
+	return s.count(ctx, tenant.ID, domain.UsageRequests, start, window, start.Add(window), 1, limit)
+}
+
+// ReserveShipments counts shipments against the tenant's daily quota, all or
+// none of them. It returns nil when the tenant has no quota.
+func (s *UsageService) ReserveShipments(ctx context.Context, tenant *domain.Tenant, shipments int) (*domain.LimitDecision, error) {
+	if tenant.RateLimit.DailyShipments <= 0 {
+		return nil, nil
+	}
+	day := domain.UsageDay(s.now())
+	return s.count(ctx, tenant.ID, domain.UsageShipments, day, 24*time.Hour, day.Add(domain.UsageRetention), shipments, tenant.RateLimit.DailyShipments)
+}
+
+func (s *UsageService) count(ctx context.Context, tenantID, metric string, start time.Time, window time.Duration, expiresAt time.Time, amount, limit int) (*domain.LimitDecision, error) {
+	counter := &domain.UsageCounter{
+		TenantID:    tenantID,
+		Metric:      metric,
+		WindowStart: start,
+		ExpiresAt:   expiresAt,
+	}
+	allowed, err := s.repository.IncrementUsage(ctx, counter, amount, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LimitDecision{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-counter.Count, 0),
+		Window:    window,
+		Reset:     start.Add(window),
+	}, nil
+}
+
+// Usage reports shipment quota use on day for tenantID, or for every tenant
+// when it is empty.
+func (s *UsageService) Usage(ctx context.Context, tenantID string, day time.Time) ([]*domain.TenantUsage, error) {
+	tenants := s.tenants.Tenants()
+	if tenantID != "" {
+		tenant, err := s.tenants.Tenant(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		tenants = []*domain.Tenant{tenant}
+	}
+
+	day = domain.UsageDay(day)
+	counters, err := s.repository.ListUsage(ctx, domain.UsageShipments, day)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(counters))
+	for _, counter := range counters {
+		counts[counter.TenantID] = counter.Count
+	}
+
+	usage := make([]*domain.TenantUsage, 0, len(tenants))
+	for _, tenant := range tenants {
+		entry := &domain.TenantUsage{
+			TenantID:  tenant.ID,
+			Date:      day.Format(time.DateOnly),
+			Shipments: counts[tenant.ID],
+			Quota:     tenant.RateLimit.DailyShipments,
+		}
+		if entry.Quota > 0 {
+			remaining := max(entry.Quota-entry.Shipments, 0)
+			entry.Remaining = &remaining
+		}
+		usage = append(usage, entry)
+	}
+	return usage, nil
+}
+
+// Run deletes expired counters until ctx is cancelled.
+func (s *UsageService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.repository.DeleteExpiredUsage(context.WithoutCancel(ctx), s.now().UTC()); err != nil {
+			log.Printf("failed to delete expired usage counters: %v", err)
+		} else if deleted > 0 {
+			log.Printf("deleted %d expired usage counters", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
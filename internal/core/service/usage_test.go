@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/testutil"
+	"testing"
+	"time"
+)
+
+func newTestUsageService(tenants map[string]*domain.Tenant) (*UsageService, *time.Time) {
+	now := time.Date(2024, 3, 1, 23, 59, 58, 0, time.UTC)
+	usage := NewUsageService(testutil.NewMockUsageRepository(), NewTenantRegistry(tenants))
+	usage.now = func() time.Time { return now }
+	return usage, &now
+}
+
+func TestUsageService_AllowRequest(t *testing.T) {
+	tenant := &domain.Tenant{ID: "acme", RateLimit: domain.RateLimit{RequestsPerSecond: 1, Burst: 2}}
+	usage, now := newTestUsageService(nil)
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		decision, err := usage.AllowRequest(ctx, tenant)
+		if err != nil || !decision.Allowed || decision.Remaining != 2-i {
+			t.Fatalf("request %d: expected allowed with %d remaining, got %+v (%v)", i, 2-i, decision, err)
+		}
+	}
+	decision, _ := usage.AllowRequest(ctx, tenant)
+	if decision.Allowed || decision.Remaining != 0 || decision.Window != 2*time.Second {
+		t.Fatalf("expected the third request in a 2s window denied, got %+v", decision)
+	}
+	if !decision.Reset.Equal(now.Truncate(2 * time.Second).Add(2 * time.Second)) {
+		t.Errorf("expected reset at the end of the window, got %v", decision.Reset)
+	}
+
+	*now = now.Add(2 * time.Second)
+	if decision, _ := usage.AllowRequest(ctx, tenant); !decision.Allowed {
+		t.Error("expected the next window to admit requests again")
+	}
+
+	if decision, err := usage.AllowRequest(ctx, &domain.Tenant{ID: "free"}); decision != nil || err != nil {
+		t.Errorf("expected no decision without a rate limit, got %+v (%v)", decision, err)
+	}
+}
+
+func TestUsageService_DailyShipmentQuota(t *testing.T) {
+	acme := &domain.Tenant{ID: "acme", RateLimit: domain.RateLimit{DailyShipments: 2}}
+	usage, now := newTestUsageService(map[string]*domain.Tenant{"acme": acme})
+	ctx := context.Background()
+
+	if decision, _ := usage.ReserveShipments(ctx, acme, 3); decision.Allowed || decision.Remaining != 2 {
+		t.Fatalf("expected 3 shipments refused with 2 remaining, got %+v", decision)
+	}
+	usage.ReserveShipments(ctx, acme, 2)
+	if decision, _ := usage.ReserveShipments(ctx, acme, 1); decision.Allowed {
+		t.Fatal("expected the third shipment of the day denied")
+	}
+
+	report, err := usage.Usage(ctx, "acme", *now)
+	if err != nil || len(report) != 1 {
+		t.Fatalf("expected acme's usage, got %v (%v)", report, err)
+	}
+	if report[0].Shipments != 2 || report[0].Quota != 2 || report[0].Remaining == nil || *report[0].Remaining != 0 || report[0].Date != "2024-03-01" {
+		t.Errorf("expected 2 of 2 shipments used on 2024-03-01, got %+v", report[0])
+	}
+
+	*now = now.Add(2 * time.Second)
+	if decision, _ := usage.ReserveShipments(ctx, acme, 1); !decision.Allowed || decision.Remaining != 1 {
+		t.Errorf("expected the quota to reset at midnight UTC, got %+v", decision)
+	}
+
+	all, _ := usage.Usage(ctx, "", *now)
+	if len(all) != 2 || all[0].TenantID != "acme" || all[1].TenantID != domain.DefaultTenantID || all[1].Remaining != nil {
+		t.Errorf("expected every tenant reported, unlimited without remaining, got %+v", all)
+	}
+
+	if _, err := usage.Usage(ctx, "unknown", *now); !errors.Is(err, domain.ErrTenantNotFound) {
+		t.Errorf("expected ErrTenantNotFound, got %v", err)
+	}
+}
+
+func TestShippingService_DailyShipmentQuota(t *testing.T) {
+	acme := &domain.Tenant{ID: "acme", RateLimit: domain.RateLimit{DailyShipments: 3}}
+	usage, now := newTestUsageService(map[string]*domain.Tenant{"acme": acme})
+	ineligible := testutil.NewMockShippingProvider("I", "http://i.test")
+	ineligible.SetCapabilities(domain.ProviderCapabilities{DestinationCountries: []string{"ZZ"}})
+	shipping := NewShippingService(testutil.NewMockRepository())
+	shipping.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.test"))
+	shipping.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.test"))
+	shipping.RegisterProvider(ineligible)
+	shipping.SetUsageService(usage)
+	ctx := domain.WithTenant(context.Background(), acme)
+	request := testutil.CreateSampleShippingRequest()
+
+	var eligibilityErr *domain.EligibilityError
+	if _, err := shipping.ProcessShipment(ctx, request, "I"); !errors.As(err, &eligibilityErr) {
+		t.Fatalf("expected an eligibility error, got %v", err)
+	}
+	if _, err := shipping.ProcessShipment(ctx, request, "missing"); !errors.Is(err, domain.ErrProviderNotFound) {
+		t.Fatalf("expected ErrProviderNotFound, got %v", err)
+	}
+
+	responses, err := shipping.BroadcastShipment(ctx, request)
+	if err != nil || len(responses) != 3 {
+		t.Fatalf("expected a broadcast to two eligible providers within the quota, got %d (%v)", len(responses), err)
+	}
+
+	items := []*domain.BatchItem{
+		{Index: 0, Request: request},
+		{Index: 1, Request: request},
+		{Index: 2, Err: errors.New("invalid request")},
+	}
+	emitted := 0
+	err = shipping.ProcessBatch(ctx, items, "A", func(result *domain.BatchItemResult) { emitted++ })
+	var quotaErr *domain.QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Shipments != 2 || quotaErr.Decision.Remaining != 1 || emitted != 0 {
+		t.Fatalf("expected the batch refused whole with 1 shipment left, got %v after %d results", err, emitted)
+	}
+
+	if _, err := shipping.ProcessShipment(ctx, request, "A"); err != nil {
+		t.Fatalf("expected the last shipment of the day booked, got %v", err)
+	}
+	if _, err := shipping.ProcessShipment(ctx, request, "A"); !errors.As(err, &quotaErr) {
+		t.Fatalf("expected a quota error, got %v", err)
+	}
+
+	report, _ := usage.Usage(ctx, "acme", *now)
+	if len(report) != 1 || report[0].Shipments != 3 {
+		t.Errorf("expected 3 shipments counted, got %+v", report)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+)
+
+const defaultRotationOverlap = 24 * time.Hour
+
+type APIKeyHandler struct {
+	keys       ports.APIKeyService
+	repository ports.APIKeyRepository
+}
+
+func NewAPIKeyHandler(keys ports.APIKeyService, repository ports.APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys:       keys,
+		repository: repository,
+	}
+}
+
+type apiKeyRequest struct {
+	TenantID  string     `json:"tenantId"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type rotateKeyRequest struct {
+	Overlap string `json:"overlap"`
+}
+
+// issuedKeyView carries the secret, which is only shown once.
+type issuedKeyView struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// operator reports whether the caller may manage keys: only admins of the
+// default tenant, which runs the deployment, may.
+func operator(w http.ResponseWriter, r *http.Request) bool {
+	if domain.TenantIDFromContext(r.Context()) != domain.DefaultTenantID {
+		respondWithError(w, http.StatusForbidden, "api keys are managed by the default tenant")
+		return false
+	}
+	return true
+}
+
+func (h *APIKeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	if !operator(w, r) {
+		return
+	}
+
+	var body apiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, bodyErrorStatus(err), "invalid request body")
+		return
+	}
+
+	key, secret, err := h.keys.IssueKey(r.Context(), body.TenantID, body.Name, body.Scopes, body.ExpiresAt)
+	if errors.Is(err, domain.ErrInvalidAPIKeyRequest) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, issuedKeyView{APIKey: key, Key: secret})
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if !operator(w, r) {
+		return
+	}
+
+	keys, err := h.repository.ListAPIKeys(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+
+	respondWithJSON(w, http.StatusOK, keys)
+}
+
+// RotateKey issues a replacement; the old key keeps working for the
+// overlap (default 24h), e.g. {"overlap": "1h"}.
+func (h *APIKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	if !operator(w, r) {
+		return
+	}
+
+	var body rotateKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			respondWithError(w, bodyErrorStatus(err), "invalid request body")
+			return
+		}
+	}
+
+	overlap := defaultRotationOverlap
+	if body.Overlap != "" {
+		parsed, err := time.ParseDuration(body.Overlap)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid overlap: "+err.Error())
+			return
+		}
+		overlap = parsed
+	}
+
+	key, secret, err := h.keys.RotateKey(r.Context(), r.PathValue("id"), overlap)
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, domain.ErrAPIKeyInactive):
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrInvalidAPIKeyRequest):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, issuedKeyView{APIKey: key, Key: secret})
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if !operator(w, r) {
+		return
+	}
+
+	err := h.keys.RevokeKey(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
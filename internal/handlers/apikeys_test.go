@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"testing"
+)
+
+func newAPIKeyMux() (*http.ServeMux, *service.APIKeyService) {
+	repo := testutil.NewMockAPIKeyRepository()
+	keys := service.NewAPIKeyService(repo)
+	handler := NewAPIKeyHandler(keys, repo)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/admin/api-keys", handler.IssueKey)
+	mux.HandleFunc("GET /api/v1/admin/api-keys", handler.ListKeys)
+	mux.HandleFunc("POST /api/v1/admin/api-keys/{id}/rotate", handler.RotateKey)
+	mux.HandleFunc("DELETE /api/v1/admin/api-keys/{id}", handler.RevokeKey)
+	return mux, keys
+}
+
+func TestAPIKeyHandler_Lifecycle(t *testing.T) {
+	mux, keys := newAPIKeyMux()
+
+	body := `{"name":"checkout","scopes":["shipments:create"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status code 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var issued struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &issued)
+	if issued.ID == "" || issued.Key == "" {
+		t.Fatalf("expected id and secret in response, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-keys", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if bytes.Contains(w.Body.Bytes(), []byte(issued.Key)) || bytes.Contains(w.Body.Bytes(), []byte(domain.HashAPIKey(issued.Key))) {
+		t.Fatalf("expected listing to hide secrets, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys/"+issued.ID+"/rotate", bytes.NewBufferString(`{"overlap":"1h"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status code 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var rotated struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &rotated)
+	if _, err := keys.Authenticate(context.Background(), issued.Key); err != nil {
+		t.Errorf("expected old key to work during overlap, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/"+rotated.ID, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status code 204, got %d", w.Code)
+	}
+	if _, err := keys.Authenticate(context.Background(), rotated.Key); err == nil {
+		t.Error("expected revoked key to be rejected")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys/"+rotated.ID+"/rotate", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status code 409 rotating a revoked key, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyHandler_Errors(t *testing.T) {
+	mux, _ := newAPIKeyMux()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"unknown scope", http.MethodPost, "/api/v1/admin/api-keys", `{"name":"x","scopes":["everything"]}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/api/v1/admin/api-keys", `{`, http.StatusBadRequest},
+		{"rotate missing key", http.MethodPost, "/api/v1/admin/api-keys/missing/rotate", ``, http.StatusNotFound},
+		{"revoke missing key", http.MethodDelete, "/api/v1/admin/api-keys/missing", ``, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestAPIKeyHandler_Tenants(t *testing.T) {
+	mux, _ := newAPIKeyMux()
+
+	body := `{"tenantId":"acme","name":"acme checkout","scopes":["shipments:create"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var issued struct {
+		TenantID string `json:"tenantId"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &issued)
+	if w.Code != http.StatusCreated || issued.TenantID != "acme" {
+		t.Fatalf("expected key issued for acme, got %d: %s", w.Code, w.Body.String())
+	}
+
+	ctx := domain.WithTenant(context.Background(), &domain.Tenant{ID: "acme"})
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-keys", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-default tenant, got %d", w.Code)
+	}
+}
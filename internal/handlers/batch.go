@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxBatchBodyBytes = 10 << 20
+	maxBatchItems     = 1000
+)
+
+type BatchHandler struct {
+	service  ports.ShippingService
+	profiles map[string]*domain.CSVProfile
+	timeout  time.Duration
+}
+
+func NewBatchHandler(service ports.ShippingService, profiles map[string]*domain.CSVProfile) *BatchHandler {
+	return &BatchHandler{
+		service:  service,
+		profiles: profiles,
+	}
+}
+
+// SetTimeout gives batches answered at once their own deadline in place of
+// the request timeout. Streamed batches have none.
+func (h *BatchHandler) SetTimeout(timeout time.Duration) {
+	h.timeout = timeout
+}
+
+type batchResponse struct {
+	Results []*domain.BatchItemResult `json:"results"`
+	Summary domain.BatchSummary       `json:"summary"`
+}
+
+// CreateBatch books a JSON array, NDJSON or CSV upload, chosen by
+// Content-Type. CSV columns are mapped with ?profile=. With
+// Accept: application/x-ndjson each result is streamed as it completes,
+// followed by a summary line; otherwise all results are returned at once in
+// item order.
+func (h *BatchHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+
+	items, err := h.decodeBatch(r)
+	if err != nil {
+		respondWithError(w, bodyErrorStatus(err), err.Error())
+		return
+	}
+	if len(items) == 0 {
+		respondWithError(w, http.StatusBadRequest, "batch is empty")
+		return
+	}
+	if len(items) > maxBatchItems {
+		respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch exceeds %d items", maxBatchItems))
+		return
+	}
+
+	provider := r.URL.Query().Get("provider")
+
+	if strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		h.streamBatch(w, r, items, provider)
+		return
+	}
+	if h.timeout > 0 {
+		setTimeout(w, h.timeout)
+	}
+
+	response := batchResponse{Results: []*domain.BatchItemResult{}}
+	err = h.service.ProcessBatch(r.Context(), items, provider, func(result *domain.BatchItemResult) {
+		response.Results = append(response.Results, result)
+		response.Summary.Add(result)
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	sort.SliceStable(response.Results, func(i, j int) bool {
+		if response.Results[i].Index != response.Results[j].Index {
+			return response.Results[i].Index < response.Results[j].Index
+		}
+		return response.Results[i].Provider < response.Results[j].Provider
+	})
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *BatchHandler) streamBatch(w http.ResponseWriter, r *http.Request, items []*domain.BatchItem, provider string) {
+	setTimeout(w, 0)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	started := false
+	start := func() {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+
+	var summary domain.BatchSummary
+	err := h.service.ProcessBatch(r.Context(), items, provider, func(result *domain.BatchItemResult) {
+		start()
+		summary.Add(result)
+		encoder.Encode(result)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	start()
+
+	encoder.Encode(map[string]domain.BatchSummary{"summary": summary})
+}
+
+func (h *BatchHandler) decodeBatch(r *http.Request) ([]*domain.BatchItem, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "", "application/json":
+		return decodeJSONBatch(r.Body)
+	case "application/x-ndjson", "application/ndjson":
+		return decodeNDJSONBatch(r.Body)
+	case "text/csv":
+		profile, err := h.profile(r.URL.Query().Get("profile"))
+		if err != nil {
+			return nil, err
+		}
+		return decodeCSVBatch(r.Body, profile)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+func (h *BatchHandler) profile(name string) (*domain.CSVProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	profile, exists := h.profiles[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown CSV profile %q", name)
+	}
+	return profile, nil
+}
+
+func decodeJSONBatch(body io.Reader) ([]*domain.BatchItem, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON array: %w", err)
+	}
+
+	items := make([]*domain.BatchItem, 0, len(raw))
+	for i, entry := range raw {
+		items = append(items, decodeBatchEntry(i, entry))
+	}
+	return items, nil
+}
+
+func decodeNDJSONBatch(body io.Reader) ([]*domain.BatchItem, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchBodyBytes)
+
+	var items []*domain.BatchItem
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		items = append(items, decodeBatchEntry(len(items), []byte(line)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+	return items, nil
+}
+
+func decodeBatchEntry(index int, entry []byte) *domain.BatchItem {
+	var request domain.GenericShippingRequest
+	if err := json.Unmarshal(entry, &request); err != nil {
+		return &domain.BatchItem{Index: index, Err: fmt.Errorf("invalid request: %w", err)}
+	}
+	return &domain.BatchItem{Index: index, Request: &request}
+}
+
+func decodeCSVBatch(body io.Reader, profile *domain.CSVProfile) ([]*domain.BatchItem, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var items []*domain.BatchItem
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		index := len(items)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			items = append(items, &domain.BatchItem{Index: index, Err: err})
+			continue
+		}
+
+		request, err := profile.Request(header, row)
+		if err != nil {
+			items = append(items, &domain.BatchItem{Index: index, Err: err})
+			continue
+		}
+		items = append(items, &domain.BatchItem{Index: index, Request: request})
+	}
+	return items, nil
+}
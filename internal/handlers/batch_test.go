@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/middleware"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBatchHandler() *BatchHandler {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.local"))
+
+	profiles := map[string]*domain.CSVProfile{
+		"warehouse": {
+			Columns:  map[string]string{"Order": "referenceNumbers", "City": "consignee.address.city", "Grams": "weight.value"},
+			Defaults: map[string]string{"weight.unit": "Grams"},
+		},
+	}
+	return NewBatchHandler(shippingService, profiles)
+}
+
+func TestBatchHandler_CreateBatch_JSON(t *testing.T) {
+	request, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	body := "[" + string(request) + `, {"weight": "heavy"}]`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/batch?provider=A", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	newBatchHandler().CreateBatch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response batchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response.Summary != (domain.BatchSummary{Total: 2, Succeeded: 1, Failed: 1}) {
+		t.Errorf("unexpected summary: %+v", response.Summary)
+	}
+	if response.Results[0].Index != 0 || !response.Results[0].Success || response.Results[0].TrackingID != "TRACK123" {
+		t.Errorf("expected item 0 to succeed, got %+v", response.Results[0])
+	}
+	if response.Results[1].Index != 1 || response.Results[1].Success || len(response.Results[1].Errors) == 0 {
+		t.Errorf("expected item 1 to report its decode error, got %+v", response.Results[1])
+	}
+}
+
+func TestBatchHandler_CreateBatch_OutlivesRequestTimeout(t *testing.T) {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	slow := testutil.NewMockShippingProvider("A", "http://a.local")
+	slow.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return &domain.ShipmentResponse{Provider: "A", Success: true, TrackingID: "TRACK123"}, nil
+		case <-ctx.Done():
+			return nil, &domain.ProviderError{Provider: "A", Category: domain.ErrorCategoryTimeout, Err: ctx.Err()}
+		}
+	})
+	shippingService.RegisterProvider(slow)
+	batchHandler := NewBatchHandler(shippingService, nil)
+	batchHandler.SetTimeout(time.Minute)
+	handler := middleware.Timeout(10*time.Millisecond, 0)(http.HandlerFunc(batchHandler.CreateBatch))
+
+	request, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	for _, accept := range []string{"application/json", "application/x-ndjson"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/batch?provider=A", strings.NewReader("["+string(request)+"]"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"succeeded":1`) {
+			t.Errorf("%s: expected the batch to outlive the request timeout, got %d: %s", accept, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestBatchHandler_CreateBatch_NDJSONStream(t *testing.T) {
+	request, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	body := string(request) + "\n\n" + string(request) + "\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	newBatchHandler().CreateBatch(w, req)
+
+	if w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("expected NDJSON response, got %q", w.Header().Get("Content-Type"))
+	}
+
+	var lines []map[string]json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(w.Body.Bytes()))
+	for scanner.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	// Two items broadcast to two providers, then the summary.
+	if len(lines) != 5 {
+		t.Fatalf("expected 4 results and a summary, got %d lines", len(lines))
+	}
+	var summary domain.BatchSummary
+	json.Unmarshal(lines[4]["summary"], &summary)
+	if summary.Total != 4 || summary.Succeeded != 4 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestBatchHandler_CreateBatch_CSV(t *testing.T) {
+	body := "Order,City,Grams\nSO-1,Dubai,1200\nSO-2,Abu Dhabi,heavy\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/batch?provider=A&profile=warehouse", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+	newBatchHandler().CreateBatch(w, req)
+
+	var response batchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(response.Results) != 2 || !response.Results[0].Success {
+		t.Fatalf("expected the first row to succeed, got %+v", response.Results)
+	}
+	if response.Results[1].Success || !strings.Contains(response.Results[1].Errors[0], "Grams") {
+		t.Errorf("expected the second row to report the invalid column, got %+v", response.Results[1])
+	}
+}
+
+func TestBatchHandler_CreateBatch_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{"malformed JSON", "/api/v1/shipments/batch", "application/json", `[{`, http.StatusBadRequest},
+		{"empty batch", "/api/v1/shipments/batch", "application/json", `[]`, http.StatusBadRequest},
+		{"unknown profile", "/api/v1/shipments/batch?profile=store", "text/csv", "Order\nSO-1\n", http.StatusBadRequest},
+		{"unsupported type", "/api/v1/shipments/batch", "application/xml", `<shipments/>`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			newBatchHandler().CreateBatch(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"strconv"
+)
+
+type JobHandler struct {
+	repository ports.JobRepository
+}
+
+func NewJobHandler(repository ports.JobRepository) *JobHandler {
+	return &JobHandler{
+		repository: repository,
+	}
+}
+
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.repository.FindJob(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrJobNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+// ListJobs is the admin listing, newest first, optionally by ?status=.
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	filter := domain.JobFilter{Status: r.URL.Query().Get("status")}
+	switch filter.Status {
+	case "", domain.JobQueued, domain.JobRunning, domain.JobCompleted, domain.JobFailed:
+	default:
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid status value %q", filter.Status))
+		return
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit value %q", value))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	jobs, err := h.repository.ListJobs(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if jobs == nil {
+		jobs = []*domain.Job{}
+	}
+
+	respondWithJSON(w, http.StatusOK, jobs)
+}
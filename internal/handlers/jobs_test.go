@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestJobHandler_AsyncSubmission(t *testing.T) {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	jobRepo := testutil.NewMockJobRepository()
+	runner := service.NewJobRunner(jobRepo, shippingService, time.Second)
+
+	handler := NewShippingHandler(shippingService)
+	handler.SetJobService(runner)
+	jobHandler := NewJobHandler(jobRepo)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/createShipping", handler.CreateShipment)
+	mux.HandleFunc("GET /api/v1/jobs", jobHandler.ListJobs)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", jobHandler.GetJob)
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A&async=true", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status code 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var submitted domain.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	location := w.Header().Get("Location")
+	if submitted.Status != domain.JobQueued || location != "/api/v1/jobs/"+submitted.ID {
+		t.Fatalf("expected queued job with Location header, got %s at %q", submitted.Status, location)
+	}
+
+	runner.ProcessDue(context.Background())
+
+	req = httptest.NewRequest(http.MethodGet, location, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var job struct {
+		Status  string                     `json:"status"`
+		Results []*domain.ShipmentResponse `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if job.Status != domain.JobCompleted || len(job.Results) != 1 || !job.Results[0].Success {
+		t.Errorf("expected completed job with one successful result, got %+v", job)
+	}
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"/api/v1/jobs?status=completed", http.StatusOK, 1},
+		{"/api/v1/jobs?status=failed", http.StatusOK, 0},
+		{"/api/v1/jobs?status=done", http.StatusBadRequest, 0},
+		{"/api/v1/jobs/missing", http.StatusNotFound, 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s: expected status code %d, got %d", tt.path, tt.expectedStatus, w.Code)
+			continue
+		}
+		if tt.expectedStatus == http.StatusOK {
+			var jobs []*domain.Job
+			json.Unmarshal(w.Body.Bytes(), &jobs)
+			if len(jobs) != tt.expectedCount {
+				t.Errorf("%s: expected %d jobs, got %d", tt.path, tt.expectedCount, len(jobs))
+			}
+		}
+	}
+}
+
+func TestJobHandler_AsyncSubmission_Rejected(t *testing.T) {
+	repo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(repo)
+	ineligible := testutil.NewMockShippingProvider("I", "http://i.local")
+	ineligible.SetCapabilities(domain.ProviderCapabilities{DestinationCountries: []string{"ZZ"}})
+	shippingService.RegisterProvider(ineligible)
+	jobRepo := testutil.NewMockJobRepository()
+	runner := service.NewJobRunner(jobRepo, shippingService, time.Second)
+
+	handler := NewShippingHandler(shippingService)
+	handler.SetJobService(runner)
+	v2 := NewV2Handler(shippingService, repo, service.NewTrackingService(repo), jobRepo)
+	v2.SetJobService(runner)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/createShipping", handler.CreateShipment)
+	mux.HandleFunc("POST /api/v2/shipments", v2.CreateShipment)
+
+	tests := []struct {
+		name           string
+		path           string
+		provider       string
+		expectedStatus int
+	}{
+		{"v1 unknown provider", "/api/v1/createShipping?provider=missing&async=true", "", http.StatusBadRequest},
+		{"v1 ineligible", "/api/v1/createShipping?provider=I&async=true", "", http.StatusUnprocessableEntity},
+		{"v2 unknown provider", "/api/v2/shipments", "missing", http.StatusNotFound},
+		{"v2 ineligible", "/api/v2/shipments", "I", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, v2ShipmentBody(tt.provider))
+		req.Header.Set("Prefer", "respond-async")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s: expected status code %d, got %d: %s", tt.name, tt.expectedStatus, w.Code, w.Body.String())
+		}
+	}
+
+	if jobs, _ := jobRepo.ListJobs(context.Background(), domain.JobFilter{}); len(jobs) != 0 {
+		t.Errorf("expected no queued jobs, got %d", len(jobs))
+	}
+}
+
+func TestShippingHandler_CreateShipment_AsyncNotEnabled(t *testing.T) {
+	handler := NewShippingHandler(service.NewShippingService(testutil.NewMockRepository()))
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?async=true", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status code 501, got %d", w.Code)
+	}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// specTypes maps each component schema to the Go type it documents.
+var specTypes = map[string]reflect.Type{
+	"GenericShippingRequest": reflect.TypeOf(domain.GenericShippingRequest{}),
+	"WeightInfo":             reflect.TypeOf(domain.WeightInfo{}),
+	"Party":                  reflect.TypeOf(domain.Party{}),
+	"Contact":                reflect.TypeOf(domain.Contact{}),
+	"Address":                reflect.TypeOf(domain.Address{}),
+	"Dimensions":             reflect.TypeOf(domain.Dimensions{}),
+	"AccountInfo":            reflect.TypeOf(domain.AccountInfo{}),
+	"CustomsDeclaration":     reflect.TypeOf(domain.CustomsDeclaration{}),
+	"DeclaredValue":          reflect.TypeOf(domain.DeclaredValue{}),
+	"Package":                reflect.TypeOf(domain.Package{}),
+	"ShipmentResponse":       reflect.TypeOf(domain.ShipmentResponse{}),
+	"MappingWarning":         reflect.TypeOf(domain.MappingWarning{}),
+	"TransformResult":        reflect.TypeOf(domain.TransformResult{}),
+	"CreateShipmentRequest":  reflect.TypeOf(createShipmentRequest{}),
+	"BatchItemResult":        reflect.TypeOf(domain.BatchItemResult{}),
+	"BatchSummary":           reflect.TypeOf(domain.BatchSummary{}),
+	"BatchResponse":          reflect.TypeOf(batchResponse{}),
+	"BroadcastSummary":       reflect.TypeOf(broadcastSummary{}),
+	"Shipment":               reflect.TypeOf(shipmentView{}),
+	"ShipmentList":           reflect.TypeOf(shipmentListResponse{}),
+	"StatusChange":           reflect.TypeOf(statusView{}),
+	"StatusUpdate":           reflect.TypeOf(statusUpdateRequest{}),
+	"Attempt":                reflect.TypeOf(attemptView{}),
+	"TrackingEventResult":    reflect.TypeOf(domain.TrackingEventResult{}),
+	"WebhookResponse":        reflect.TypeOf(webhookResponse{}),
+	"SubscriptionRequest":    reflect.TypeOf(subscriptionRequest{}),
+	"WebhookSubscription":    reflect.TypeOf(domain.WebhookSubscription{}),
+	"WebhookDelivery":        reflect.TypeOf(deliveryView{}),
+	"WebhookDeliveryAttempt": reflect.TypeOf(domain.WebhookDeliveryAttempt{}),
+	"ShipmentEvent":          reflect.TypeOf(domain.ShipmentEvent{}),
+	"Job":                    reflect.TypeOf(domain.Job{}),
+	"ProviderInfo":           reflect.TypeOf(domain.ProviderInfo{}),
+	"ProviderCapabilities":   reflect.TypeOf(domain.ProviderCapabilities{}),
+	"Problem":                reflect.TypeOf(Problem{}),
+	"SchemaError":            reflect.TypeOf(schemaErrorResponse{}),
+	"SchemaViolation":        reflect.TypeOf(domain.SchemaViolation{}),
+	"APIKey":                 reflect.TypeOf(domain.APIKey{}),
+	"IssuedAPIKey":           reflect.TypeOf(issuedKeyView{}),
+	"APIKeyRequest":          reflect.TypeOf(apiKeyRequest{}),
+	"RotateKeyRequest":       reflect.TypeOf(rotateKeyRequest{}),
+	"TenantUsage":            reflect.TypeOf(domain.TenantUsage{}),
+}
+
+type specSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       string                 `json:"type"`
+	Format     string                 `json:"format"`
+	Enum       []string               `json:"enum"`
+	Items      *specSchema            `json:"items"`
+	Properties map[string]*specSchema `json:"properties"`
+	AllOf      []*specSchema          `json:"allOf"`
+}
+
+type specDocument struct {
+	OpenAPI    string                            `json:"openapi"`
+	Paths      map[string]map[string]interface{} `json:"paths"`
+	Components struct {
+		Schemas map[string]*specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadSpec(t *testing.T) *specDocument {
+	t.Helper()
+	var spec specDocument
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return &spec
+}
+
+func (d *specDocument) resolve(t *testing.T, ref string) (string, *specSchema) {
+	t.Helper()
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	schema, ok := d.Components.Schemas[name]
+	if !ok {
+		t.Fatalf("unresolved $ref %q", ref)
+	}
+	return name, schema
+}
+
+func (d *specDocument) properties(t *testing.T, schema *specSchema) map[string]*specSchema {
+	properties := map[string]*specSchema{}
+	for _, part := range schema.AllOf {
+		if part.Ref != "" {
+			_, part = d.resolve(t, part.Ref)
+		}
+		for name, property := range d.properties(t, part) {
+			properties[name] = property
+		}
+	}
+	for name, property := range schema.Properties {
+		properties[name] = property
+	}
+	return properties
+}
+
+// jsonFields returns the fields encoding/json writes for typ, with embedded
+// structs flattened and outer fields taking precedence.
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	var embedded []reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			embedded = append(embedded, field)
+			continue
+		}
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	for _, field := range embedded {
+		inner := field.Type
+		if inner.Kind() == reflect.Pointer {
+			inner = inner.Elem()
+		}
+		for name, fieldType := range jsonFields(inner) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldType
+			}
+		}
+	}
+	return fields
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+func (d *specDocument) checkType(t *testing.T, path string, schema *specSchema, typ reflect.Type) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == rawMessageType || typ.Kind() == reflect.Interface {
+		return
+	}
+
+	if schema.Ref != "" {
+		name, target := d.resolve(t, schema.Ref)
+		if typ.Kind() == reflect.Struct && typ != timeType {
+			if specTypes[name] != typ {
+				t.Errorf("%s: spec refers to %s, Go type is %s", path, name, typ)
+			}
+			return
+		}
+		schema = target
+	}
+
+	var want string
+	switch {
+	case typ == timeType:
+		want = "string"
+		if schema.Format != "date-time" {
+			t.Errorf("%s: expected format date-time", path)
+		}
+	case typ.Kind() == reflect.String:
+		want = "string"
+	case typ.Kind() == reflect.Bool:
+		want = "boolean"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		want = "number"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		want = "integer"
+	case typ.Kind() == reflect.Slice:
+		want = "array"
+	case typ.Kind() == reflect.Map:
+		want = "object"
+	default:
+		t.Errorf("%s: Go type %s must be documented with a $ref", path, typ)
+		return
+	}
+
+	if schema.Type != want {
+		t.Errorf("%s: spec type %q, Go type %s needs %q", path, schema.Type, typ, want)
+		return
+	}
+	if want == "array" {
+		if schema.Items == nil {
+			t.Errorf("%s: array without items", path)
+			return
+		}
+		d.checkType(t, path+"[]", schema.Items, typ.Elem())
+	}
+}
+
+func TestOpenAPI_MatchesGoTypes(t *testing.T) {
+	spec := loadSpec(t)
+
+	for name, schema := range spec.Components.Schemas {
+		if _, ok := specTypes[name]; !ok && (len(schema.Properties) > 0 || len(schema.AllOf) > 0) && name != "Error" {
+			t.Errorf("schema %s has no Go type in specTypes", name)
+		}
+	}
+
+	for name, typ := range specTypes {
+		schema, ok := spec.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s missing from openapi.json", name)
+			continue
+		}
+
+		properties := spec.properties(t, schema)
+		fields := jsonFields(typ)
+		for field, fieldType := range fields {
+			property, ok := properties[field]
+			if !ok {
+				t.Errorf("%s.%s is in %s but not in the spec", name, field, typ)
+				continue
+			}
+			spec.checkType(t, name+"."+field, property, fieldType)
+		}
+		for property := range properties {
+			if _, ok := fields[property]; !ok {
+				t.Errorf("%s.%s is in the spec but not in %s", name, property, typ)
+			}
+		}
+	}
+}
+
+func TestOpenAPI_EnumsMatchDomain(t *testing.T) {
+	spec := loadSpec(t)
+	property := func(schema, field string) []string {
+		if field == "" {
+			return spec.Components.Schemas[schema].Enum
+		}
+		return spec.properties(t, spec.Components.Schemas[schema])[field].Enum
+	}
+
+	var statuses []string
+	for _, status := range domain.ShipmentStatuses {
+		statuses = append(statuses, string(status))
+	}
+
+	tests := []struct {
+		schema string
+		field  string
+		want   []string
+	}{
+		{"GenericShippingRequest", "productCode", domain.CanonicalCodes[domain.CodeFieldProduct]},
+		{"GenericShippingRequest", "serviceType", domain.CanonicalCodes[domain.CodeFieldService]},
+		{"GenericShippingRequest", "deliveryType", domain.CanonicalCodes[domain.CodeFieldDelivery]},
+		{"GenericShippingRequest", "contentType", domain.CanonicalCodes[domain.CodeFieldContent]},
+		{"WeightInfo", "unit", domain.WeightUnits},
+		{"Dimensions", "unit", domain.DimensionUnits},
+		{"ShipmentStatus", "", statuses},
+		{"EventType", "", domain.EventTypes},
+		{"MappingWarning", "reason", []string{domain.WarningDropped, domain.WarningTruncated, domain.WarningDefaulted, domain.WarningTransliterated}},
+		{"Job", "status", []string{domain.JobQueued, domain.JobRunning, domain.JobCompleted, domain.JobFailed}},
+		{"WebhookDelivery", "status", []string{domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead}},
+		{"StatusChange", "source", []string{domain.StatusSourceAPI, domain.StatusSourceWebhook, domain.StatusSourcePoller}},
+		{"Scope", "", domain.APIKeyScopes},
+	}
+
+	for _, tt := range tests {
+		got := append([]string(nil), property(tt.schema, tt.field)...)
+		want := append([]string(nil), tt.want...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.%s enum: spec %v, domain %v", tt.schema, tt.field, got, want)
+		}
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	OpenAPI(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var spec specDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("failed to unmarshal spec: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("expected OpenAPI 3, got %q", spec.OpenAPI)
+	}
+	for _, path := range []string{"/api/v1/createShipping", "/api/v2/shipments", "/api/v2/providers/{name}", "/api/v1/admin/api-keys", "/openapi.json"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("path %s missing from spec", path)
+		}
+	}
+
+	var refs []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, inner := range v {
+				if ref, ok := inner.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+				}
+				collect(inner)
+			}
+		case []interface{}:
+			for _, inner := range v {
+				collect(inner)
+			}
+		}
+	}
+	var document interface{}
+	json.Unmarshal(openAPISpec, &document)
+	collect(document)
+	for _, ref := range refs {
+		spec.resolve(t, ref)
+	}
+}
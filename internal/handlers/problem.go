@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"shipping-api/internal/core/domain"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body, used by the v2 API.
+type Problem struct {
+	Type       string   `json:"type"`
+	Title      string   `json:"title"`
+	Status     int      `json:"status"`
+	Detail     string   `json:"detail,omitempty"`
+	Instance   string   `json:"instance,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	Provider   string   `json:"provider,omitempty"`
+	TrackingID string   `json:"trackingId,omitempty"`
+}
+
+func newProblem(status int, problemType, title, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + problemType,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func badRequestProblem(detail string) *Problem {
+	return newProblem(http.StatusBadRequest, "invalid-request", "Invalid request", detail)
+}
+
+// bodyProblem describes a request body that could not be decoded.
+func bodyProblem(err error) *Problem {
+	if bodyErrorStatus(err) == http.StatusRequestEntityTooLarge {
+		return newProblem(http.StatusRequestEntityTooLarge, "request-too-large", "Request body too large", err.Error())
+	}
+	return badRequestProblem("invalid request body: " + err.Error())
+}
+
+// internalProblem logs err and answers with a generic detail, so database
+// and driver errors stay out of responses.
+func internalProblem(err error) *Problem {
+	log.Printf("internal error: %v", err)
+	return newProblem(http.StatusInternalServerError, "internal-error", "Internal server error", "the request could not be completed")
+}
+
+// unsavedProblem reports a shipment the carrier booked but that could not be
+// stored, with the carrier's tracking ID so it is not booked again.
+func unsavedProblem(response *domain.ShipmentResponse, err error) *Problem {
+	log.Printf("provider %s tracking ID %s: %v", response.Provider, response.TrackingID, err)
+	problem := newProblem(http.StatusInternalServerError, "shipment-not-saved", "Shipment booked but not saved", "the carrier booked the shipment but it could not be saved; do not resubmit it")
+	problem.Provider = response.Provider
+	problem.TrackingID = response.TrackingID
+	return problem
+}
+
+// problemForError maps service errors to problems: unknown providers are
+// 404, requests a carrier cannot take are 422, an exhausted quota is 429,
+// carrier timeouts are 504 and other carrier failures are 502.
+func problemForError(err error) *Problem {
+	var eligibilityErr *domain.EligibilityError
+	var mappingErr *domain.MappingError
+	var codeErr *domain.CodeTranslationError
+	var providerErr *domain.ProviderError
+	var transitionErr *domain.TransitionError
+	var quotaErr *domain.QuotaError
+
+	switch {
+	case errors.Is(err, domain.ErrProviderNotFound):
+		return newProblem(http.StatusNotFound, "provider-not-found", "Provider not found", err.Error())
+	case errors.Is(err, domain.ErrShipmentNotFound):
+		return newProblem(http.StatusNotFound, "shipment-not-found", "Shipment not found", err.Error())
+	case errors.Is(err, domain.ErrJobNotFound):
+		return newProblem(http.StatusNotFound, "job-not-found", "Job not found", err.Error())
+	case errors.Is(err, domain.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidStatus):
+		return badRequestProblem(err.Error())
+	case errors.As(err, &eligibilityErr):
+		problem := newProblem(http.StatusUnprocessableEntity, "provider-ineligible", "Provider cannot handle this shipment", err.Error())
+		problem.Provider = eligibilityErr.Provider
+		problem.Errors = eligibilityErr.Reasons
+		return problem
+	case errors.As(err, &mappingErr), errors.As(err, &codeErr):
+		return newProblem(http.StatusUnprocessableEntity, "mapping-failed", "Request cannot be mapped to the provider format", err.Error())
+	case errors.As(err, &quotaErr):
+		return newProblem(http.StatusTooManyRequests, "quota-exceeded", "Daily shipment quota exceeded", err.Error())
+	case errors.As(err, &transitionErr):
+		return newProblem(http.StatusConflict, "invalid-transition", "Invalid status transition", err.Error())
+	case errors.As(err, &providerErr) && providerErr.Category == domain.ErrorCategoryTimeout:
+		problem := newProblem(http.StatusGatewayTimeout, "carrier-timeout", "Carrier did not respond in time", err.Error())
+		problem.Provider = providerErr.Provider
+		return problem
+	case errors.As(err, &providerErr):
+		problem := newProblem(http.StatusBadGateway, "carrier-unavailable", "Carrier request failed", err.Error())
+		problem.Provider = providerErr.Provider
+		return problem
+	default:
+		return internalProblem(err)
+	}
+}
+
+// problemForRejection describes a carrier that answered but did not book the
+// shipment: its own 5xx errors are 502, anything else is a rejection of the
+// request and 422.
+func problemForRejection(response *domain.ShipmentResponse) *Problem {
+	problem := newProblem(http.StatusUnprocessableEntity, "carrier-rejected", "Carrier rejected the shipment", response.Message)
+	if response.Exchange != nil && response.Exchange.StatusCode >= 500 {
+		problem = newProblem(http.StatusBadGateway, "carrier-error", "Carrier returned an error", response.Message)
+	}
+	problem.Provider = response.Provider
+	return problem
+}
+
+func respondWithProblem(w http.ResponseWriter, r *http.Request, problem *Problem) {
+	problem.Instance = r.URL.Path
+	body, err := json.Marshal(problem)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(body)
+}
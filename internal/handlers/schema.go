@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+	"shipping-api/internal/core/domain"
+)
+
+// RequestSchema serves the JSON Schema for the generic shipping request.
+// Only the current version is published.
+func RequestSchema(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("version") != domain.RequestSchemaVersion {
+		respondWithError(w, http.StatusNotFound, "unknown schema version "+r.PathValue("version"))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, requestSchema)
+}
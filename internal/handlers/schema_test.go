@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"testing"
+)
+
+func TestRequestSchema_Served(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schemas/generic-shipping-request/{version}", RequestSchema)
+
+	req := httptest.NewRequest(http.MethodGet, domain.RequestSchemaID, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var schema domain.JSONSchema
+	if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+	if w.Code != http.StatusOK || schema.ID != domain.RequestSchemaID || schema.Properties["consignee"] == nil {
+		t.Fatalf("unexpected schema %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/schemas/generic-shipping-request/v0", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestShippingHandler_CreateShipment_StrictDecoding(t *testing.T) {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	handler := NewShippingHandler(shippingService)
+
+	var body map[string]interface{}
+	encoded, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	json.Unmarshal(encoded, &body)
+	consignee := body["consignee"].(map[string]interface{})
+	consignee["contact"].(map[string]interface{})["mobileNo"] = "+919441234567"
+	body["numberOfPieces"] = "1"
+	encoded, _ = json.Marshal(body)
+
+	send := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(encoded))
+		w := httptest.NewRecorder()
+		handler.CreateShipment(w, req)
+		return w
+	}
+
+	if w := send("/api/v1/createShipping?provider=A&dryRun=true&strict=true"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status code 400, got %d", w.Code)
+	} else {
+		var response schemaErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		want := []domain.SchemaViolation{
+			{Pointer: "/consignee/contact/mobileNo", Message: "unknown field"},
+			{Pointer: "/numberOfPieces", Message: "expected integer, got string"},
+		}
+		if len(response.Violations) != len(want) || response.Violations[0] != want[0] || response.Violations[1] != want[1] {
+			t.Errorf("unexpected violations %+v", response.Violations)
+		}
+	}
+
+	body["numberOfPieces"] = 1
+	encoded, _ = json.Marshal(body)
+	if w := send("/api/v1/createShipping?provider=A&dryRun=true"); w.Code != http.StatusOK {
+		t.Errorf("expected lenient decoding to ignore the unknown field, got %d", w.Code)
+	}
+
+	handler.SetStrictDecoding(true)
+	if w := send("/api/v1/createShipping?provider=A&dryRun=true"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected strict decoding by default, got %d", w.Code)
+	}
+}
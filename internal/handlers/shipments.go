@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"strconv"
+	"time"
+)
+
+type ShipmentHandler struct {
+	repository      ports.ShipmentRepository
+	trackingService ports.TrackingService
+}
+
+func NewShipmentHandler(repository ports.ShipmentRepository, trackingService ports.TrackingService) *ShipmentHandler {
+	return &ShipmentHandler{
+		repository:      repository,
+		trackingService: trackingService,
+	}
+}
+
+type shipmentView struct {
+	ID                 string          `json:"id"`
+	Provider           string          `json:"provider"`
+	Success            bool            `json:"success"`
+	TrackingID         string          `json:"trackingId,omitempty"`
+	AWB                string          `json:"awb,omitempty"`
+	ReferenceNumbers   []string        `json:"referenceNumbers,omitempty"`
+	DestinationCountry string          `json:"destinationCountry,omitempty"`
+	Status             string          `json:"status"`
+	StatusUpdatedAt    time.Time       `json:"statusUpdatedAt"`
+	CreatedAt          time.Time       `json:"createdAt"`
+	GenericPayload     json.RawMessage `json:"genericPayload,omitempty"`
+	TransformedPayload json.RawMessage `json:"transformedPayload,omitempty"`
+	ProviderResponse   json.RawMessage `json:"providerResponse,omitempty"`
+	RawResponse        string          `json:"rawResponse,omitempty"`
+	Attempts           []attemptView   `json:"attempts,omitempty"`
+	Timeline           []statusView    `json:"timeline,omitempty"`
+}
+
+type statusView struct {
+	FromStatus  string    `json:"fromStatus,omitempty"`
+	Status      string    `json:"status"`
+	Source      string    `json:"source"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+type statusUpdateRequest struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+type attemptView struct {
+	ID              string          `json:"id"`
+	RequestID       string          `json:"requestId"`
+	Provider        string          `json:"provider"`
+	AttemptNumber   int             `json:"attemptNumber"`
+	Success         bool            `json:"success"`
+	ResponseStatus  int             `json:"responseStatus,omitempty"`
+	DurationMs      int64           `json:"durationMs"`
+	ErrorCategory   string          `json:"errorCategory,omitempty"`
+	ErrorMessage    string          `json:"errorMessage,omitempty"`
+	RequestBody     json.RawMessage `json:"requestBody,omitempty"`
+	ResponseHeaders json.RawMessage `json:"responseHeaders,omitempty"`
+	ResponseBody    string          `json:"responseBody,omitempty"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+type shipmentListResponse struct {
+	Data       []shipmentView `json:"data"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+func (h *ShipmentHandler) GetShipment(w http.ResponseWriter, r *http.Request) {
+	view, err := loadShipmentView(r.Context(), h.repository, r.PathValue("id"))
+	if errors.Is(err, domain.ErrShipmentNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+// loadShipmentView returns the shipment with its payloads, attempts and
+// status timeline.
+func loadShipmentView(ctx context.Context, repository ports.ShipmentRepository, id string) (*shipmentView, error) {
+	record, err := repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := repository.FindAttemptsByShipmentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := repository.FindStatusHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	view := newShipmentView(record, true)
+	for _, attempt := range attempts {
+		view.Attempts = append(view.Attempts, newAttemptView(attempt))
+	}
+	for _, change := range history {
+		view.Timeline = append(view.Timeline, newStatusView(change))
+	}
+
+	return &view, nil
+}
+
+func (h *ShipmentHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var body statusUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, bodyErrorStatus(err), "invalid request body")
+		return
+	}
+
+	status, err := domain.ParseShipmentStatus(body.Status)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	change := &domain.StatusChange{
+		ShipmentID:  r.PathValue("id"),
+		ToStatus:    status,
+		Source:      domain.StatusSourceAPI,
+		Description: body.Description,
+		Location:    body.Location,
+		OccurredAt:  body.OccurredAt,
+	}
+
+	var transitionErr *domain.TransitionError
+	err = h.trackingService.UpdateStatus(r.Context(), change)
+	switch {
+	case errors.Is(err, domain.ErrShipmentNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.As(err, &transitionErr):
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, newStatusView(change))
+}
+
+func (h *ShipmentHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseShipmentFilter(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.repository.Search(r.Context(), filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := shipmentListResponse{
+		Data:       make([]shipmentView, 0, len(page.Records)),
+		NextCursor: page.NextCursor,
+	}
+	for _, record := range page.Records {
+		response.Data = append(response.Data, newShipmentView(record, false))
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func parseShipmentFilter(query url.Values) (domain.ShipmentFilter, error) {
+	filter := domain.ShipmentFilter{
+		Provider:        query.Get("provider"),
+		TrackingID:      query.Get("trackingId"),
+		AWB:             query.Get("awb"),
+		ReferenceNumber: query.Get("reference"),
+		ConsigneeEmail:  query.Get("consigneeEmail"),
+		Destination:     query.Get("destinationCountry"),
+		Cursor:          query.Get("cursor"),
+		SortOrder:       domain.SortDescending,
+	}
+
+	if value := query.Get("status"); value != "" {
+		status, err := domain.ParseShipmentStatus(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = status
+	}
+
+	if value := query.Get("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid success value %q", value)
+		}
+		filter.Success = &success
+	}
+
+	for name, target := range map[string]*time.Time{"createdFrom": &filter.CreatedFrom, "createdTo": &filter.CreatedTo} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s value %q: expected RFC 3339", name, value)
+			}
+			*target = parsed
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit value %q", value)
+		}
+		filter.Limit = limit
+	}
+
+	switch sortOrder := query.Get("sort"); sortOrder {
+	case "":
+	case domain.SortAscending, domain.SortDescending:
+		filter.SortOrder = sortOrder
+	default:
+		return filter, fmt.Errorf("invalid sort value %q: expected asc or desc", sortOrder)
+	}
+
+	return filter, nil
+}
+
+func newShipmentView(record *domain.ShipmentRecord, includePayloads bool) shipmentView {
+	view := shipmentView{
+		ID:                 record.ID,
+		Provider:           record.Provider,
+		Success:            record.Success,
+		TrackingID:         record.TrackingID,
+		AWB:                record.AWB,
+		ReferenceNumbers:   record.ReferenceNumbers,
+		DestinationCountry: record.DestinationCountry,
+		Status:             string(record.Status),
+		StatusUpdatedAt:    record.StatusUpdatedAt,
+		CreatedAt:          record.CreatedAt,
+	}
+
+	if includePayloads {
+		view.GenericPayload = rawJSON(record.GenericPayload)
+		view.TransformedPayload = rawJSON(record.TransformedPayload)
+		view.ProviderResponse = rawJSON(record.ProviderResponse)
+		view.RawResponse = string(record.RawResponse)
+	}
+
+	return view
+}
+
+func newStatusView(change *domain.StatusChange) statusView {
+	return statusView{
+		FromStatus:  string(change.FromStatus),
+		Status:      string(change.ToStatus),
+		Source:      change.Source,
+		Description: change.Description,
+		Location:    change.Location,
+		OccurredAt:  change.OccurredAt,
+	}
+}
+
+func newAttemptView(attempt *domain.ShipmentAttempt) attemptView {
+	return attemptView{
+		ID:              attempt.ID,
+		RequestID:       attempt.RequestID,
+		Provider:        attempt.Provider,
+		AttemptNumber:   attempt.AttemptNumber,
+		Success:         attempt.Success,
+		ResponseStatus:  attempt.ResponseStatus,
+		DurationMs:      attempt.DurationMs,
+		ErrorCategory:   attempt.ErrorCategory,
+		ErrorMessage:    attempt.ErrorMessage,
+		RequestBody:     rawJSON(attempt.RequestBody),
+		ResponseHeaders: rawJSON(attempt.ResponseHeaders),
+		ResponseBody:    string(attempt.ResponseBody),
+		CreatedAt:       attempt.CreatedAt,
+	}
+}
+
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return json.RawMessage(data)
+}
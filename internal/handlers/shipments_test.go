@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seedShipments(t *testing.T, repo *testutil.MockRepository) []*domain.ShipmentRecord {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var records []*domain.ShipmentRecord
+
+	for i, tc := range []struct {
+		provider string
+		tracking string
+		email    string
+	}{
+		{"A", "A-TRACK-1", "first@test.com"},
+		{"B", "B-TRACK-2", "second@test.com"},
+		{"A", "A-TRACK-3", "third@test.com"},
+	} {
+		request := testutil.CreateSampleShippingRequest()
+		request.Consignee.Contact.EmailAddress = tc.email
+		genericPayload, _ := json.Marshal(request)
+		providerResponse, _ := json.Marshal(domain.ShipmentResponse{Provider: tc.provider, Success: true, TrackingID: tc.tracking})
+
+		record := &domain.ShipmentRecord{
+			ID:                 string(rune('a' + i)),
+			Provider:           tc.provider,
+			GenericPayload:     genericPayload,
+			TransformedPayload: []byte(`{}`),
+			ProviderResponse:   providerResponse,
+			RawResponse:        []byte("not json"),
+			Success:            true,
+			CreatedAt:          base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := repo.Save(context.Background(), record); err != nil {
+			t.Fatalf("failed to seed record: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func newShipmentMux(repo *testutil.MockRepository) *http.ServeMux {
+	handler := NewShipmentHandler(repo, service.NewTrackingService(repo))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/shipments", handler.ListShipments)
+	mux.HandleFunc("GET /api/v1/shipments/{id}", handler.GetShipment)
+	mux.HandleFunc("POST /api/v1/shipments/{id}/status", handler.UpdateStatus)
+	return mux
+}
+
+func TestShipmentHandler_GetShipment(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	records := seedShipments(t, mockRepo)
+	mux := newShipmentMux(mockRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments/"+records[1].ID, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d", w.Code)
+	}
+
+	var view shipmentView
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if view.TrackingID != "B-TRACK-2" {
+		t.Errorf("expected tracking ID B-TRACK-2, got %s", view.TrackingID)
+	}
+
+	if view.RawResponse != "not json" {
+		t.Errorf("expected raw response to be returned as text, got %s", view.RawResponse)
+	}
+
+	if len(view.GenericPayload) == 0 {
+		t.Error("expected generic payload in detail view")
+	}
+}
+
+func TestShipmentHandler_GetShipment_NotFound(t *testing.T) {
+	mux := newShipmentMux(testutil.NewMockRepository())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments/missing", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestShipmentHandler_ListShipments_Filters(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	seedShipments(t, mockRepo)
+	mux := newShipmentMux(mockRepo)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"c", "b", "a"}},
+		{"?sort=asc", []string{"a", "b", "c"}},
+		{"?provider=A", []string{"c", "a"}},
+		{"?trackingId=B-TRACK-2", []string{"b"}},
+		{"?consigneeEmail=THIRD@test.com", []string{"c"}},
+		{"?createdFrom=2026-01-01T12:01:00Z", []string{"c", "b"}},
+		{"?reference=Ref1", []string{"c", "b", "a"}},
+		{"?success=false", []string{}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments"+tt.query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status code 200, got %d", tt.query, w.Code)
+			continue
+		}
+
+		var response shipmentListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", tt.query, err)
+		}
+
+		if len(response.Data) != len(tt.expected) {
+			t.Errorf("%s: expected %d shipments, got %d", tt.query, len(tt.expected), len(response.Data))
+			continue
+		}
+		for i, id := range tt.expected {
+			if response.Data[i].ID != id {
+				t.Errorf("%s: expected shipment %s at position %d, got %s", tt.query, id, i, response.Data[i].ID)
+			}
+		}
+	}
+}
+
+func TestShipmentHandler_ListShipments_CursorPagination(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	seedShipments(t, mockRepo)
+	mux := newShipmentMux(mockRepo)
+
+	var ids []string
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments?limit=2&cursor="+cursor, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		var response shipmentListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		for _, view := range response.Data {
+			ids = append(ids, view.ID)
+		}
+		if response.NextCursor == "" {
+			break
+		}
+		cursor = response.NextCursor
+	}
+
+	if len(ids) != 3 || ids[0] != "c" || ids[1] != "b" || ids[2] != "a" {
+		t.Errorf("expected pages to cover c, b, a in order, got %v", ids)
+	}
+}
+
+func TestShipmentHandler_ListShipments_InvalidParameters(t *testing.T) {
+	mux := newShipmentMux(testutil.NewMockRepository())
+
+	for _, query := range []string{"?success=maybe", "?createdFrom=yesterday", "?limit=-1", "?sort=sideways", "?cursor=not-a-cursor"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments"+query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestShipmentHandler_UpdateStatus(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	records := seedShipments(t, mockRepo)
+	mux := newShipmentMux(mockRepo)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"status": "picked_up", "location": "Dubai"}`, http.StatusOK},
+		{`{"status": "cancelled"}`, http.StatusConflict},
+		{`{"status": "lost"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/"+records[0].ID+"/status", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("%s: expected status code %d, got %d", tc.body, tc.code, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments/"+records[0].ID, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var view shipmentView
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if view.Status != string(domain.StatusPickedUp) {
+		t.Errorf("expected status picked_up, got %s", view.Status)
+	}
+	if len(view.Timeline) != 2 {
+		t.Fatalf("expected 2 timeline entries, got %d", len(view.Timeline))
+	}
+	if view.Timeline[0].Status != string(domain.StatusCreated) || view.Timeline[1].Location != "Dubai" {
+		t.Errorf("unexpected timeline: %+v", view.Timeline)
+	}
+}
+
+func TestShipmentHandler_UpdateStatus_NotFound(t *testing.T) {
+	mux := newShipmentMux(testutil.NewMockRepository())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/missing/status", strings.NewReader(`{"status": "picked_up"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
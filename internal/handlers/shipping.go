@@ -2,13 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"shipping-api/internal/core/domain"
 	"shipping-api/internal/core/ports"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var requestSchema = domain.RequestSchema()
+
 type ShippingHandler struct {
 	service ports.ShippingService
+	jobs    ports.JobService
+	strict  bool
 }
 
 func NewShippingHandler(service ports.ShippingService) *ShippingHandler {
@@ -17,24 +28,69 @@ func NewShippingHandler(service ports.ShippingService) *ShippingHandler {
 	}
 }
 
+// SetJobService enables ?async=true submissions.
+func (h *ShippingHandler) SetJobService(jobs ports.JobService) {
+	h.jobs = jobs
+}
+
+// SetStrictDecoding validates every request body against the published
+// schema. Without it, clients opt in per request with ?strict=true.
+func (h *ShippingHandler) SetStrictDecoding(strict bool) {
+	h.strict = strict
+}
+
+type schemaErrorResponse struct {
+	Error      string                   `json:"error"`
+	Violations []domain.SchemaViolation `json:"violations"`
+}
+
 func (h *ShippingHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondWithError(w, bodyErrorStatus(err), "invalid request body")
+		return
+	}
+
+	if h.strict || r.URL.Query().Get("strict") == "true" {
+		var schemaErr *domain.SchemaError
+		if errors.As(requestSchema.Validate(body), &schemaErr) {
+			respondWithJSON(w, http.StatusBadRequest, schemaErrorResponse{Error: "request does not match schema " + domain.RequestSchemaID, Violations: schemaErr.Violations})
+			return
+		}
+	}
+
 	var request domain.GenericShippingRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	if err := json.Unmarshal(body, &request); err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
 	provider := r.URL.Query().Get("provider")
 
+	if r.URL.Query().Get("dryRun") == "true" {
+		h.transformShipment(w, r, &request, provider)
+		return
+	}
+
+	if r.URL.Query().Get("async") == "true" {
+		h.submitJob(w, r, &request, provider)
+		return
+	}
+
+	if provider == "" && strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.streamBroadcast(w, r, &request)
+		return
+	}
+
 	if provider == "" {
 		responses, err := h.service.BroadcastShipment(r.Context(), &request)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, err.Error())
+			respondWithServiceError(w, err)
 			return
 		}
 		respondWithJSON(w, http.StatusOK, responses)
@@ -43,13 +99,182 @@ func (h *ShippingHandler) CreateShipment(w http.ResponseWriter, r *http.Request)
 
 	response, err := h.service.ProcessShipment(r.Context(), &request, provider)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithServiceError(w, err)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+func (h *ShippingHandler) transformShipment(w http.ResponseWriter, r *http.Request, request *domain.GenericShippingRequest, provider string) {
+	results, err := h.service.TransformShipment(r.Context(), request, provider)
+	if errors.Is(err, domain.ErrProviderNotFound) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if provider != "" && len(results) == 1 {
+		respondWithJSON(w, http.StatusOK, results[0])
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+type broadcastSummary struct {
+	RequestID string `json:"requestId,omitempty"`
+	Total     int    `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// streamBroadcast sends a "response" Server-Sent Event per provider as it
+// answers, then a "summary" event. When the client disconnects the request
+// context is cancelled, which cancels the provider calls still in flight.
+func (h *ShippingHandler) streamBroadcast(w http.ResponseWriter, r *http.Request, request *domain.GenericShippingRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	setTimeout(w, 0)
+
+	// The stream starts with the first event, so a refused quota can still
+	// be answered with an error status.
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+	}
+
+	var summary broadcastSummary
+	err := h.service.StreamBroadcast(r.Context(), request, func(response *domain.ShipmentResponse) {
+		start()
+		summary.RequestID = response.RequestID
+		summary.Total++
+		if response.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		writeEvent(w, "response", response)
+		flusher.Flush()
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	start()
+
+	if r.Context().Err() != nil {
+		return
+	}
+	writeEvent(w, "summary", summary)
+	flusher.Flush()
+}
+
+func writeEvent(w http.ResponseWriter, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte(`{"error":"internal server error"}`)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// setTimeout moves the request deadline set by the timeout middleware, if w
+// goes through it. Zero removes the deadline.
+func setTimeout(w http.ResponseWriter, timeout time.Duration) {
+	for {
+		if rw, ok := w.(interface{ SetTimeout(time.Duration) }); ok {
+			rw.SetTimeout(timeout)
+			return
+		}
+		rw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = rw.Unwrap()
+	}
+}
+
+func (h *ShippingHandler) submitJob(w http.ResponseWriter, r *http.Request, request *domain.GenericShippingRequest, provider string) {
+	if h.jobs == nil {
+		respondWithError(w, http.StatusNotImplemented, "async submission is not enabled")
+		return
+	}
+
+	job, err := h.jobs.SubmitShipment(r.Context(), request, provider)
+	if errors.Is(err, domain.ErrProviderNotFound) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, statusForError(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	respondWithJSON(w, http.StatusAccepted, job)
+}
+
+func statusForError(err error) int {
+	var eligibilityErr *domain.EligibilityError
+	var mappingErr *domain.MappingError
+	var codeErr *domain.CodeTranslationError
+	var quotaErr *domain.QuotaError
+	if errors.As(err, &eligibilityErr) || errors.As(err, &mappingErr) || errors.As(err, &codeErr) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.As(err, &quotaErr) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+func respondWithServiceError(w http.ResponseWriter, err error) {
+	setQuotaHeaders(w, err)
+	respondWithError(w, statusForError(err), err.Error())
+}
+
+// setQuotaHeaders describes a refused daily quota with the same headers the
+// rate limiter sends, including Retry-After.
+func setQuotaHeaders(w http.ResponseWriter, err error) {
+	var quotaErr *domain.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return
+	}
+
+	decision := quotaErr.Decision
+	reset := strconv.Itoa(max(int(math.Ceil(time.Until(decision.Reset).Seconds())), 1))
+	header := w.Header()
+	header.Add("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, int(decision.Window.Seconds())))
+	header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	header.Set("RateLimit-Reset", reset)
+	header.Set("Retry-After", reset)
+}
+
+// bodyErrorStatus is 413 for a body over the size limit and 400 for any
+// other unreadable body.
+func bodyErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
 }
@@ -120,6 +120,20 @@ func TestShippingHandler_CreateShipment_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestShippingHandler_CreateShipment_BodyTooLarge(t *testing.T) {
+	handler := NewShippingHandler(service.NewShippingService(testutil.NewMockRepository()))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A", bytes.NewBufferString(`{"weight": {"value": 1000}}`))
+	w := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(w, req.Body, 8)
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status code 413, got %d", w.Code)
+	}
+}
+
 func TestShippingHandler_CreateShipment_MethodNotAllowed(t *testing.T) {
 	mockRepo := testutil.NewMockRepository()
 	shippingService := service.NewShippingService(mockRepo)
@@ -165,6 +179,10 @@ func TestShippingHandler_CreateShipment_ProviderNotFound(t *testing.T) {
 
 type mockFailingService struct{}
 
+func (m *mockFailingService) ValidateShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) error {
+	return nil
+}
+
 func (m *mockFailingService) ProcessShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) (*domain.ShipmentResponse, error) {
 	return nil, errors.New("service error")
 }
@@ -173,6 +191,26 @@ func (m *mockFailingService) BroadcastShipment(ctx context.Context, request *dom
 	return nil, errors.New("broadcast error")
 }
 
+func (m *mockFailingService) TransformShipment(ctx context.Context, request *domain.GenericShippingRequest, providerName string) ([]*domain.TransformResult, error) {
+	return nil, errors.New("transform error")
+}
+
+func (m *mockFailingService) StreamBroadcast(ctx context.Context, request *domain.GenericShippingRequest, emit func(*domain.ShipmentResponse)) error {
+	emit(&domain.ShipmentResponse{Provider: "A", Message: "broadcast error"})
+	return nil
+}
+
+func (m *mockFailingService) ProcessBatch(ctx context.Context, items []*domain.BatchItem, providerName string, emit func(*domain.BatchItemResult)) error {
+	for _, item := range items {
+		emit(&domain.BatchItemResult{Index: item.Index, Errors: []string{"batch error"}})
+	}
+	return nil
+}
+
+func (m *mockFailingService) Providers(ctx context.Context) []domain.ProviderInfo {
+	return nil
+}
+
 func TestShippingHandler_CreateShipment_ServiceError(t *testing.T) {
 	handler := NewShippingHandler(&mockFailingService{})
 
@@ -206,3 +244,103 @@ func TestShippingHandler_CreateShipment_BroadcastError(t *testing.T) {
 		t.Errorf("expected status code 500, got %d", w.Code)
 	}
 }
+
+func TestShippingHandler_CreateShipment_IneligibleProvider(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+
+	mockProvider := testutil.NewMockShippingProvider("A", "http://a.local")
+	mockProvider.SetCapabilities(domain.ProviderCapabilities{DestinationCountries: []string{"AE"}, SupportsInsurance: true})
+	shippingService.RegisterProvider(mockProvider)
+
+	handler := NewShippingHandler(shippingService)
+
+	request := testutil.CreateSampleShippingRequest()
+	requestBody, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status code 422, got %d", w.Code)
+	}
+}
+
+func TestShippingHandler_CreateShipment_DryRun(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+
+	called := false
+	mockProvider := testutil.NewMockShippingProvider("A", "http://a.local")
+	mockProvider.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		called = true
+		return &domain.ShipmentResponse{Provider: "A", Success: true}, nil
+	})
+	shippingService.RegisterProvider(mockProvider)
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.local"))
+
+	handler := NewShippingHandler(shippingService)
+
+	request := testutil.CreateSampleShippingRequest()
+	requestBody, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?dryRun=true", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d", w.Code)
+	}
+
+	var results []*domain.TransformResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Provider != "A" || results[1].Provider != "B" {
+		t.Errorf("expected results ordered A, B, got %s, %s", results[0].Provider, results[1].Provider)
+	}
+
+	for _, result := range results {
+		if !result.Eligible {
+			t.Errorf("expected provider %s to be eligible, got errors %v", result.Provider, result.Errors)
+		}
+		if len(result.Payload) == 0 {
+			t.Errorf("expected payload for provider %s", result.Provider)
+		}
+	}
+
+	if called {
+		t.Error("expected no provider call in dry-run mode")
+	}
+
+	if mockRepo.GetRecordCount() != 0 {
+		t.Errorf("expected 0 records in repository, got %d", mockRepo.GetRecordCount())
+	}
+}
+
+func TestShippingHandler_CreateShipment_DryRunUnknownProvider(t *testing.T) {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	handler := NewShippingHandler(shippingService)
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?dryRun=true&provider=unknown", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status code 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
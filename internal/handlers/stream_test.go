@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseEvents(t *testing.T, body []byte) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	return events
+}
+
+func TestShippingHandler_CreateShipment_StreamBroadcast(t *testing.T) {
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	failing := testutil.NewMockShippingProvider("B", "http://b.local")
+	failing.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return &domain.ShipmentResponse{Provider: "B", Message: "rejected"}, nil
+	})
+	shippingService.RegisterProvider(failing)
+	handler := NewShippingHandler(shippingService)
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping", bytes.NewBuffer(requestBody))
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	handler.CreateShipment(w, req)
+
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", w.Header().Get("Content-Type"))
+	}
+
+	events := parseEvents(t, w.Body.Bytes())
+	if len(events) != 3 || events[0].name != "response" || events[1].name != "response" || events[2].name != "summary" {
+		t.Fatalf("expected two response events and a summary, got %+v", events)
+	}
+
+	var summary broadcastSummary
+	if err := json.Unmarshal([]byte(events[2].data), &summary); err != nil {
+		t.Fatalf("failed to unmarshal summary: %v", err)
+	}
+	if summary.Total != 2 || summary.Succeeded != 1 || summary.Failed != 1 || summary.RequestID == "" {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestShippingHandler_CreateShipment_StreamClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shippingService := service.NewShippingService(testutil.NewMockRepository())
+	slow := testutil.NewMockShippingProvider("A", "http://a.local")
+	slow.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		<-ctx.Done()
+		return nil, &domain.ProviderError{Provider: "A", Category: domain.ErrorCategoryNetwork, Err: ctx.Err()}
+	})
+	shippingService.RegisterProvider(slow)
+	disconnecting := testutil.NewMockShippingProvider("B", "http://b.local")
+	disconnecting.SetCreateShipmentFunc(func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		cancel()
+		return &domain.ShipmentResponse{Provider: "B", Success: true}, nil
+	})
+	shippingService.RegisterProvider(disconnecting)
+	handler := NewShippingHandler(shippingService)
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping", bytes.NewBuffer(requestBody)).WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	handler.CreateShipment(w, req)
+
+	for _, event := range parseEvents(t, w.Body.Bytes()) {
+		if event.name == "summary" {
+			t.Error("expected no summary after the client disconnected")
+		}
+	}
+}
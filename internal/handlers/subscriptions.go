@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"strconv"
+)
+
+type SubscriptionHandler struct {
+	subscriptions ports.SubscriptionService
+	repository    ports.WebhookRepository
+}
+
+func NewSubscriptionHandler(subscriptions ports.SubscriptionService, repository ports.WebhookRepository) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptions: subscriptions,
+		repository:    repository,
+	}
+}
+
+type subscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type deliveryView struct {
+	*domain.WebhookDelivery
+	Payload json.RawMessage                  `json:"payload,omitempty"`
+	History []*domain.WebhookDeliveryAttempt `json:"history,omitempty"`
+}
+
+// CreateSubscription returns the signing secret; it is not shown again.
+func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var body subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, bodyErrorStatus(err), "invalid request body")
+		return
+	}
+
+	subscription, err := h.subscriptions.CreateSubscription(r.Context(), body.URL, body.Events, body.Secret)
+	if errors.Is(err, domain.ErrInvalidSubscription) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, subscription)
+}
+
+func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.repository.ListSubscriptions(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	views := make([]domain.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		view := *subscription
+		view.Secret = ""
+		views = append(views, view)
+	}
+
+	respondWithJSON(w, http.StatusOK, views)
+}
+
+func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	err := h.repository.DeactivateSubscription(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrSubscriptionNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries serves both a subscription's deliveries and the global
+// list; ?status=dead gives the dead-letter list.
+func (h *SubscriptionHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	filter := domain.DeliveryFilter{
+		SubscriptionID: r.PathValue("id"),
+		Status:         r.URL.Query().Get("status"),
+	}
+	switch filter.Status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid status value %q", filter.Status))
+		return
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit value %q", value))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := h.repository.FindDeliveries(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	views := make([]deliveryView, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		views = append(views, deliveryView{WebhookDelivery: delivery})
+	}
+
+	respondWithJSON(w, http.StatusOK, views)
+}
+
+func (h *SubscriptionHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.repository.FindDelivery(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrDeliveryNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	attempts, err := h.repository.FindDeliveryAttempts(r.Context(), delivery.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, deliveryView{
+		WebhookDelivery: delivery,
+		Payload:         rawJSON(delivery.Payload),
+		History:         attempts,
+	})
+}
+
+func (h *SubscriptionHandler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	err := h.repository.ReplayDelivery(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, domain.ErrDeliveryNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, domain.ErrDeliveryNotDead):
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSubscriptionMux(repo *testutil.MockWebhookRepository) (*http.ServeMux, *service.WebhookDispatcher) {
+	dispatcher := service.NewWebhookDispatcher(repo, testutil.NewMockWebhookSender(http.StatusInternalServerError, errors.New("subscriber responded with status 500")), time.Second)
+	dispatcher.SetRetryPolicy(domain.RetryPolicy{MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second})
+	handler := NewSubscriptionHandler(dispatcher, repo)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/webhook-subscriptions", handler.CreateSubscription)
+	mux.HandleFunc("GET /api/v1/webhook-subscriptions", handler.ListSubscriptions)
+	mux.HandleFunc("DELETE /api/v1/webhook-subscriptions/{id}", handler.DeleteSubscription)
+	mux.HandleFunc("GET /api/v1/webhook-deliveries", handler.ListDeliveries)
+	mux.HandleFunc("GET /api/v1/webhook-deliveries/{id}", handler.GetDelivery)
+	mux.HandleFunc("POST /api/v1/webhook-deliveries/{id}/replay", handler.ReplayDelivery)
+	return mux, dispatcher
+}
+
+func TestSubscriptionHandler_CreateSubscription(t *testing.T) {
+	mux, _ := newSubscriptionMux(testutil.NewMockWebhookRepository())
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"valid", `{"url":"https://orders.test/hooks","events":["shipment.created"]}`, http.StatusCreated},
+		{"unknown event", `{"url":"https://orders.test/hooks","events":["shipment.lost"]}`, http.StatusBadRequest},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook-subscriptions", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook-subscriptions", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var subscriptions []*domain.WebhookSubscription
+	if err := json.Unmarshal(w.Body.Bytes(), &subscriptions); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(subscriptions) != 1 || subscriptions[0].Secret != "" {
+		t.Errorf("expected one subscription without its secret, got %+v", subscriptions)
+	}
+}
+
+func TestSubscriptionHandler_ReplayDelivery(t *testing.T) {
+	repo := testutil.NewMockWebhookRepository()
+	mux, dispatcher := newSubscriptionMux(repo)
+
+	dispatcher.CreateSubscription(context.Background(), "https://orders.test/hooks", []string{domain.EventShipmentCreated}, "secret")
+	dispatcher.Publish(context.Background(), &domain.ShipmentEvent{ID: "event-1", Type: domain.EventShipmentCreated, OccurredAt: time.Now(), Data: json.RawMessage(`{}`)})
+	deliveries, _ := repo.FindDeliveries(context.Background(), domain.DeliveryFilter{})
+	id := deliveries[0].ID
+
+	replay := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/"+id+"/replay", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := replay(); code != http.StatusConflict {
+		t.Errorf("expected 409 for a pending delivery, got %d", code)
+	}
+
+	dispatcher.DispatchDue(context.Background())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook-deliveries/"+id, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var view struct {
+		Status  string                           `json:"status"`
+		History []*domain.WebhookDeliveryAttempt `json:"history"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if view.Status != domain.DeliveryDead || len(view.History) != 1 {
+		t.Fatalf("expected dead delivery with one attempt, got %s/%d", view.Status, len(view.History))
+	}
+
+	if code := replay(); code != http.StatusAccepted {
+		t.Errorf("expected 202 for a dead delivery, got %d", code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/webhook-deliveries/missing/replay", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown delivery, got %d", w.Code)
+	}
+}
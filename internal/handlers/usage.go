@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"time"
+)
+
+type UsageHandler struct {
+	usage ports.UsageService
+}
+
+func NewUsageHandler(usage ports.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usage: usage,
+	}
+}
+
+// GetUsage reports daily shipment quota use, for ?date= (YYYY-MM-DD, UTC,
+// default today) and optionally one ?tenant=. Admins of other tenants than
+// the default one only see their own usage.
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	day := time.Now()
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid date value "+value)
+			return
+		}
+		day = parsed
+	}
+
+	tenantID := r.URL.Query().Get("tenant")
+	if caller := domain.TenantIDFromContext(r.Context()); caller != domain.DefaultTenantID {
+		if tenantID != "" && tenantID != caller {
+			respondWithError(w, http.StatusForbidden, "usage of other tenants is only visible to the default tenant")
+			return
+		}
+		tenantID = caller
+	}
+
+	usage, err := h.usage.Usage(r.Context(), tenantID, day)
+	if errors.Is(err, domain.ErrTenantNotFound) {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, usage)
+}
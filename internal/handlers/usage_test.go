@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestUsageHandler_GetUsage(t *testing.T) {
+	acme := &domain.Tenant{ID: "acme", RateLimit: domain.RateLimit{DailyShipments: 10}}
+	usage := service.NewUsageService(testutil.NewMockUsageRepository(), service.NewTenantRegistry(map[string]*domain.Tenant{"acme": acme}))
+	usage.ReserveShipments(context.Background(), acme, 1)
+	handler := NewUsageHandler(usage)
+
+	tests := []struct {
+		name    string
+		target  string
+		tenant  *domain.Tenant
+		want    int
+		tenants []string
+	}{
+		{"operator sees every tenant", "/api/v1/admin/usage", nil, http.StatusOK, []string{"acme", "default"}},
+		{"operator filters by tenant", "/api/v1/admin/usage?tenant=acme", nil, http.StatusOK, []string{"acme"}},
+		{"tenant sees itself", "/api/v1/admin/usage", acme, http.StatusOK, []string{"acme"}},
+		{"tenant cannot see others", "/api/v1/admin/usage?tenant=default", acme, http.StatusForbidden, nil},
+		{"unknown tenant", "/api/v1/admin/usage?tenant=unknown", nil, http.StatusNotFound, nil},
+		{"invalid date", "/api/v1/admin/usage?date=yesterday", nil, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.tenant != nil {
+			req = req.WithContext(domain.WithTenant(req.Context(), tt.tenant))
+		}
+		w := httptest.NewRecorder()
+		handler.GetUsage(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		var report []*domain.TenantUsage
+		json.Unmarshal(w.Body.Bytes(), &report)
+		if len(report) != len(tt.tenants) {
+			t.Errorf("%s: expected tenants %v, got %s", tt.name, tt.tenants, w.Body.String())
+			continue
+		}
+		for i, tenantID := range tt.tenants {
+			if report[i].TenantID != tenantID {
+				t.Errorf("%s: expected tenants %v, got %s", tt.name, tt.tenants, w.Body.String())
+			}
+		}
+		if report[0].TenantID == "acme" && report[0].Shipments != 1 {
+			t.Errorf("%s: expected one acme shipment counted today, got %d", tt.name, report[0].Shipments)
+		}
+	}
+}
+
+func TestShipmentHandlers_DailyQuota(t *testing.T) {
+	acme := &domain.Tenant{ID: "acme", RateLimit: domain.RateLimit{DailyShipments: 1}}
+	repo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(repo)
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.local"))
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("B", "http://b.local"))
+	shippingService.SetUsageService(service.NewUsageService(testutil.NewMockUsageRepository(), service.NewTenantRegistry(map[string]*domain.Tenant{"acme": acme})))
+	v2 := NewV2Handler(shippingService, repo, service.NewTrackingService(repo), testutil.NewMockJobRepository())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/createShipping", NewShippingHandler(shippingService).CreateShipment)
+	mux.HandleFunc("POST /api/v1/shipments/batch", NewBatchHandler(shippingService, nil).CreateBatch)
+	mux.HandleFunc("POST /api/v2/shipments", v2.CreateShipment)
+	mux.HandleFunc("POST /api/v2/broadcasts", v2.CreateBroadcast)
+
+	sample, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	batch := string(sample) + "\n" + string(sample) + "\n"
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		accept string
+		status int
+	}{
+		{"broadcast needs two", "/api/v1/createShipping", string(sample), "", http.StatusTooManyRequests},
+		{"streamed broadcast needs two", "/api/v1/createShipping", string(sample), "text/event-stream", http.StatusTooManyRequests},
+		{"batch needs two", "/api/v1/shipments/batch", batch, "application/x-ndjson", http.StatusTooManyRequests},
+		{"v2 broadcast needs two", "/api/v2/broadcasts", string(sample), "", http.StatusTooManyRequests},
+		{"single shipment fits", "/api/v1/createShipping?provider=A", string(sample), "", http.StatusOK},
+		{"quota used up", "/api/v2/shipments", v2ShipmentBody("A").String(), "", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+		req = req.WithContext(domain.WithTenant(req.Context(), acme))
+		if strings.Contains(tt.target, "batch") {
+			req.Header.Set("Content-Type", "application/x-ndjson")
+		}
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, w.Code, w.Body.String())
+			continue
+		}
+		if tt.status == http.StatusTooManyRequests && (w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Limit") != "1") {
+			t.Errorf("%s: expected quota headers, got %v", tt.name, w.Header())
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"sort"
+	"strings"
+)
+
+// V2Handler serves the resource-oriented /api/v2 API. Every success body is
+// an envelope with the resource under "data"; every error is problem+json.
+type V2Handler struct {
+	shipping   ports.ShippingService
+	repository ports.ShipmentRepository
+	tracking   ports.TrackingService
+	jobRepo    ports.JobRepository
+	jobs       ports.JobService
+}
+
+func NewV2Handler(shipping ports.ShippingService, repository ports.ShipmentRepository, tracking ports.TrackingService, jobRepo ports.JobRepository) *V2Handler {
+	return &V2Handler{
+		shipping:   shipping,
+		repository: repository,
+		tracking:   tracking,
+		jobRepo:    jobRepo,
+	}
+}
+
+// SetJobService lets clients send "Prefer: respond-async" to queue a
+// shipment instead of waiting for the carrier.
+func (h *V2Handler) SetJobService(jobs ports.JobService) {
+	h.jobs = jobs
+}
+
+type envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+type listMeta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type createShipmentRequest struct {
+	Provider string `json:"provider"`
+	domain.GenericShippingRequest
+}
+
+func (h *V2Handler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	var body createShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithProblem(w, r, bodyProblem(err))
+		return
+	}
+	if body.Provider == "" {
+		respondWithProblem(w, r, badRequestProblem("provider is required; use /api/v2/broadcasts to send to every provider"))
+		return
+	}
+
+	if h.jobs != nil && strings.Contains(r.Header.Get("Prefer"), "respond-async") {
+		h.submitJob(w, r, &body.GenericShippingRequest, body.Provider)
+		return
+	}
+
+	response, err := h.shipping.ProcessShipment(r.Context(), &body.GenericShippingRequest, body.Provider)
+	if errors.Is(err, domain.ErrShipmentNotSaved) && response != nil {
+		respondWithProblem(w, r, unsavedProblem(response, err))
+		return
+	}
+	if err != nil {
+		setQuotaHeaders(w, err)
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+	if !response.Success {
+		respondWithProblem(w, r, problemForRejection(response))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v2/shipments/"+response.ShipmentID)
+	respondWithData(w, http.StatusCreated, response, nil)
+}
+
+func (h *V2Handler) submitJob(w http.ResponseWriter, r *http.Request, request *domain.GenericShippingRequest, provider string) {
+	job, err := h.jobs.SubmitShipment(r.Context(), request, provider)
+	if err != nil {
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v2/jobs/"+job.ID)
+	w.Header().Set("Preference-Applied", "respond-async")
+	respondWithData(w, http.StatusAccepted, job, nil)
+}
+
+// CreateBroadcast sends the request to every eligible provider. Per-provider
+// failures are reported in the data, so the response is 200 either way
+// unless the tenant's quota cannot cover every eligible provider.
+func (h *V2Handler) CreateBroadcast(w http.ResponseWriter, r *http.Request) {
+	var request domain.GenericShippingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondWithProblem(w, r, bodyProblem(err))
+		return
+	}
+
+	responses, err := h.shipping.BroadcastShipment(r.Context(), &request)
+	if err != nil {
+		setQuotaHeaders(w, err)
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].Provider < responses[j].Provider
+	})
+
+	summary := broadcastSummary{Total: len(responses)}
+	for _, response := range responses {
+		summary.RequestID = response.RequestID
+		if response.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	respondWithData(w, http.StatusOK, responses, summary)
+}
+
+func (h *V2Handler) GetShipment(w http.ResponseWriter, r *http.Request) {
+	view, err := loadShipmentView(r.Context(), h.repository, r.PathValue("id"))
+	if err != nil {
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+
+	respondWithData(w, http.StatusOK, view, nil)
+}
+
+func (h *V2Handler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseShipmentFilter(r.URL.Query())
+	if err != nil {
+		respondWithProblem(w, r, badRequestProblem(err.Error()))
+		return
+	}
+
+	page, err := h.repository.Search(r.Context(), filter)
+	if err != nil {
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+
+	views := make([]shipmentView, 0, len(page.Records))
+	for _, record := range page.Records {
+		views = append(views, newShipmentView(record, false))
+	}
+
+	respondWithData(w, http.StatusOK, views, listMeta{NextCursor: page.NextCursor})
+}
+
+func (h *V2Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var body statusUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithProblem(w, r, bodyProblem(err))
+		return
+	}
+
+	status, err := domain.ParseShipmentStatus(body.Status)
+	if err != nil {
+		respondWithProblem(w, r, badRequestProblem(err.Error()))
+		return
+	}
+
+	change := &domain.StatusChange{
+		ShipmentID:  r.PathValue("id"),
+		ToStatus:    status,
+		Source:      domain.StatusSourceAPI,
+		Description: body.Description,
+		Location:    body.Location,
+		OccurredAt:  body.OccurredAt,
+	}
+	if err := h.tracking.UpdateStatus(r.Context(), change); err != nil {
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+
+	respondWithData(w, http.StatusOK, newStatusView(change), nil)
+}
+
+func (h *V2Handler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	providers := h.shipping.Providers(r.Context())
+	if providers == nil {
+		providers = []domain.ProviderInfo{}
+	}
+
+	respondWithData(w, http.StatusOK, providers, nil)
+}
+
+func (h *V2Handler) GetProvider(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	for _, provider := range h.shipping.Providers(r.Context()) {
+		if provider.Name == name {
+			respondWithData(w, http.StatusOK, provider, nil)
+			return
+		}
+	}
+
+	respondWithProblem(w, r, problemForError(fmt.Errorf("%w: %s", domain.ErrProviderNotFound, name)))
+}
+
+func (h *V2Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobRepo.FindJob(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithProblem(w, r, problemForError(err))
+		return
+	}
+
+	respondWithData(w, http.StatusOK, job, nil)
+}
+
+func respondWithData(w http.ResponseWriter, code int, data, meta interface{}) {
+	respondWithJSON(w, code, envelope{Data: data, Meta: meta})
+}
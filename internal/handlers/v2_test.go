@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func newV2Mux(providers ...*testutil.MockShippingProvider) *http.ServeMux {
+	repo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(repo)
+	for _, provider := range providers {
+		shippingService.RegisterProvider(provider)
+	}
+	handler := NewV2Handler(shippingService, repo, service.NewTrackingService(repo), testutil.NewMockJobRepository())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/shipments", handler.CreateShipment)
+	mux.HandleFunc("GET /api/v2/shipments", handler.ListShipments)
+	mux.HandleFunc("GET /api/v2/shipments/{id}", handler.GetShipment)
+	mux.HandleFunc("POST /api/v2/shipments/{id}/status", handler.UpdateStatus)
+	mux.HandleFunc("POST /api/v2/broadcasts", handler.CreateBroadcast)
+	mux.HandleFunc("GET /api/v2/providers", handler.ListProviders)
+	mux.HandleFunc("GET /api/v2/providers/{name}", handler.GetProvider)
+	mux.HandleFunc("GET /api/v2/jobs/{id}", handler.GetJob)
+	return mux
+}
+
+func v2ShipmentBody(provider string) *bytes.Buffer {
+	body := map[string]interface{}{}
+	encoded, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	json.Unmarshal(encoded, &body)
+	if provider != "" {
+		body["provider"] = provider
+	}
+	encoded, _ = json.Marshal(body)
+	return bytes.NewBuffer(encoded)
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if contentType := w.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Fatalf("expected %s, got %q", problemContentType, contentType)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem: %v", err)
+	}
+	if problem.Status != w.Code || problem.Type == "" || problem.Title == "" {
+		t.Fatalf("incomplete problem for status %d: %+v", w.Code, problem)
+	}
+	return problem
+}
+
+func TestV2Handler_CreateAndGetShipment(t *testing.T) {
+	mux := newV2Mux(testutil.NewMockShippingProvider("A", "http://a.local"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/shipments", v2ShipmentBody("A"))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status code 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data domain.ShipmentResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	location := w.Header().Get("Location")
+	if created.Data.ShipmentID == "" || location != "/api/v2/shipments/"+created.Data.ShipmentID {
+		t.Fatalf("expected Location for shipment %q, got %q", created.Data.ShipmentID, location)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, location, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var fetched struct {
+		Data shipmentView `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &fetched); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if w.Code != http.StatusOK || fetched.Data.ID != created.Data.ShipmentID || len(fetched.Data.Attempts) != 1 {
+		t.Fatalf("unexpected shipment %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestV2Handler_CreateShipment_Problems(t *testing.T) {
+	failing := func(name string, fn func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error)) *testutil.MockShippingProvider {
+		provider := testutil.NewMockShippingProvider(name, "http://"+name+".local")
+		provider.SetCreateShipmentFunc(fn)
+		return provider
+	}
+	rejected := func(status int) func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+			return &domain.ShipmentResponse{Success: false, Message: "rejected", Exchange: &domain.ProviderExchange{StatusCode: status}}, nil
+		}
+	}
+	providerError := func(category string) func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+		return func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+			return nil, &domain.ProviderError{Provider: "X", Category: category, Err: errors.New("failed")}
+		}
+	}
+	ineligible := testutil.NewMockShippingProvider("I", "http://i.local")
+	ineligible.SetCapabilities(domain.ProviderCapabilities{DestinationCountries: []string{"ZZ"}})
+
+	mux := newV2Mux(
+		failing("T", providerError(domain.ErrorCategoryTimeout)),
+		failing("N", providerError(domain.ErrorCategoryNetwork)),
+		failing("E", rejected(http.StatusServiceUnavailable)),
+		failing("R", rejected(http.StatusBadRequest)),
+		ineligible,
+	)
+
+	tests := []struct {
+		provider    string
+		status      int
+		problemType string
+	}{
+		{"", http.StatusBadRequest, "/problems/invalid-request"},
+		{"missing", http.StatusNotFound, "/problems/provider-not-found"},
+		{"I", http.StatusUnprocessableEntity, "/problems/provider-ineligible"},
+		{"T", http.StatusGatewayTimeout, "/problems/carrier-timeout"},
+		{"N", http.StatusBadGateway, "/problems/carrier-unavailable"},
+		{"E", http.StatusBadGateway, "/problems/carrier-error"},
+		{"R", http.StatusUnprocessableEntity, "/problems/carrier-rejected"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/shipments", v2ShipmentBody(tt.provider))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("provider %q: expected status code %d, got %d", tt.provider, tt.status, w.Code)
+			continue
+		}
+		problem := decodeProblem(t, w)
+		if problem.Type != tt.problemType || problem.Instance != "/api/v2/shipments" {
+			t.Errorf("provider %q: unexpected problem %+v", tt.provider, problem)
+		}
+	}
+}
+
+func TestV2Handler_Broadcast(t *testing.T) {
+	mux := newV2Mux(
+		testutil.NewMockShippingProvider("B", "http://b.local"),
+		testutil.NewMockShippingProvider("A", "http://a.local"),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/broadcasts", v2ShipmentBody(""))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var body struct {
+		Data []domain.ShipmentResponse `json:"data"`
+		Meta broadcastSummary          `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if w.Code != http.StatusOK || len(body.Data) != 2 || body.Data[0].Provider != "A" {
+		t.Fatalf("unexpected broadcast %d: %s", w.Code, w.Body.String())
+	}
+	if body.Meta.Total != 2 || body.Meta.Succeeded != 2 || body.Meta.RequestID == "" {
+		t.Errorf("unexpected summary %+v", body.Meta)
+	}
+}
+
+func TestV2Handler_Providers(t *testing.T) {
+	mux := newV2Mux(testutil.NewMockShippingProvider("A", "http://a.local"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/providers/A", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var body struct {
+		Data domain.ProviderInfo `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if w.Code != http.StatusOK || body.Data.Name != "A" || !body.Data.Capabilities.SupportsCOD {
+		t.Fatalf("unexpected provider %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/providers/missing", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status code 404, got %d", w.Code)
+	}
+	decodeProblem(t, w)
+}
+
+func TestV2Handler_ShipmentNotFound(t *testing.T) {
+	mux := newV2Mux()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v2/shipments/missing", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v2/shipments/missing/status", bytes.NewBufferString(`{"status":"delivered"}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v2/jobs/missing", nil),
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status code 404, got %d", req.Method, req.URL.Path, w.Code)
+			continue
+		}
+		decodeProblem(t, w)
+	}
+}
+
+func TestV2Handler_BodyTooLarge(t *testing.T) {
+	mux := newV2Mux(testutil.NewMockShippingProvider("A", "http://a.test"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/shipments", v2ShipmentBody("A"))
+	w := httptest.NewRecorder()
+	req.Body = http.MaxBytesReader(w, req.Body, 16)
+	mux.ServeHTTP(w, req)
+
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if w.Code != http.StatusRequestEntityTooLarge || problem.Type != "/problems/request-too-large" {
+		t.Errorf("expected a request-too-large problem, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+type failingSaveRepository struct {
+	*testutil.MockRepository
+}
+
+func (r *failingSaveRepository) Save(ctx context.Context, record *domain.ShipmentRecord) error {
+	return errors.New(`pq: relation "shipment_records" does not exist`)
+}
+
+func TestV2Handler_CreateShipment_NotSaved(t *testing.T) {
+	repo := &failingSaveRepository{testutil.NewMockRepository()}
+	shippingService := service.NewShippingService(repo)
+	shippingService.RegisterProvider(testutil.NewMockShippingProvider("A", "http://a.test"))
+	handler := NewV2Handler(shippingService, repo, service.NewTrackingService(repo), testutil.NewMockJobRepository())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/shipments", v2ShipmentBody("A"))
+	w := httptest.NewRecorder()
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status code 500, got %d", w.Code)
+	}
+	problem := decodeProblem(t, w)
+	if problem.Type != "/problems/shipment-not-saved" || problem.TrackingID != "TRACK123" || problem.Provider != "A" {
+		t.Errorf("expected a shipment-not-saved problem with the tracking ID, got %+v", problem)
+	}
+	if strings.Contains(w.Body.String(), "pq:") {
+		t.Errorf("expected the database error to stay out of the response, got %s", w.Body.String())
+	}
+}
+
+func TestInternalProblem_HidesError(t *testing.T) {
+	problem := problemForError(errors.New(`pq: password authentication failed for user "shipping"`))
+
+	if problem.Status != http.StatusInternalServerError || strings.Contains(problem.Detail, "pq:") {
+		t.Errorf("expected a generic internal problem, got %+v", problem)
+	}
+}
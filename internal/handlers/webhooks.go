@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+)
+
+const maxWebhookBodyBytes = 1 << 20
+
+type WebhookHandler struct {
+	trackingService ports.TrackingService
+}
+
+func NewWebhookHandler(trackingService ports.TrackingService) *WebhookHandler {
+	return &WebhookHandler{
+		trackingService: trackingService,
+	}
+}
+
+type webhookResponse struct {
+	Results []*domain.TrackingEventResult `json:"results"`
+}
+
+func (h *WebhookHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		respondWithError(w, bodyErrorStatus(err), "invalid request body")
+		return
+	}
+
+	results, err := h.trackingService.HandleWebhook(r.Context(), r.PathValue("provider"), r.Header, body)
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotSupported):
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, domain.ErrWebhookSignature):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+		return
+	case errors.Is(err, domain.ErrInvalidWebhookPayload):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if results == nil {
+		results = []*domain.TrackingEventResult{}
+	}
+	respondWithJSON(w, http.StatusOK, webhookResponse{Results: results})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newWebhookMux(repo *testutil.MockRepository, events ...*domain.TrackingEvent) *http.ServeMux {
+	trackingService := service.NewTrackingService(repo)
+	trackingService.RegisterWebhookParser(testutil.NewMockWebhookParser("A", "secret", events...))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/webhooks/{provider}", NewWebhookHandler(trackingService).ReceiveWebhook)
+	return mux
+}
+
+func TestWebhookHandler_ReceiveWebhook(t *testing.T) {
+	mockRepo := testutil.NewMockRepository()
+	records := seedShipments(t, mockRepo)
+	event := &domain.TrackingEvent{Provider: "A", EventID: "E1", TrackingID: "A-TRACK-1", Status: domain.StatusPickedUp, OccurredAt: time.Now()}
+	mux := newWebhookMux(mockRepo, event)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/A", strings.NewReader(`{}`))
+	req.Header.Set("X-Webhook-Token", "secret")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response webhookResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Result != domain.TrackingResultApplied {
+		t.Fatalf("expected one applied result, got %+v", response.Results)
+	}
+
+	found, _ := mockRepo.FindByID(context.Background(), records[0].ID)
+	if found.Status != domain.StatusPickedUp {
+		t.Errorf("expected status picked_up, got %s", found.Status)
+	}
+}
+
+func TestWebhookHandler_ReceiveWebhook_Errors(t *testing.T) {
+	mux := newWebhookMux(testutil.NewMockRepository())
+
+	tests := []struct {
+		name     string
+		provider string
+		token    string
+		body     string
+		code     int
+	}{
+		{"unknown provider", "Z", "secret", `{}`, http.StatusNotFound},
+		{"bad signature", "A", "wrong", `{}`, http.StatusUnauthorized},
+		{"bad payload", "A", "secret", `not json`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+tt.provider, strings.NewReader(tt.body))
+			req.Header.Set("X-Webhook-Token", tt.token)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("expected status code %d, got %d", tt.code, w.Code)
+			}
+		})
+	}
+}
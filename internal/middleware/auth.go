@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"strings"
+)
+
+type Auth struct {
+	keys    ports.APIKeyService
+	tenants ports.TenantDirectory
+}
+
+func NewAuth(keys ports.APIKeyService, tenants ports.TenantDirectory) *Auth {
+	return &Auth{
+		keys:    keys,
+		tenants: tenants,
+	}
+}
+
+// Require runs next only for requests with an active API key that grants
+// scope, sent as "Authorization: Bearer <key>" or "X-API-Key: <key>". The
+// key and its tenant are available to next through domain.APIKeyFromContext
+// and domain.TenantFromContext.
+func (a *Auth) Require(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := credential(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="shipping-api"`)
+			writeError(w, r, http.StatusUnauthorized, "api key required")
+			return
+		}
+
+		key, err := a.keys.Authenticate(r.Context(), secret)
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="shipping-api", error="invalid_token"`)
+			writeError(w, r, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("api key authentication failed: %v", err)
+			writeError(w, r, http.StatusInternalServerError, "authentication unavailable")
+			return
+		}
+
+		if !key.HasScope(scope) {
+			writeError(w, r, http.StatusForbidden, "api key lacks scope "+scope)
+			return
+		}
+
+		tenant, err := a.tenants.Tenant(key.TenantID)
+		if err != nil {
+			writeError(w, r, http.StatusForbidden, err.Error())
+			return
+		}
+
+		ctx := domain.WithTenant(domain.WithAPIKey(r.Context(), key), tenant)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func credential(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
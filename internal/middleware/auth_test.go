@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/service"
+	"shipping-api/internal/testutil"
+	"testing"
+)
+
+func TestAuth_Require(t *testing.T) {
+	keys := service.NewAPIKeyService(testutil.NewMockAPIKeyRepository())
+	reader, readerSecret, _ := keys.IssueKey(context.Background(), "", "reader", []string{domain.ScopeShipmentsRead}, nil)
+	_, adminSecret, _ := keys.IssueKey(context.Background(), "", "admin", []string{domain.ScopeAdmin}, nil)
+
+	var seen *domain.APIKey
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = domain.APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	auth := NewAuth(keys, service.NewTenantRegistry(nil))
+
+	tests := []struct {
+		name   string
+		scope  string
+		header string
+		value  string
+		want   int
+	}{
+		{"missing key", domain.ScopeShipmentsRead, "", "", http.StatusUnauthorized},
+		{"unknown key", domain.ScopeShipmentsRead, "X-API-Key", "sk_unknown", http.StatusUnauthorized},
+		{"bearer token", domain.ScopeShipmentsRead, "Authorization", "Bearer " + readerSecret, http.StatusOK},
+		{"api key header", domain.ScopeShipmentsRead, "X-API-Key", readerSecret, http.StatusOK},
+		{"missing scope", domain.ScopeShipmentsCreate, "X-API-Key", readerSecret, http.StatusForbidden},
+		{"admin grants all", domain.ScopeShipmentsCreate, "X-API-Key", adminSecret, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+		auth.Require(tt.scope, next).ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+		if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: expected WWW-Authenticate header", tt.name)
+		}
+	}
+
+	seen = nil
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+	req.Header.Set("X-API-Key", readerSecret)
+	auth.Require(domain.ScopeShipmentsRead, next).ServeHTTP(httptest.NewRecorder(), req)
+	if seen == nil || seen.ID != reader.ID {
+		t.Errorf("expected authenticated key in context, got %+v", seen)
+	}
+}
+
+func TestAuth_ResolvesTenant(t *testing.T) {
+	keys := service.NewAPIKeyService(testutil.NewMockAPIKeyRepository())
+	_, acmeSecret, _ := keys.IssueKey(context.Background(), "acme", "acme", []string{domain.ScopeShipmentsRead}, nil)
+	_, goneSecret, _ := keys.IssueKey(context.Background(), "gone", "gone", []string{domain.ScopeShipmentsRead}, nil)
+	auth := NewAuth(keys, service.NewTenantRegistry(map[string]*domain.Tenant{"acme": {ID: "acme"}}))
+
+	var seen *domain.Tenant
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = domain.TenantFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+	req.Header.Set("X-API-Key", acmeSecret)
+	auth.Require(domain.ScopeShipmentsRead, next).ServeHTTP(httptest.NewRecorder(), req)
+	if seen == nil || seen.ID != "acme" {
+		t.Errorf("expected tenant acme in context, got %+v", seen)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+	req.Header.Set("X-API-Key", goneSecret)
+	w := httptest.NewRecorder()
+	auth.Require(domain.ScopeShipmentsRead, next).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for unknown tenant, got %d", w.Code)
+	}
+}
+
+func TestAuth_ErrorFormatFollowsAPIVersion(t *testing.T) {
+	auth := NewAuth(service.NewAPIKeyService(testutil.NewMockAPIKeyRepository()), service.NewTenantRegistry(nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/shipments", nil)
+	w := httptest.NewRecorder()
+	auth.Require(domain.ScopeShipmentsRead, next).ServeHTTP(w, req)
+	var problem struct {
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if w.Header().Get("Content-Type") != "application/problem+json" || problem.Status != http.StatusUnauthorized {
+		t.Errorf("expected problem+json 401, got %q %s", w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+	w = httptest.NewRecorder()
+	auth.Require(domain.ScopeShipmentsRead, next).ServeHTTP(w, req)
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body["error"] == "" {
+		t.Errorf("expected v1 error body, got %s", w.Body.String())
+	}
+}
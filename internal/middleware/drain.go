@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Drain tracks the requests in flight, so shutdown can wait for handlers the
+// server stopped waiting for before closing what they use. Once shutdown
+// starts, requests that lifted their deadline, like streams, get timeout to
+// finish. Track must run inside Timeout, whose deadline it moves.
+type Drain struct {
+	timeout  time.Duration
+	requests sync.WaitGroup
+	shutdown context.Context
+	stop     context.CancelFunc
+}
+
+func NewDrain(timeout time.Duration) *Drain {
+	shutdown, stop := context.WithCancel(context.Background())
+	return &Drain{
+		timeout:  timeout,
+		shutdown: shutdown,
+		stop:     stop,
+	}
+}
+
+func (d *Drain) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.requests.Add(1)
+		defer d.requests.Done()
+
+		dw := &drainWriter{ResponseWriter: w, drain: d, start: time.Now()}
+		stop := context.AfterFunc(d.shutdown, dw.bound)
+		defer dw.finish()
+		defer stop()
+
+		next.ServeHTTP(dw, r)
+	})
+}
+
+// Shutdown bounds the requests running without a deadline. It is meant
+// for http.Server.RegisterOnShutdown.
+func (d *Drain) Shutdown() {
+	d.stop()
+}
+
+// Wait waits for the requests in flight until ctx is done and reports
+// whether they finished.
+func (d *Drain) Wait(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		d.requests.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// drainWriter passes SetTimeout on to Timeout and remembers when a handler
+// lifted its deadline, so shutdown can bound it again.
+type drainWriter struct {
+	http.ResponseWriter
+	drain *Drain
+	start time.Time
+
+	mu        sync.Mutex
+	unbounded bool
+	finished  bool
+}
+
+func (w *drainWriter) SetTimeout(timeout time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unbounded = timeout <= 0
+	if w.unbounded && w.drain.shutdown.Err() != nil {
+		timeout = time.Since(w.start) + w.drain.timeout
+		w.unbounded = false
+	}
+	w.setTimeout(timeout)
+}
+
+func (w *drainWriter) bound() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.unbounded && !w.finished {
+		w.setTimeout(time.Since(w.start) + w.drain.timeout)
+		w.unbounded = false
+	}
+}
+
+func (w *drainWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.finished = true
+}
+
+func (w *drainWriter) setTimeout(timeout time.Duration) {
+	if tw, ok := w.ResponseWriter.(interface{ SetTimeout(time.Duration) }); ok {
+		tw.SetTimeout(timeout)
+	}
+}
+
+func (w *drainWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *drainWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrain_Wait(t *testing.T) {
+	drain := NewDrain(time.Minute)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := drain.Track(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		close(done)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if drain.Wait(ctx) {
+		t.Error("expected Wait to give up while a request is running")
+	}
+
+	close(release)
+	<-done
+	if !drain.Wait(context.Background()) {
+		t.Error("expected Wait to return once requests finished")
+	}
+}
+
+func TestDrain_ShutdownBoundsStreams(t *testing.T) {
+	for _, name := range []string{"streaming", "lifted after shutdown"} {
+		t.Run(name, func(t *testing.T) {
+			drain := NewDrain(10 * time.Millisecond)
+			lifted := make(chan struct{})
+			var err error
+			handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.(interface{ SetTimeout(time.Duration) }).SetTimeout(0)
+				close(lifted)
+				select {
+				case <-r.Context().Done():
+					err = r.Context().Err()
+				case <-time.After(time.Second):
+				}
+			}), Timeout(time.Minute, 0), drain.Track)
+
+			if name == "lifted after shutdown" {
+				drain.Shutdown()
+			}
+			done := make(chan struct{})
+			go func() {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+				close(done)
+			}()
+			<-lifted
+			drain.Shutdown()
+			<-done
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the stream to be cut off after shutdown, got %v", err)
+			}
+		})
+	}
+}
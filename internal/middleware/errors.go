@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// writeError answers in the error format of the API being called:
+// problem+json under /api/v2, {"error": ...} elsewhere.
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	var body []byte
+	if strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		body, _ = json.Marshal(map[string]interface{}{
+			"type":     "about:blank",
+			"title":    http.StatusText(status),
+			"status":   status,
+			"detail":   message,
+			"instance": r.URL.Path,
+		})
+		w.Header().Set("Content-Type", "application/problem+json")
+	} else {
+		body, _ = json.Marshal(map[string]string{"error": message})
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+}
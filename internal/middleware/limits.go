@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxBytes caps every request body at limit bytes. Handlers reading past it
+// get an *http.MaxBytesError and answer 413.
+func MaxBytes(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limit > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeout gives each request a deadline on its context, which ends carrier
+// calls and queries still running when it passes. Handlers that need longer,
+// like streams and batches, move it through the SetTimeout method of their
+// ResponseWriter; the server's write deadline, writeTimeout after the request
+// started, moves with it.
+func Timeout(timeout, writeTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := newTimeoutContext(r.Context())
+			defer ctx.cancel(context.Canceled)
+
+			tw := &timeoutWriter{
+				ResponseWriter: w,
+				ctx:            ctx,
+				start:          time.Now(),
+				writeMargin:    writeTimeout - timeout,
+			}
+			if timeout > 0 {
+				ctx.setDeadline(tw.start.Add(timeout))
+			}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+		})
+	}
+}
+
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         *timeoutContext
+	start       time.Time
+	writeMargin time.Duration
+}
+
+// SetTimeout replaces the request's deadline with timeout after it started.
+// Zero removes it, and the write deadline with it, for streams that report
+// progress as they go.
+func (w *timeoutWriter) SetTimeout(timeout time.Duration) {
+	controller := http.NewResponseController(w.ResponseWriter)
+	if timeout <= 0 {
+		w.ctx.setDeadline(time.Time{})
+		controller.SetWriteDeadline(time.Time{})
+		return
+	}
+
+	w.ctx.setDeadline(w.start.Add(timeout))
+	if w.writeMargin > 0 {
+		controller.SetWriteDeadline(w.start.Add(timeout + w.writeMargin))
+	}
+}
+
+func (w *timeoutWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// timeoutContext is a context whose deadline can be moved after it was
+// created. It ends with context.DeadlineExceeded like one from
+// context.WithDeadline, so carrier calls still report a timeout.
+type timeoutContext struct {
+	context.Context
+	done chan struct{}
+	stop func() bool
+
+	mu       sync.Mutex
+	err      error
+	deadline time.Time
+	timer    *time.Timer
+}
+
+func newTimeoutContext(parent context.Context) *timeoutContext {
+	ctx := &timeoutContext{Context: parent, done: make(chan struct{})}
+	ctx.stop = context.AfterFunc(parent, func() { ctx.cancel(parent.Err()) })
+	return ctx
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deadline.IsZero() {
+		return c.Context.Deadline()
+	}
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *timeoutContext) setDeadline(deadline time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.deadline = deadline
+	if !deadline.IsZero() {
+		c.timer = time.AfterFunc(time.Until(deadline), func() { c.cancel(context.DeadlineExceeded) })
+	}
+}
+
+func (c *timeoutContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.stop()
+	close(c.done)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxBytes(t *testing.T) {
+	var readErr error
+	handler := MaxBytes(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("1234")))
+	if readErr != nil {
+		t.Errorf("expected a body at the limit to be read, got %v", readErr)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("12345")))
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(readErr, &maxBytesErr) {
+		t.Errorf("expected MaxBytesError past the limit, got %v", readErr)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	handler := Timeout(time.Minute, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/createShipping", nil))
+	if !hasDeadline || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline within a minute, got %v (%v)", deadline, hasDeadline)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !hasDeadline {
+		t.Error("expected the Accept header not to lift the deadline")
+	}
+}
+
+func TestTimeout_Expires(t *testing.T) {
+	var err error
+	handler := Timeout(10*time.Millisecond, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		err = r.Context().Err()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestTimeout_SetTimeout(t *testing.T) {
+	var err error
+	var hasDeadline bool
+	handler := Timeout(10*time.Millisecond, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(interface{ SetTimeout(time.Duration) }).SetTimeout(time.Minute)
+		time.Sleep(30 * time.Millisecond)
+		err = r.Context().Err()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Errorf("expected the extended deadline to hold, got %v", err)
+	}
+
+	handler = Timeout(time.Minute, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(interface{ SetTimeout(time.Duration) }).SetTimeout(0)
+		_, hasDeadline = r.Context().Deadline()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if hasDeadline {
+		t.Error("expected SetTimeout(0) to remove the deadline")
+	}
+}
+
+func TestTimeout_StreamingOutlivesWriteTimeout(t *testing.T) {
+	handler := Timeout(time.Minute, 50*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(interface{ SetTimeout(time.Duration) }).SetTimeout(0)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	server := httptest.NewUnstartedServer(Chain(handler, RequestID))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "done" {
+		t.Errorf("expected the stream to finish past the write timeout, got %q (%v)", body, err)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}), RequestID, AccessLog(logger))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shipments?consigneeEmail=a@b.test", nil)
+	req.Header.Set(domain.RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q", out.String())
+	}
+	if entry["request_id"] != "req-1" || entry["path"] != "/api/v1/shipments" || entry["status"] != float64(201) || entry["bytes"] != float64(7) {
+		t.Errorf("unexpected access log entry %v", entry)
+	}
+	if strings.Contains(out.String(), "a@b.test") {
+		t.Error("expected the query string left out of the log")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("mapper exploded")
+	}), RequestID, Recover(logger))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusInternalServerError || body["error"] == "" {
+		t.Errorf("expected a JSON 500, got %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(out.String(), "mapper exploded") || !strings.Contains(out.String(), w.Header().Get(domain.RequestIDHeader)) {
+		t.Errorf("expected the panic logged with the request ID, got %s", out.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/shipments", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("expected problem+json under /api/v2, got %q", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestStatusWriter_KeepsFlusher(t *testing.T) {
+	var flushed bool
+	handler := AccessLog(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if ok {
+			flusher.Flush()
+		}
+		flushed = ok
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !flushed || !w.Flushed {
+		t.Error("expected streaming handlers to flush through the access log")
+	}
+}
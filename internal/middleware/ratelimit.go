@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"shipping-api/internal/core/domain"
+	"shipping-api/internal/core/ports"
+	"strconv"
+	"time"
+)
+
+// RateLimiter enforces each tenant's request rate with counters shared
+// across replicas; the daily shipment quota is counted by the shipping
+// service. Requests without a tenant, and tenants without limits, pass.
+// Limits fail open: when the counters cannot be reached the request is
+// served and the error logged.
+type RateLimiter struct {
+	usage ports.UsageService
+	now   func() time.Time
+}
+
+func NewRateLimiter(usage ports.UsageService) *RateLimiter {
+	return &RateLimiter{
+		usage: usage,
+		now:   time.Now,
+	}
+}
+
+// Limit counts the request against the tenant's rate limit.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := domain.TenantFromContext(r.Context())
+		if tenant == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision, err := l.usage.AllowRequest(r.Context(), tenant)
+		if l.reject(w, r, decision, err, "rate limit exceeded") {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject sets the rate limit headers for decision and answers 429 with
+// Retry-After when it was not allowed.
+func (l *RateLimiter) reject(w http.ResponseWriter, r *http.Request, decision *domain.LimitDecision, err error, message string) bool {
+	if err != nil {
+		log.Printf("rate limit check failed, allowing request: %v", err)
+		return false
+	}
+	if decision == nil {
+		return false
+	}
+
+	reset := max(int(math.Ceil(decision.Reset.Sub(l.now()).Seconds())), 1)
+	window := max(int(math.Ceil(decision.Window.Seconds())), 1)
+	header := w.Header()
+	header.Add("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, window))
+	// With several policies, report the one closest to running out.
+	if current, err := strconv.Atoi(header.Get("RateLimit-Remaining")); err != nil || decision.Remaining <= current {
+		header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(reset))
+	}
+
+	if decision.Allowed {
+		return false
+	}
+	header.Set("Retry-After", strconv.Itoa(reset))
+	writeError(w, r, http.StatusTooManyRequests, message)
+	return true
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+type fakeUsage struct {
+	request *domain.LimitDecision
+	err     error
+	counted int
+}
+
+func (f *fakeUsage) AllowRequest(ctx context.Context, tenant *domain.Tenant) (*domain.LimitDecision, error) {
+	f.counted++
+	return f.request, f.err
+}
+
+func (f *fakeUsage) ReserveShipments(ctx context.Context, tenant *domain.Tenant, shipments int) (*domain.LimitDecision, error) {
+	return nil, nil
+}
+
+func (f *fakeUsage) Usage(ctx context.Context, tenantID string, day time.Time) ([]*domain.TenantUsage, error) {
+	return nil, nil
+}
+
+func serveLimited(limiter *RateLimiter, handler http.Handler, target string, tenant *domain.Tenant) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, nil)
+	if tenant != nil {
+		req = req.WithContext(domain.WithTenant(req.Context(), tenant))
+	}
+	w := httptest.NewRecorder()
+	limiter.Limit(handler).ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter_Headers(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	usage := &fakeUsage{
+		request: &domain.LimitDecision{Allowed: true, Limit: 10, Remaining: 7, Window: 2 * time.Second, Reset: now.Add(time.Second)},
+	}
+	limiter := NewRateLimiter(usage)
+	limiter.now = func() time.Time { return now }
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	w := serveLimited(limiter, ok, "/api/v1/createShipping", &domain.Tenant{ID: "acme"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "7" {
+		t.Errorf("expected 7 remaining, got %q", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "1" {
+		t.Errorf("expected reset in 1s, got %q", got)
+	}
+	if got := w.Header().Values("RateLimit-Policy"); len(got) != 1 || got[0] != "10;w=2" {
+		t.Errorf("expected the request policy, got %v", got)
+	}
+}
+
+func TestRateLimiter_Rejects(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	usage := &fakeUsage{
+		request: &domain.LimitDecision{Allowed: false, Limit: 10, Window: 2 * time.Second, Reset: now.Add(1500 * time.Millisecond)},
+	}
+	limiter := NewRateLimiter(usage)
+	limiter.now = func() time.Time { return now }
+	served := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { served = true })
+
+	w := serveLimited(limiter, handler, "/api/v1/createShipping", &domain.Tenant{ID: "acme"})
+	if w.Code != http.StatusTooManyRequests || served {
+		t.Fatalf("expected 429 without calling the handler, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("expected Retry-After 2, got %q", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("expected no remaining requests, got %q", got)
+	}
+
+	w = serveLimited(limiter, handler, "/api/v2/shipments", &domain.Tenant{ID: "acme"})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("expected a problem+json 429 on the v2 API, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestRateLimiter_Passes(t *testing.T) {
+	usage := &fakeUsage{err: errors.New("database unavailable")}
+	limiter := NewRateLimiter(usage)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	if w := serveLimited(limiter, ok, "/api/v1/createShipping", &domain.Tenant{ID: "acme"}); w.Code != http.StatusOK {
+		t.Errorf("expected to fail open when counters are unavailable, got %d", w.Code)
+	}
+
+	usage.counted = 0
+	if w := serveLimited(limiter, ok, "/api/v1/createShipping", nil); w.Code != http.StatusOK || usage.counted != 0 {
+		t.Errorf("expected a request without a tenant to pass uncounted, got %d after %d checks", w.Code, usage.counted)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"shipping-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = domain.RequestIDFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"propagated", "order-42:retry.1", true},
+		{"unsafe characters replaced", "bad id\nInjected: 1", false},
+		{"overlong replaced", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments", nil)
+		if tt.incoming != "" {
+			req.Header.Set(domain.RequestIDHeader, tt.incoming)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		returned := w.Header().Get(domain.RequestIDHeader)
+		if returned == "" || returned != seen {
+			t.Errorf("%s: expected the context ID %q returned, got %q", tt.name, seen, returned)
+		}
+		if (returned == tt.incoming) != tt.keep {
+			t.Errorf("%s: expected keep %v, got %q", tt.name, tt.keep, returned)
+		}
+	}
+}
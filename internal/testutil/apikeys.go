@@ -0,0 +1,123 @@
+package testutil
+
+import (
+	"context"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MockAPIKeyRepository struct {
+	keys    map[string]*domain.APIKey
+	touches int
+	mu      sync.RWMutex
+}
+
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *key
+	m.keys[key.ID] = &stored
+	return nil
+}
+
+func (m *MockAPIKeyRepository) FindAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, exists := m.keys[id]
+	if !exists {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (m *MockAPIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []*domain.APIKey
+	for _, key := range m.keys {
+		copied := *key
+		keys = append(keys, &copied)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) RotateAPIKey(ctx context.Context, oldID string, replacement *domain.APIKey, oldExpiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, exists := m.keys[oldID]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	if !old.Active(time.Now()) {
+		return domain.ErrAPIKeyInactive
+	}
+	if old.ExpiresAt == nil || oldExpiresAt.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &oldExpiresAt
+	}
+	old.ReplacedBy = replacement.ID
+	stored := *replacement
+	m.keys[replacement.ID] = &stored
+	return nil
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id string, revokedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, exists := m.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key, exists := m.keys[id]; exists {
+		key.LastUsedAt = &usedAt
+		m.touches++
+	}
+	return nil
+}
+
+// Touches returns how many last-used updates were written.
+func (m *MockAPIKeyRepository) Touches() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.touches
+}
+
+// ExpireAPIKey moves a key's expiry into the past.
+func (m *MockAPIKeyRepository) ExpireAPIKey(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key, exists := m.keys[id]; exists {
+		past := time.Now().Add(-time.Second)
+		key.ExpiresAt = &past
+	}
+}
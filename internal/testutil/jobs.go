@@ -0,0 +1,128 @@
+package testutil
+
+import (
+	"context"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MockJobRepository struct {
+	jobs map[string]*domain.Job
+	mu   sync.RWMutex
+}
+
+func NewMockJobRepository() *MockJobRepository {
+	return &MockJobRepository{
+		jobs: make(map[string]*domain.Job),
+	}
+}
+
+func (m *MockJobRepository) CreateJob(ctx context.Context, job *domain.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job.TenantID == "" {
+		job.TenantID = domain.TenantIDFromContext(ctx)
+	}
+	stored := *job
+	m.jobs[job.ID] = &stored
+	return nil
+}
+
+func (m *MockJobRepository) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var due []*domain.Job
+	for _, job := range m.jobs {
+		if job.Status == domain.JobQueued && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*domain.Job, 0, len(due))
+	for _, job := range due {
+		job.Status = domain.JobRunning
+		job.Attempts++
+		job.RunAt = now.Add(lease)
+		job.UpdatedAt = now
+		copied := *job
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (m *MockJobRepository) FailExpiredJobs(ctx context.Context, reason string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	failed := 0
+	for _, job := range m.jobs {
+		if job.Status == domain.JobRunning && !job.RunAt.After(now) {
+			job.Status = domain.JobFailed
+			job.Error = reason
+			job.UpdatedAt = now
+			job.CompletedAt = &now
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (m *MockJobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, exists := m.jobs[job.ID]
+	if !exists {
+		return domain.ErrJobNotFound
+	}
+	stored.Status = job.Status
+	stored.Results = job.Results
+	stored.Error = job.Error
+	stored.RunAt = job.RunAt
+	stored.UpdatedAt = job.UpdatedAt
+	stored.CompletedAt = job.CompletedAt
+	return nil
+}
+
+func (m *MockJobRepository) FindJob(ctx context.Context, id string) (*domain.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, exists := m.jobs[id]
+	if !exists || !inScope(ctx, job.TenantID) {
+		return nil, domain.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (m *MockJobRepository) ListJobs(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var jobs []*domain.Job
+	for _, job := range m.jobs {
+		if (filter.Status == "" || job.Status == filter.Status) && inScope(ctx, job.TenantID) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if filter.Limit > 0 && len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+	return jobs, nil
+}
+
+// MakeJobDue lets a test run a job scheduled for a later retry.
+func (m *MockJobRepository) MakeJobDue(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job, exists := m.jobs[id]; exists {
+		job.RunAt = time.Now()
+	}
+}
@@ -2,13 +2,20 @@ package testutil
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"shipping-api/internal/core/domain"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type MockShippingProvider struct {
 	name           string
 	endpoint       string
+	capabilities   domain.ProviderCapabilities
 	createShipment func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error)
 }
 
@@ -16,13 +23,23 @@ func NewMockShippingProvider(name, endpoint string) *MockShippingProvider {
 	return &MockShippingProvider{
 		name:     name,
 		endpoint: endpoint,
+		capabilities: domain.ProviderCapabilities{
+			SupportsCOD:       true,
+			SupportsInsurance: true,
+		},
 		createShipment: func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error) {
+			requestBody, _ := json.Marshal(request)
 			return &domain.ShipmentResponse{
 				Provider:   name,
 				Success:    true,
 				TrackingID: "TRACK123",
 				AWB:        "AWB123",
 				Message:    "Success",
+				Exchange: &domain.ProviderExchange{
+					RequestBody:  requestBody,
+					StatusCode:   200,
+					ResponseBody: []byte(`{"trackingId":"TRACK123","awb":"AWB123","message":"Success"}`),
+				},
 			}, nil
 		},
 	}
@@ -32,6 +49,17 @@ func (m *MockShippingProvider) CreateShipment(ctx context.Context, request *doma
 	return m.createShipment(ctx, request)
 }
 
+func (m *MockShippingProvider) TransformRequest(request *domain.GenericShippingRequest) (*domain.TransformResult, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TransformResult{
+		Provider: m.name,
+		Payload:  payload,
+	}, nil
+}
+
 func (m *MockShippingProvider) GetProviderName() string {
 	return m.name
 }
@@ -40,13 +68,24 @@ func (m *MockShippingProvider) GetEndpoint() string {
 	return m.endpoint
 }
 
+func (m *MockShippingProvider) GetCapabilities() domain.ProviderCapabilities {
+	return m.capabilities
+}
+
+func (m *MockShippingProvider) SetCapabilities(capabilities domain.ProviderCapabilities) {
+	m.capabilities = capabilities
+}
+
 func (m *MockShippingProvider) SetCreateShipmentFunc(fn func(ctx context.Context, request *domain.GenericShippingRequest) (*domain.ShipmentResponse, error)) {
 	m.createShipment = fn
 }
 
 type MockRepository struct {
-	records map[string]*domain.ShipmentRecord
-	mu      sync.RWMutex
+	records  map[string]*domain.ShipmentRecord
+	attempts []*domain.ShipmentAttempt
+	history  []*domain.StatusChange
+	outbox   []*domain.OutboxEvent
+	mu       sync.RWMutex
 }
 
 func NewMockRepository() *MockRepository {
@@ -58,16 +97,198 @@ func NewMockRepository() *MockRepository {
 func (m *MockRepository) Save(ctx context.Context, record *domain.ShipmentRecord) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	record.PopulateIndexFields()
+	if record.TenantID == "" {
+		record.TenantID = domain.TenantIDFromContext(ctx)
+	}
+	if record.NextPollAt.IsZero() {
+		record.NextPollAt = record.CreatedAt
+	}
 	m.records[record.ID] = record
+	m.appendHistory(record.InitialStatusChange())
+	created, err := record.CreatedEvent(domain.RequestIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	m.appendOutbox(created)
+	return nil
+}
+
+func (m *MockRepository) UpdateStatus(ctx context.Context, change *domain.StatusChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, exists := m.records[change.ShipmentID]
+	if !exists || !inScope(ctx, record.TenantID) {
+		return domain.ErrShipmentNotFound
+	}
+	if change.EventID != "" {
+		for _, existing := range m.history {
+			if existing.ShipmentID == change.ShipmentID && existing.EventID == change.EventID {
+				return domain.ErrDuplicateStatusChange
+			}
+		}
+	}
+	if err := domain.ValidateTransition(record.Status, change.ToStatus); err != nil {
+		return err
+	}
+	change.FromStatus = record.Status
+	if change.OccurredAt.IsZero() {
+		change.OccurredAt = time.Now()
+	}
+	record.Status = change.ToStatus
+	record.StatusUpdatedAt = change.OccurredAt
+	m.appendHistory(change)
+	events, err := change.Events()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		event.TenantID = record.TenantID
+	}
+	m.appendOutbox(events...)
+	return nil
+}
+
+func (m *MockRepository) FindStatusHistory(ctx context.Context, shipmentID string) ([]*domain.StatusChange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	record, exists := m.records[shipmentID]
+	if !exists || !inScope(ctx, record.TenantID) {
+		return nil, nil
+	}
+	var results []*domain.StatusChange
+	for _, change := range m.history {
+		if change.ShipmentID == shipmentID {
+			results = append(results, change)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockRepository) ClaimShipmentsForPolling(ctx context.Context, provider string, policy domain.TrackingPolicy, lease time.Duration) ([]*domain.ShipmentRecord, func() error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var due []*domain.ShipmentRecord
+	for _, record := range m.records {
+		if record.Provider == provider && record.Success && !record.Status.IsTerminal() &&
+			!record.NextPollAt.After(now) && policy.Trackable(record) {
+			due = append(due, record)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextPollAt.Before(due[j].NextPollAt) })
+	if len(due) > policy.BatchSize {
+		due = due[:policy.BatchSize]
+	}
+	for _, record := range due {
+		record.NextPollAt = now.Add(lease)
+	}
+	return due, func() error { return nil }, nil
+}
+
+func (m *MockRepository) SchedulePoll(ctx context.Context, shipmentID string, nextPollAt time.Time, unchangedPolls int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, exists := m.records[shipmentID]
+	if !exists {
+		return domain.ErrShipmentNotFound
+	}
+	record.NextPollAt = nextPollAt
+	record.UnchangedPolls = unchangedPolls
+	return nil
+}
+
+func (m *MockRepository) appendHistory(change *domain.StatusChange) {
+	if change.ID == "" {
+		change.ID = fmt.Sprintf("status-%d", len(m.history)+1)
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	m.history = append(m.history, change)
+}
+
+func (m *MockRepository) appendOutbox(events ...*domain.ShipmentEvent) {
+	for _, event := range events {
+		sequence := int64(len(m.outbox) + 1)
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("event-%d", sequence)
+		}
+		m.outbox = append(m.outbox, &domain.OutboxEvent{
+			Sequence:      sequence,
+			Event:         event,
+			NextAttemptAt: event.OccurredAt,
+			CreatedAt:     time.Now(),
+		})
+	}
+}
+
+func (m *MockRepository) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	blocked := make(map[string]bool)
+	var claimed []*domain.OutboxEvent
+	for _, event := range m.outbox {
+		if event.PublishedAt != nil || event.DeadAt != nil {
+			continue
+		}
+		shipmentID := event.Event.ShipmentID
+		if shipmentID != "" && blocked[shipmentID] {
+			continue
+		}
+		blocked[shipmentID] = true
+		if event.NextAttemptAt.After(now) || len(claimed) == limit {
+			continue
+		}
+		event.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, event)
+	}
+	return claimed, nil
+}
+
+func (m *MockRepository) MarkOutboxPublished(ctx context.Context, sequence int64, publishedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event := m.outbox[sequence-1]
+	event.Attempts++
+	event.LastError = ""
+	event.PublishedAt = &publishedAt
 	return nil
 }
 
+func (m *MockRepository) RescheduleOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.outbox[event.Sequence-1]
+	stored.Attempts = event.Attempts
+	stored.NextAttemptAt = event.NextAttemptAt
+	stored.LastError = event.LastError
+	return nil
+}
+
+func (m *MockRepository) DeadLetterOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.outbox[event.Sequence-1]
+	stored.Attempts = event.Attempts
+	stored.LastError = event.LastError
+	stored.DeadAt = event.DeadAt
+	return nil
+}
+
+func (m *MockRepository) GetOutbox() []*domain.OutboxEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*domain.OutboxEvent(nil), m.outbox...)
+}
+
 func (m *MockRepository) FindByID(ctx context.Context, id string) (*domain.ShipmentRecord, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	record, exists := m.records[id]
-	if !exists {
-		return nil, nil
+	if !exists || !inScope(ctx, record.TenantID) {
+		return nil, domain.ErrShipmentNotFound
 	}
 	return record, nil
 }
@@ -78,7 +299,7 @@ func (m *MockRepository) FindByProvider(ctx context.Context, provider string, li
 	var results []*domain.ShipmentRecord
 	count := 0
 	for _, record := range m.records {
-		if record.Provider == provider && count < limit {
+		if record.Provider == provider && inScope(ctx, record.TenantID) && count < limit {
 			results = append(results, record)
 			count++
 		}
@@ -86,8 +307,235 @@ func (m *MockRepository) FindByProvider(ctx context.Context, provider string, li
 	return results, nil
 }
 
+func (m *MockRepository) FindByTrackingID(ctx context.Context, trackingID string) ([]*domain.ShipmentRecord, error) {
+	return m.findWhere(ctx, func(record *domain.ShipmentRecord) bool { return record.TrackingID == trackingID })
+}
+
+func (m *MockRepository) FindByAWB(ctx context.Context, awb string) ([]*domain.ShipmentRecord, error) {
+	return m.findWhere(ctx, func(record *domain.ShipmentRecord) bool { return record.AWB == awb })
+}
+
+func (m *MockRepository) FindByReference(ctx context.Context, reference string) ([]*domain.ShipmentRecord, error) {
+	return m.findWhere(ctx, func(record *domain.ShipmentRecord) bool { return containsString(record.ReferenceNumbers, reference) })
+}
+
+func (m *MockRepository) findWhere(ctx context.Context, match func(*domain.ShipmentRecord) bool) ([]*domain.ShipmentRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.ShipmentRecord
+	for _, record := range m.records {
+		if match(record) && inScope(ctx, record.TenantID) {
+			results = append(results, record)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+	return results, nil
+}
+
+func (m *MockRepository) Search(ctx context.Context, filter domain.ShipmentFilter) (*domain.ShipmentPage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var cursor *domain.Cursor
+	if filter.Cursor != "" {
+		var err error
+		if cursor, err = domain.DecodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	ascending := filter.SortOrder == domain.SortAscending
+	before := func(a, b *domain.ShipmentRecord) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt) == ascending
+		}
+		return a.ID != b.ID && (a.ID < b.ID) == ascending
+	}
+
+	var matches []*domain.ShipmentRecord
+	for _, record := range m.records {
+		if !matchesFilter(record, filter) || !inScope(ctx, record.TenantID) {
+			continue
+		}
+		if cursor != nil && !before(&domain.ShipmentRecord{CreatedAt: cursor.CreatedAt, ID: cursor.ID}, record) {
+			continue
+		}
+		matches = append(matches, record)
+	}
+	sort.Slice(matches, func(i, j int) bool { return before(matches[i], matches[j]) })
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	page := &domain.ShipmentPage{Records: matches}
+	if len(matches) > limit {
+		page.Records = matches[:limit]
+		last := page.Records[limit-1]
+		page.NextCursor = domain.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+func matchesFilter(record *domain.ShipmentRecord, filter domain.ShipmentFilter) bool {
+	switch {
+	case filter.Provider != "" && record.Provider != filter.Provider:
+		return false
+	case filter.Success != nil && record.Success != *filter.Success:
+		return false
+	case !filter.CreatedFrom.IsZero() && record.CreatedAt.Before(filter.CreatedFrom):
+		return false
+	case !filter.CreatedTo.IsZero() && !record.CreatedAt.Before(filter.CreatedTo):
+		return false
+	case filter.TrackingID != "" && record.TrackingID != filter.TrackingID:
+		return false
+	case filter.AWB != "" && record.AWB != filter.AWB:
+		return false
+	case filter.ReferenceNumber != "" && !containsString(record.ReferenceNumbers, filter.ReferenceNumber):
+		return false
+	case filter.ConsigneeEmail != "" && record.ConsigneeEmailHash != domain.HashEmail(filter.ConsigneeEmail):
+		return false
+	case filter.Status != "" && record.Status != filter.Status:
+		return false
+	case filter.Destination != "" && !strings.EqualFold(record.DestinationCountry, filter.Destination):
+		return false
+	}
+	return true
+}
+
+// inScope mirrors the repository's tenant scoping: a context without a
+// tenant sees everything.
+func inScope(ctx context.Context, tenantID string) bool {
+	tenant := domain.TenantFromContext(ctx)
+	return tenant == nil || tenant.ID == tenantID
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockRepository) SaveAttempt(ctx context.Context, attempt *domain.ShipmentAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if attempt.TenantID == "" {
+		attempt.TenantID = domain.TenantIDFromContext(ctx)
+	}
+	attempt.AttemptNumber = 1
+	for _, existing := range m.attempts {
+		if existing.RequestID == attempt.RequestID && existing.Provider == attempt.Provider {
+			attempt.AttemptNumber++
+		}
+	}
+	m.attempts = append(m.attempts, attempt)
+	failed, err := attempt.FailedEvent()
+	if err != nil {
+		return err
+	}
+	if failed != nil {
+		m.appendOutbox(failed)
+	}
+	return nil
+}
+
+func (m *MockRepository) FindAttemptsByRequestID(ctx context.Context, requestID string) ([]*domain.ShipmentAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.ShipmentAttempt
+	for _, attempt := range m.attempts {
+		if attempt.RequestID == requestID && inScope(ctx, attempt.TenantID) {
+			results = append(results, attempt)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockRepository) FindAttemptsByShipmentID(ctx context.Context, shipmentID string) ([]*domain.ShipmentAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.ShipmentAttempt
+	for _, attempt := range m.attempts {
+		if attempt.ShipmentID == shipmentID && inScope(ctx, attempt.TenantID) {
+			results = append(results, attempt)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockRepository) GetAttemptCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.attempts)
+}
+
 func (m *MockRepository) GetRecordCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.records)
 }
+
+type MockWebhookParser struct {
+	name   string
+	token  string
+	events []*domain.TrackingEvent
+}
+
+func NewMockWebhookParser(name, token string, events ...*domain.TrackingEvent) *MockWebhookParser {
+	return &MockWebhookParser{name: name, token: token, events: events}
+}
+
+func (m *MockWebhookParser) GetProviderName() string {
+	return m.name
+}
+
+func (m *MockWebhookParser) VerifyWebhook(header http.Header, body []byte) error {
+	if header.Get("X-Webhook-Token") != m.token {
+		return domain.ErrWebhookSignature
+	}
+	return nil
+}
+
+func (m *MockWebhookParser) ParseWebhook(body []byte) ([]*domain.TrackingEvent, error) {
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("body is not JSON")
+	}
+	return m.events, nil
+}
+
+type MockTrackingProvider struct {
+	name   string
+	policy domain.TrackingPolicy
+	track  func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error)
+	calls  int
+	mu     sync.Mutex
+}
+
+func NewMockTrackingProvider(name string, policy domain.TrackingPolicy, track func(shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error)) *MockTrackingProvider {
+	return &MockTrackingProvider{name: name, policy: policy, track: track}
+}
+
+func (m *MockTrackingProvider) GetProviderName() string {
+	return m.name
+}
+
+func (m *MockTrackingProvider) GetTrackingPolicy() domain.TrackingPolicy {
+	return m.policy
+}
+
+func (m *MockTrackingProvider) TrackShipments(ctx context.Context, shipments []*domain.ShipmentRecord) ([]*domain.TrackingEvent, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	return m.track(shipments)
+}
+
+func (m *MockTrackingProvider) GetCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"context"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MockUsageRepository struct {
+	counters map[usageKey]*domain.UsageCounter
+	mu       sync.Mutex
+}
+
+type usageKey struct {
+	tenantID    string
+	metric      string
+	windowStart time.Time
+}
+
+func NewMockUsageRepository() *MockUsageRepository {
+	return &MockUsageRepository{
+		counters: make(map[usageKey]*domain.UsageCounter),
+	}
+}
+
+func (m *MockUsageRepository) IncrementUsage(ctx context.Context, counter *domain.UsageCounter, amount, limit int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := usageKey{counter.TenantID, counter.Metric, counter.WindowStart.UTC()}
+	stored, exists := m.counters[key]
+	if !exists {
+		stored = &domain.UsageCounter{
+			TenantID:    counter.TenantID,
+			Metric:      counter.Metric,
+			WindowStart: counter.WindowStart,
+			ExpiresAt:   counter.ExpiresAt,
+		}
+	}
+	if stored.Count+amount > limit {
+		counter.Count = stored.Count
+		return false, nil
+	}
+	stored.Count += amount
+	m.counters[key] = stored
+	counter.Count = stored.Count
+	return true, nil
+}
+
+func (m *MockUsageRepository) ListUsage(ctx context.Context, metric string, windowStart time.Time) ([]*domain.UsageCounter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var results []*domain.UsageCounter
+	for key, counter := range m.counters {
+		if key.metric == metric && key.windowStart.Equal(windowStart) {
+			copied := *counter
+			results = append(results, &copied)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].TenantID < results[j].TenantID })
+	return results, nil
+}
+
+func (m *MockUsageRepository) DeleteExpiredUsage(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for key, counter := range m.counters {
+		if !counter.ExpiresAt.After(now) {
+			delete(m.counters, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,228 @@
+package testutil
+
+import (
+	"context"
+	"shipping-api/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MockWebhookRepository struct {
+	subscriptions map[string]*domain.WebhookSubscription
+	deliveries    map[string]*domain.WebhookDelivery
+	attempts      []*domain.WebhookDeliveryAttempt
+	mu            sync.RWMutex
+}
+
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		subscriptions: make(map[string]*domain.WebhookSubscription),
+		deliveries:    make(map[string]*domain.WebhookDelivery),
+	}
+}
+
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if subscription.TenantID == "" {
+		subscription.TenantID = domain.TenantIDFromContext(ctx)
+	}
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *MockWebhookRepository) FindSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	subscription, exists := m.subscriptions[id]
+	if !exists || !inScope(ctx, subscription.TenantID) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		if inScope(ctx, subscription.TenantID) {
+			results = append(results, subscription)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results, nil
+}
+
+func (m *MockWebhookRepository) DeactivateSubscription(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription, exists := m.subscriptions[id]
+	if !exists || !inScope(ctx, subscription.TenantID) {
+		return domain.ErrSubscriptionNotFound
+	}
+	subscription.Active = false
+	return nil
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.deliveries {
+		if existing.SubscriptionID == delivery.SubscriptionID && existing.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var due []*domain.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == domain.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, delivery := range due {
+		delivery.NextAttemptAt = now.Add(lease)
+	}
+	return due, nil
+}
+
+func (m *MockWebhookRepository) RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[delivery.ID] = delivery
+	m.attempts = append(m.attempts, attempt)
+	return nil
+}
+
+func (m *MockWebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	delivery, exists := m.deliveries[id]
+	if !exists || !m.deliveryInScope(ctx, delivery) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (m *MockWebhookRepository) FindDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if filter.SubscriptionID != "" && delivery.SubscriptionID != filter.SubscriptionID {
+			continue
+		}
+		if filter.Status != "" && delivery.Status != filter.Status {
+			continue
+		}
+		if !m.deliveryInScope(ctx, delivery) {
+			continue
+		}
+		results = append(results, delivery)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].UpdatedAt.After(results[j].UpdatedAt) })
+	return results, nil
+}
+
+func (m *MockWebhookRepository) FindDeliveryAttempts(ctx context.Context, deliveryID string) ([]*domain.WebhookDeliveryAttempt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []*domain.WebhookDeliveryAttempt
+	for _, attempt := range m.attempts {
+		if attempt.DeliveryID == deliveryID {
+			results = append(results, attempt)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockWebhookRepository) ReplayDelivery(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery, exists := m.deliveries[id]
+	if !exists || !m.deliveryInScope(ctx, delivery) {
+		return domain.ErrDeliveryNotFound
+	}
+	if delivery.Status != domain.DeliveryDead {
+		return domain.ErrDeliveryNotDead
+	}
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	return nil
+}
+
+// deliveryInScope scopes a delivery through its subscription, like the
+// repository's join.
+func (m *MockWebhookRepository) deliveryInScope(ctx context.Context, delivery *domain.WebhookDelivery) bool {
+	subscription, exists := m.subscriptions[delivery.SubscriptionID]
+	return !exists || inScope(ctx, subscription.TenantID)
+}
+
+type MockWebhookSender struct {
+	statusCode int
+	err        error
+	sent       []*domain.WebhookDelivery
+	mu         sync.Mutex
+}
+
+func NewMockWebhookSender(statusCode int, err error) *MockWebhookSender {
+	return &MockWebhookSender{statusCode: statusCode, err: err}
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, delivery)
+	return m.statusCode, m.err
+}
+
+func (m *MockWebhookSender) GetSentCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+type MockEventSink struct {
+	events []*domain.ShipmentEvent
+	err    error
+	mu     sync.Mutex
+}
+
+func NewMockEventSink() *MockEventSink {
+	return &MockEventSink{}
+}
+
+func (m *MockEventSink) Publish(ctx context.Context, event *domain.ShipmentEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *MockEventSink) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *MockEventSink) GetEvents() []*domain.ShipmentEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.ShipmentEvent(nil), m.events...)
+}
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS shipment_attempts (
+    id VARCHAR(36) PRIMARY KEY,
+    request_id VARCHAR(64) NOT NULL,
+    shipment_id VARCHAR(36) REFERENCES shipment_records(id),
+    provider VARCHAR(50) NOT NULL,
+    attempt_number INT NOT NULL DEFAULT 1,
+    request_body BYTEA,
+    response_status INT NOT NULL DEFAULT 0,
+    response_headers JSONB,
+    response_body BYTEA,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error_category VARCHAR(32) NOT NULL DEFAULT '',
+    error_message TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (request_id, provider, attempt_number)
+);
+
+CREATE INDEX idx_shipment_attempts_shipment_id ON shipment_attempts(shipment_id);
+CREATE INDEX idx_shipment_attempts_provider_created_at ON shipment_attempts(provider, created_at DESC);
+CREATE INDEX idx_shipment_attempts_error_category ON shipment_attempts(error_category) WHERE error_category <> '';
@@ -0,0 +1 @@
+ALTER TABLE shipment_records DROP COLUMN IF EXISTS raw_response;
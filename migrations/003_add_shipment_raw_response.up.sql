@@ -0,0 +1,11 @@
+ALTER TABLE shipment_records ADD COLUMN IF NOT EXISTS raw_response BYTEA;
+
+-- transformed_payload used to hold the carrier's parsed response; move it to
+-- raw_response so the column only ever contains what was sent.
+UPDATE shipment_records
+SET raw_response = convert_to(transformed_payload::text, 'UTF8'),
+    transformed_payload = 'null'::jsonb
+WHERE raw_response IS NULL;
+
+COMMENT ON COLUMN shipment_records.transformed_payload IS 'Provider-native request body sent to the carrier';
+COMMENT ON COLUMN shipment_records.raw_response IS 'Raw response body returned by the carrier';
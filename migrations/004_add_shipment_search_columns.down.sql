@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_shipment_records_consignee_email_hash;
+DROP INDEX IF EXISTS idx_shipment_records_destination_country;
+DROP INDEX IF EXISTS idx_shipment_records_reference_numbers;
+DROP INDEX IF EXISTS idx_shipment_records_awb;
+DROP INDEX IF EXISTS idx_shipment_records_tracking_id;
+
+ALTER TABLE shipment_records
+    DROP COLUMN IF EXISTS consignee_email_hash,
+    DROP COLUMN IF EXISTS destination_country,
+    DROP COLUMN IF EXISTS reference_numbers,
+    DROP COLUMN IF EXISTS awb,
+    DROP COLUMN IF EXISTS tracking_id;
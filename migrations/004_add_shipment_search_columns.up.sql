@@ -0,0 +1,28 @@
+ALTER TABLE shipment_records
+    ADD COLUMN IF NOT EXISTS tracking_id VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS awb VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS reference_numbers TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS destination_country TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS consignee_email_hash VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE shipment_records
+SET tracking_id = COALESCE(provider_response->>'trackingId', ''),
+    awb = COALESCE(provider_response->>'awb', ''),
+    reference_numbers = CASE
+        WHEN jsonb_typeof(generic_payload->'referenceNumbers') = 'array'
+            THEN ARRAY(SELECT jsonb_array_elements_text(generic_payload->'referenceNumbers'))
+        ELSE '{}'
+    END,
+    destination_country = upper(COALESCE(generic_payload->'consignee'->'address'->>'countryCode', '')),
+    consignee_email_hash = CASE
+        WHEN COALESCE(trim(generic_payload->'consignee'->'contact'->>'emailAddress'), '') = '' THEN ''
+        ELSE encode(sha256(convert_to(lower(trim(generic_payload->'consignee'->'contact'->>'emailAddress')), 'UTF8')), 'hex')
+    END;
+
+CREATE INDEX idx_shipment_records_tracking_id ON shipment_records(tracking_id) WHERE tracking_id <> '';
+CREATE INDEX idx_shipment_records_awb ON shipment_records(awb) WHERE awb <> '';
+CREATE INDEX idx_shipment_records_reference_numbers ON shipment_records USING GIN (reference_numbers);
+CREATE INDEX idx_shipment_records_destination_country ON shipment_records(destination_country, created_at DESC);
+CREATE INDEX idx_shipment_records_consignee_email_hash ON shipment_records(consignee_email_hash) WHERE consignee_email_hash <> '';
+
+COMMENT ON COLUMN shipment_records.consignee_email_hash IS 'Hex SHA-256 of the lower-cased, trimmed consignee email';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_shipment_records_status;
+DROP TABLE IF EXISTS shipment_status_history;
+
+ALTER TABLE shipment_records
+    DROP COLUMN IF EXISTS status_updated_at,
+    DROP COLUMN IF EXISTS status;
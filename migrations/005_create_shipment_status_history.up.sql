@@ -0,0 +1,24 @@
+ALTER TABLE shipment_records
+    ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'created',
+    ADD COLUMN IF NOT EXISTS status_updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE shipment_records SET status_updated_at = created_at;
+
+CREATE TABLE IF NOT EXISTS shipment_status_history (
+    id VARCHAR(36) PRIMARY KEY,
+    shipment_id VARCHAR(36) NOT NULL REFERENCES shipment_records(id),
+    from_status VARCHAR(32) NOT NULL DEFAULT '',
+    to_status VARCHAR(32) NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO shipment_status_history (id, shipment_id, to_status, source, occurred_at, created_at)
+SELECT gen_random_uuid()::text, id, status, 'api', created_at, created_at
+FROM shipment_records;
+
+CREATE INDEX idx_shipment_status_history_shipment_id ON shipment_status_history(shipment_id, occurred_at);
+CREATE INDEX idx_shipment_records_status ON shipment_records(status, status_updated_at);
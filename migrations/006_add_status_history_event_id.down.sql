@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_shipment_status_history_event_id;
+
+ALTER TABLE shipment_status_history
+    DROP COLUMN IF EXISTS carrier_status,
+    DROP COLUMN IF EXISTS event_id;
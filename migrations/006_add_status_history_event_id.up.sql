@@ -0,0 +1,6 @@
+ALTER TABLE shipment_status_history
+    ADD COLUMN IF NOT EXISTS event_id VARCHAR(128) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS carrier_status VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_shipment_status_history_event_id
+    ON shipment_status_history(shipment_id, event_id) WHERE event_id <> '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_shipment_records_next_poll_at;
+
+ALTER TABLE shipment_records
+    DROP COLUMN IF EXISTS unchanged_polls,
+    DROP COLUMN IF EXISTS next_poll_at;
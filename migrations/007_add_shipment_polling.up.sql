@@ -0,0 +1,6 @@
+ALTER TABLE shipment_records
+    ADD COLUMN IF NOT EXISTS next_poll_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS unchanged_polls INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_shipment_records_next_poll_at ON shipment_records(provider, next_poll_at)
+    WHERE success AND status NOT IN ('delivered', 'returned', 'cancelled');
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    sequence BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    shipment_id VARCHAR(36) NOT NULL DEFAULT '',
+    data JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    dead_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_outbox_pending ON event_outbox(sequence) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX idx_event_outbox_pending_shipment ON event_outbox(shipment_id, sequence) WHERE published_at IS NULL AND dead_at IS NULL;
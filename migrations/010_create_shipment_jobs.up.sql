@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS shipment_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    provider VARCHAR(50) NOT NULL DEFAULT '',
+    request_id VARCHAR(36) NOT NULL,
+    request JSONB NOT NULL,
+    results JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_shipment_jobs_due ON shipment_jobs(run_at) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_shipment_jobs_status ON shipment_jobs(status, created_at DESC);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_api_keys_tenant;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant;
+DROP INDEX IF EXISTS idx_shipment_jobs_tenant_status;
+DROP INDEX IF EXISTS idx_shipment_attempts_tenant_request_id;
+DROP INDEX IF EXISTS idx_shipment_records_tenant_destination;
+DROP INDEX IF EXISTS idx_shipment_records_tenant_status;
+DROP INDEX IF EXISTS idx_shipment_records_tenant_provider;
+DROP INDEX IF EXISTS idx_shipment_records_tenant_created_at;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE shipment_jobs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE shipment_attempts DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE shipment_records DROP COLUMN IF EXISTS tenant_id;
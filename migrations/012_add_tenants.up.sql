@@ -0,0 +1,15 @@
+ALTER TABLE shipment_records ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE shipment_attempts ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE shipment_jobs ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_shipment_records_tenant_created_at ON shipment_records(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_records_tenant_provider ON shipment_records(tenant_id, provider, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_records_tenant_status ON shipment_records(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_records_tenant_destination ON shipment_records(tenant_id, destination_country, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_attempts_tenant_request_id ON shipment_attempts(tenant_id, request_id);
+CREATE INDEX IF NOT EXISTS idx_shipment_jobs_tenant_status ON shipment_jobs(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys(tenant_id, created_at DESC);
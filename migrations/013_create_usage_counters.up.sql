@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS usage_counters (
+    tenant_id VARCHAR(64) NOT NULL,
+    metric VARCHAR(16) NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, metric, window_start)
+);
+
+CREATE INDEX idx_usage_counters_metric_window ON usage_counters(metric, window_start);
+CREATE INDEX idx_usage_counters_expires_at ON usage_counters(expires_at);
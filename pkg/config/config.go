@@ -1,23 +1,161 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	ServerPort      string
-	DatabaseURL     string
-	ProviderAURL    string
-	ProviderBURL    string
+	ServerPort     string
+	DatabaseURL    string
+	ProviderAURL   string
+	ProviderBURL   string
+	CodeTablesFile string
+	WebhookSecretA string
+	WebhookSecretB string
+
+	ProviderATrackingURL string
+	ProviderBTrackingURL string
+	PollerInterval       time.Duration
+
+	WebhookDispatchInterval time.Duration
+
+	OutboxRelayInterval time.Duration
+	EventLog            bool
+	EventFile           string
+
+	JobInterval    time.Duration
+	JobConcurrency int
+	JobMaxAttempts int
+
+	CSVProfilesFile  string
+	BatchConcurrency int
+	BatchTimeout     time.Duration
+
+	StrictDecoding bool
+
+	AuthEnabled     bool
+	BootstrapAPIKey string
+	TenantsFile     string
+
+	MaxBodyBytes   int64
+	RequestTimeout time.Duration
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
-		ServerPort:   getEnv("PORT", "8080"),
-		DatabaseURL:  getEnv("DATABASE_URL", ""),
-		ProviderAURL: getEnv("PROVIDER_A_URL", "https://a.local/createShipping"),
-		ProviderBURL: getEnv("PROVIDER_B_URL", "https://b.local/createShipping"),
+		ServerPort:     getEnv("PORT", "8080"),
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+		ProviderAURL:   getEnv("PROVIDER_A_URL", "https://a.local/createShipping"),
+		ProviderBURL:   getEnv("PROVIDER_B_URL", "https://b.local/createShipping"),
+		CodeTablesFile: getEnv("CODE_TABLES_FILE", ""),
+		WebhookSecretA: getEnv("WEBHOOK_SECRET_A", ""),
+		WebhookSecretB: getEnv("WEBHOOK_SECRET_B", ""),
+
+		ProviderATrackingURL: getEnv("PROVIDER_A_TRACKING_URL", ""),
+		ProviderBTrackingURL: getEnv("PROVIDER_B_TRACKING_URL", ""),
+
+		EventLog:  getEnv("EVENT_LOG", "false") == "true",
+		EventFile: getEnv("EVENT_FILE", ""),
+
+		CSVProfilesFile: getEnv("CSV_PROFILES_FILE", ""),
+
+		StrictDecoding: getEnv("STRICT_DECODING", "false") == "true",
+
+		AuthEnabled:     getEnv("AUTH_ENABLED", "true") == "true",
+		BootstrapAPIKey: getEnv("BOOTSTRAP_API_KEY", ""),
+		TenantsFile:     getEnv("TENANTS_FILE", ""),
+	}
+
+	interval, err := time.ParseDuration(getEnv("POLLER_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid POLLER_INTERVAL: %w", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid POLLER_INTERVAL: must be positive")
+	}
+	cfg.PollerInterval = interval
+
+	dispatchInterval, err := time.ParseDuration(getEnv("WEBHOOK_DISPATCH_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL: %w", err)
+	}
+	if dispatchInterval <= 0 {
+		return nil, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL: must be positive")
+	}
+	cfg.WebhookDispatchInterval = dispatchInterval
+
+	relayInterval, err := time.ParseDuration(getEnv("OUTBOX_RELAY_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL: %w", err)
+	}
+	if relayInterval <= 0 {
+		return nil, fmt.Errorf("invalid OUTBOX_RELAY_INTERVAL: must be positive")
+	}
+	cfg.OutboxRelayInterval = relayInterval
+
+	jobInterval, err := time.ParseDuration(getEnv("JOB_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JOB_INTERVAL: %w", err)
+	}
+	if jobInterval <= 0 {
+		return nil, fmt.Errorf("invalid JOB_INTERVAL: must be positive")
+	}
+	cfg.JobInterval = jobInterval
+
+	if cfg.JobConcurrency, err = strconv.Atoi(getEnv("JOB_CONCURRENCY", "4")); err != nil {
+		return nil, fmt.Errorf("invalid JOB_CONCURRENCY: %w", err)
+	}
+	if cfg.JobMaxAttempts, err = strconv.Atoi(getEnv("JOB_MAX_ATTEMPTS", "5")); err != nil {
+		return nil, fmt.Errorf("invalid JOB_MAX_ATTEMPTS: %w", err)
+	}
+	if cfg.JobMaxAttempts < 1 {
+		return nil, fmt.Errorf("invalid JOB_MAX_ATTEMPTS: must be at least 1")
+	}
+	if cfg.BatchConcurrency, err = strconv.Atoi(getEnv("BATCH_CONCURRENCY", "8")); err != nil {
+		return nil, fmt.Errorf("invalid BATCH_CONCURRENCY: %w", err)
+	}
+	if cfg.BatchTimeout, err = time.ParseDuration(getEnv("BATCH_TIMEOUT", "10m")); err != nil {
+		return nil, fmt.Errorf("invalid BATCH_TIMEOUT: %w", err)
+	}
+	if cfg.MaxBodyBytes, err = strconv.ParseInt(getEnv("MAX_BODY_BYTES", "10485760"), 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid MAX_BODY_BYTES: %w", err)
+	}
+
+	requestTimeout, err := time.ParseDuration(getEnv("REQUEST_TIMEOUT", "60s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REQUEST_TIMEOUT: %w", err)
+	}
+	cfg.RequestTimeout = requestTimeout
+
+	if cfg.ReadHeaderTimeout, err = time.ParseDuration(getEnv("READ_HEADER_TIMEOUT", "10s")); err != nil {
+		return nil, fmt.Errorf("invalid READ_HEADER_TIMEOUT: %w", err)
+	}
+	if cfg.ReadTimeout, err = time.ParseDuration(getEnv("READ_TIMEOUT", "60s")); err != nil {
+		return nil, fmt.Errorf("invalid READ_TIMEOUT: %w", err)
+	}
+	if cfg.WriteTimeout, err = time.ParseDuration(getEnv("WRITE_TIMEOUT", "75s")); err != nil {
+		return nil, fmt.Errorf("invalid WRITE_TIMEOUT: %w", err)
+	}
+	if cfg.IdleTimeout, err = time.ParseDuration(getEnv("IDLE_TIMEOUT", "120s")); err != nil {
+		return nil, fmt.Errorf("invalid IDLE_TIMEOUT: %w", err)
+	}
+	if cfg.ShutdownTimeout, err = time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "75s")); err != nil {
+		return nil, fmt.Errorf("invalid SHUTDOWN_TIMEOUT: %w", err)
+	}
+	// The write timeout covers the handler, so a shorter one would cut off
+	// requests before REQUEST_TIMEOUT can answer them.
+	if cfg.WriteTimeout > 0 && cfg.RequestTimeout > 0 && cfg.WriteTimeout <= cfg.RequestTimeout {
+		return nil, fmt.Errorf("WRITE_TIMEOUT (%s) must be longer than REQUEST_TIMEOUT (%s)", cfg.WriteTimeout, cfg.RequestTimeout)
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -37,6 +175,54 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadCodeTables reads per-provider code translation overrides keyed by
+// provider name, then code field, then canonical code.
+func LoadCodeTables(path string) (map[string]map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code tables: %w", err)
+	}
+
+	var tables map[string]map[string]map[string]string
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return nil, fmt.Errorf("failed to parse code tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+// LoadCSVProfiles reads batch upload column profiles keyed by profile name.
+// Each profile is left as raw JSON for the caller to decode.
+func LoadCSVProfiles(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV profiles: %w", err)
+	}
+
+	var profiles map[string]json.RawMessage
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse CSV profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// LoadTenants reads tenant settings keyed by tenant ID. Each tenant is left
+// as raw JSON for the caller to decode.
+func LoadTenants(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants: %w", err)
+	}
+
+	var tenants map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
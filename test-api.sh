@@ -1,6 +1,7 @@
 #!/bin/bash
 
 API_URL="http://localhost:38089"
+API_KEY="${API_KEY:-sk_local_development_key}"
 
 if ! command -v jq &> /dev/null; then
     echo "jq not found, install it for pretty output: brew install jq"
@@ -15,6 +16,7 @@ echo ""
 echo "1. Testing Provider A"
 curl -s -X POST "${API_URL}/api/v1/createShipping?provider=A" \
   -H "Content-Type: application/json" \
+  -H "X-API-Key: ${API_KEY}" \
   -d @sample-payload.json | jq '.'
 echo ""
 sleep 1
@@ -22,6 +24,7 @@ sleep 1
 echo "2. Testing Provider B"
 curl -s -X POST "${API_URL}/api/v1/createShipping?provider=B" \
   -H "Content-Type: application/json" \
+  -H "X-API-Key: ${API_KEY}" \
   -d @sample-payload.json | jq '.'
 echo ""
 sleep 1
@@ -29,6 +32,7 @@ sleep 1
 echo "3. Testing broadcast (both providers)"
 curl -s -X POST "${API_URL}/api/v1/createShipping" \
   -H "Content-Type: application/json" \
+  -H "X-API-Key: ${API_KEY}" \
   -d @sample-payload.json | jq '.'
 echo ""
 sleep 1
@@ -36,6 +40,7 @@ sleep 1
 echo "4. Testing invalid provider"
 curl -s -X POST "${API_URL}/api/v1/createShipping?provider=X" \
   -H "Content-Type: application/json" \
+  -H "X-API-Key: ${API_KEY}" \
   -d @sample-payload.json | jq '.'
 echo ""
 sleep 1
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"shipping-api/internal/adapters/providers/providerA"
@@ -13,6 +14,7 @@ import (
 	"shipping-api/internal/core/service"
 	"shipping-api/internal/handlers"
 	"shipping-api/internal/testutil"
+	"strings"
 	"testing"
 )
 
@@ -192,6 +194,23 @@ func TestE2E_CreateShipment_ProviderFailure(t *testing.T) {
 	if mockRepo.GetRecordCount() != 0 {
 		t.Errorf("expected 0 records for failed shipment, got %d", mockRepo.GetRecordCount())
 	}
+
+	attempts, _ := mockRepo.FindAttemptsByRequestID(context.Background(), response.RequestID)
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 attempt for failed shipment, got %d", len(attempts))
+	}
+
+	if attempts[0].ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("expected response status 500, got %d", attempts[0].ResponseStatus)
+	}
+
+	if string(attempts[0].ResponseBody) != `{"error": "internal server error"}` {
+		t.Errorf("expected carrier response body, got %s", attempts[0].ResponseBody)
+	}
+
+	if len(attempts[0].RequestBody) == 0 {
+		t.Error("expected request body sent to the carrier to be recorded")
+	}
 }
 
 func TestE2E_MapperIntegration_ProviderA(t *testing.T) {
@@ -350,3 +369,278 @@ func TestE2E_ConcurrentRequests(t *testing.T) {
 		t.Errorf("expected %d records, got %d", concurrentRequests, mockRepo.GetRecordCount())
 	}
 }
+
+func TestE2E_DryRun_ReturnsProviderPayloads(t *testing.T) {
+	requests := 0
+	providerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer providerServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerA.NewAdapter(providerServer.URL))
+	shippingService.RegisterProvider(providerB.NewAdapter(providerServer.URL))
+
+	handler := handlers.NewShippingHandler(shippingService)
+
+	requestBody, _ := json.Marshal(testutil.CreateSampleShippingRequest())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=B&dryRun=true", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var result domain.TransformResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	var payload providerB.Request
+	if err := json.Unmarshal(result.Payload, &payload); err != nil {
+		t.Fatalf("failed to unmarshal provider B payload: %v", err)
+	}
+
+	if payload.Weight != 1.0 {
+		t.Errorf("expected weight 1.0 kg, got %f", payload.Weight)
+	}
+
+	if payload.ProductType != "XPS" {
+		t.Errorf("expected product type translated to XPS, got %s", payload.ProductType)
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no carrier calls, got %d", requests)
+	}
+
+	if mockRepo.GetRecordCount() != 0 {
+		t.Errorf("expected 0 records for dry run, got %d", mockRepo.GetRecordCount())
+	}
+}
+
+func TestE2E_StrictMapping_RejectsLossyRequest(t *testing.T) {
+	requests := 0
+	providerAServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer providerAServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerA.NewAdapter(providerAServer.URL))
+
+	handler := handlers.NewShippingHandler(shippingService)
+
+	request := testutil.CreateSampleShippingRequest()
+	request.Consignee.Address.Line2 = "Apartment 4"
+	request.StrictMapping = true
+	requestBody, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no carrier calls, got %d", requests)
+	}
+}
+
+func TestE2E_MappingWarningsReturned(t *testing.T) {
+	providerAServer, providerBServer := setupMockProviderServers()
+	defer providerAServer.Close()
+	defer providerBServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerA.NewAdapter(providerAServer.URL))
+
+	handler := handlers.NewShippingHandler(shippingService)
+
+	request := testutil.CreateSampleShippingRequest()
+	request.Consignee.Address.State = "Karnataka"
+	requestBody, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	var response domain.ShipmentResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	found := false
+	for _, warning := range response.Warnings {
+		if warning.Field == "consignee.address.state" && warning.Reason == domain.WarningDropped {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected dropped warning for consignee.address.state, got %v", response.Warnings)
+	}
+}
+
+func TestE2E_UnmappedCode_RejectedNamingProvider(t *testing.T) {
+	requests := 0
+	providerAServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer providerAServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerA.NewAdapter(providerAServer.URL))
+
+	handler := handlers.NewShippingHandler(shippingService)
+
+	request := testutil.CreateSampleShippingRequest()
+	request.ServiceType = "Overnight"
+	requestBody, _ := json.Marshal(request)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/createShipping?provider=A", bytes.NewBuffer(requestBody))
+	w := httptest.NewRecorder()
+
+	handler.CreateShipment(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+
+	var errorResponse map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &errorResponse); err != nil {
+		t.Fatalf("failed to unmarshal error response: %v", err)
+	}
+
+	if !strings.Contains(errorResponse["error"], "provider A") {
+		t.Errorf("expected error to name provider A, got %s", errorResponse["error"])
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no carrier calls, got %d", requests)
+	}
+}
+
+func TestE2E_StoresSentPayloadAndRawResponse(t *testing.T) {
+	var receivedBody []byte
+	providerBServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"trackingId": "B-1", "awb": "AWB-1"}`))
+	}))
+	defer providerBServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerB.NewAdapter(providerBServer.URL))
+
+	response, err := shippingService.ProcessShipment(context.Background(), testutil.CreateSampleShippingRequest(), "B")
+	if err != nil {
+		t.Fatalf("failed to create shipment: %v", err)
+	}
+
+	if !response.Success {
+		t.Fatal("expected successful response")
+	}
+
+	records, _ := mockRepo.FindByProvider(context.Background(), "B", 10)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	redacted := strings.NewReplacer(`"testuser"`, `"[REDACTED]"`, `"testpass"`, `"[REDACTED]"`).Replace(string(receivedBody))
+	if string(records[0].TransformedPayload) != redacted {
+		t.Errorf("expected transformed payload to equal the bytes sent to the carrier with credentials masked\nsent: %s\nstored: %s", receivedBody, records[0].TransformedPayload)
+	}
+
+	if string(records[0].RawResponse) != `{"trackingId": "B-1", "awb": "AWB-1"}` {
+		t.Errorf("expected raw carrier response to be stored verbatim, got %s", records[0].RawResponse)
+	}
+}
+
+func TestE2E_ShipmentViewHidesCredentials(t *testing.T) {
+	_, providerBServer := setupMockProviderServers()
+	defer providerBServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerB.NewAdapter(providerBServer.URL))
+
+	response, err := shippingService.ProcessShipment(context.Background(), testutil.CreateSampleShippingRequest(), "B")
+	if err != nil {
+		t.Fatalf("failed to create shipment: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/shipments/{id}", handlers.NewShipmentHandler(mockRepo, nil).GetShipment)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/shipments/"+response.ShipmentID, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, secret := range []string{"testuser", "testpass"} {
+		if strings.Contains(w.Body.String(), secret) {
+			t.Errorf("expected %q to be redacted from the shipment view", secret)
+		}
+	}
+	if !strings.Contains(w.Body.String(), domain.RedactedValue) {
+		t.Error("expected the credentials to show as redacted")
+	}
+}
+
+func TestE2E_ShipmentViewHidesTenantCredentials(t *testing.T) {
+	var receivedBody []byte
+	providerBServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{"trackingId": "B-1", "awb": "AWB-1"}`))
+	}))
+	defer providerBServer.Close()
+
+	mockRepo := testutil.NewMockRepository()
+	shippingService := service.NewShippingService(mockRepo)
+	shippingService.RegisterProvider(providerB.NewAdapter(providerBServer.URL))
+
+	tenant := &domain.Tenant{
+		ID:          "acme",
+		Credentials: map[string]domain.AccountInfo{"B": {Number: "900", Username: "acme-user", Password: "acme-secret"}},
+	}
+	ctx := domain.WithTenant(context.Background(), tenant)
+	request := testutil.CreateSampleShippingRequest()
+	request.Account = domain.AccountInfo{}
+
+	response, err := shippingService.ProcessShipment(ctx, request, "B")
+	if err != nil {
+		t.Fatalf("failed to create shipment: %v", err)
+	}
+	if !strings.Contains(string(receivedBody), "acme-secret") {
+		t.Fatalf("expected the tenant's credentials to be sent to the carrier, got %s", receivedBody)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/shipments/{id}", handlers.NewShipmentHandler(mockRepo, nil).GetShipment)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/shipments/"+response.ShipmentID, nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, secret := range []string{"acme-user", "acme-secret"} {
+		if strings.Contains(w.Body.String(), secret) {
+			t.Errorf("expected the tenant's %q to be hidden from the shipment view", secret)
+		}
+	}
+}